@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/pkg/blob"
+	"github.com/spf13/cobra"
+)
+
+// highwayBlobCmd represents the blob command
+var highwayBlobCmd = &cobra.Command{
+	Use:   "blob",
+	Short: "Upload/Download/Delete Blobs stored on IPFS",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+// blobPutCmd uploads a file to the configured blob store
+var blobPutCmd = &cobra.Command{
+	Use:   "put <file>",
+	Short: "Upload a file and print its CID",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openBlobStore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+
+		label, _ := cmd.Flags().GetString("label")
+		owner, _ := cmd.Flags().GetString("did")
+		b, err := store.Put(context.Background(), f, blob.PutOptions{
+			Owner: owner,
+			Label: label,
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(b.CID)
+	},
+}
+
+// blobGetCmd downloads a blob to a file or stdout
+var blobGetCmd = &cobra.Command{
+	Use:   "get <cid>",
+	Short: "Download a blob",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openBlobStore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		r, err := store.Get(context.Background(), args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer r.Close()
+
+		var w io.Writer = os.Stdout
+		if out, _ := cmd.Flags().GetString("out"); out != "" {
+			f, err := os.Create(out)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			defer f.Close()
+			w = f
+		}
+		if _, err := io.Copy(w, r); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// blobStatCmd prints the metadata of a blob
+var blobStatCmd = &cobra.Command{
+	Use:   "stat <cid>",
+	Short: "Show the metadata of a blob",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openBlobStore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		b, err := store.Stat(context.Background(), args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("CID:     %s\n", b.CID)
+		fmt.Printf("Label:   %s\n", b.Label)
+		fmt.Printf("Size:    %d\n", b.Size)
+		fmt.Printf("Pinned:  %t\n", b.Pinned)
+		fmt.Printf("Owners:  %d\n", len(b.Owners))
+		fmt.Printf("Created: %s\n", b.CreatedAt)
+	},
+}
+
+// blobRmCmd removes the caller's ownership of a blob
+var blobRmCmd = &cobra.Command{
+	Use:   "rm <cid>",
+	Short: "Delete a blob uploaded by the given owner",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openBlobStore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		owner, _ := cmd.Flags().GetString("did")
+		if err := store.Delete(context.Background(), args[0], owner); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("deleted", args[0])
+	},
+}
+
+// openBlobStore opens the blob store configured for this node.
+func openBlobStore() (blob.BlobStore, error) {
+	cnfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return blob.New(cnfg)
+}
+
+func init() {
+	blobPutCmd.Flags().String("label", "", "Human readable name for the blob")
+	blobPutCmd.Flags().String("did", "", "DID of the owner")
+	blobPutCmd.MarkFlagRequired("did")
+	blobGetCmd.Flags().StringP("out", "o", "", "File to write the blob to (default stdout)")
+	blobRmCmd.Flags().String("did", "", "DID of the owner that uploaded the blob")
+	blobRmCmd.MarkFlagRequired("did")
+
+	highwayBlobCmd.AddCommand(blobPutCmd, blobGetCmd, blobStatCmd, blobRmCmd)
+}
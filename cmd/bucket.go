@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	"github.com/spf13/cobra"
+)
+
+// highwayBucketCmd represents the bucket command
+var highwayBucketCmd = &cobra.Command{
+	Use:   "bucket",
+	Short: "Manage Project Buckets stored on the Highway.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+// bucketCreateCmd creates a bucket
+var bucketCreateCmd = &cobra.Command{
+	Use:   "create <label>",
+	Short: "Create a bucket with object schemas attached",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		description, _ := cmd.Flags().GetString("description")
+		kind, _ := cmd.Flags().GetString("kind")
+		objects, _ := cmd.Flags().GetStringSlice("object")
+		b, err := store.CreateBucket(ownerFlag(cmd), docstore.Bucket{
+			Label:       args[0],
+			Description: description,
+			Kind:        kind,
+			ObjectDids:  objects,
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(b)
+	},
+}
+
+// bucketGetCmd prints a bucket
+var bucketGetCmd = &cobra.Command{
+	Use:   "get <did>",
+	Short: "Show a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		b, err := store.Bucket(ownerFlag(cmd), args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(b)
+	},
+}
+
+// bucketListCmd lists the owner's buckets
+var bucketListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List buckets",
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		buckets, err := store.Buckets(ownerFlag(cmd))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(buckets)
+	},
+}
+
+// bucketRmCmd deletes a bucket
+var bucketRmCmd = &cobra.Command{
+	Use:   "rm <did>",
+	Short: "Delete a bucket and its documents",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := store.DeleteBucket(ownerFlag(cmd), args[0]); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("deleted", args[0])
+	},
+}
+
+// bucketPutCmd writes a JSON document to a bucket
+var bucketPutCmd = &cobra.Command{
+	Use:   "put <bucket-did> <file>",
+	Short: "Write a JSON document to a bucket, validated against its object schema (use - for stdin)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		var buf []byte
+		if args[1] == "-" {
+			buf, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			buf, err = ioutil.ReadFile(args[1])
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(buf, &values); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		object, _ := cmd.Flags().GetString("object")
+		id, _ := cmd.Flags().GetString("id")
+		d, err := store.PutDocument(ownerFlag(cmd), docstore.Document{
+			ID:        id,
+			BucketDid: args[0],
+			ObjectDid: object,
+			Values:    values,
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(d)
+	},
+}
+
+// bucketDocsCmd lists the documents in a bucket
+var bucketDocsCmd = &cobra.Command{
+	Use:   "docs <bucket-did>",
+	Short: "List the documents in a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		docs, err := store.Documents(ownerFlag(cmd), args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(docs)
+	},
+}
+
+func init() {
+	highwayBucketCmd.PersistentFlags().String("did", "", "DID of the owner")
+	highwayBucketCmd.MarkPersistentFlagRequired("did")
+	bucketCreateCmd.Flags().String("description", "", "Description of the bucket")
+	bucketCreateCmd.Flags().String("kind", "", "Bucket kind: app or user")
+	bucketCreateCmd.Flags().StringSlice("object", nil, "DID of an object schema to attach")
+	bucketPutCmd.Flags().String("object", "", "DID of the object schema the document follows")
+	bucketPutCmd.MarkFlagRequired("object")
+	bucketPutCmd.Flags().String("id", "", "Document ID to replace (default: new document)")
+
+	highwayBucketCmd.AddCommand(bucketCreateCmd, bucketGetCmd, bucketListCmd, bucketRmCmd, bucketPutCmd, bucketDocsCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sonr-io/webauthn.io/config"
+	db "github.com/sonr-io/webauthn.io/database"
+	"github.com/spf13/cobra"
+)
+
+// highwayDbCmd represents the db command
+var highwayDbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the node's user database schema",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+// dbMigrateCmd applies pending schema migrations
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openStore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer store.Disconnect(context.Background())
+		if err := store.Migrate(context.Background()); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("database is up to date")
+	},
+}
+
+// dbStatusCmd lists the Mongo migrations and when they were applied
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List schema migrations and whether they have been applied",
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openStore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer store.Disconnect(context.Background())
+		mongo, ok := store.(*db.MongoClient)
+		if !ok {
+			fmt.Println("this backend updates its schema when opened")
+			return
+		}
+		migrations, err := mongo.MigrationStatus(context.Background())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, m := range migrations {
+			applied := "pending"
+			if !m.AppliedAt.IsZero() {
+				applied = m.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%3d  %-35s %s\n", m.Version, m.Name, applied)
+		}
+	},
+}
+
+// openStore opens the user database configured for this node.
+func openStore() (db.Store, error) {
+	cnfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return db.Open(cnfg)
+}
+
+func init() {
+	highwayDbCmd.AddCommand(dbMigrateCmd, dbStatusCmd)
+}
@@ -6,15 +6,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// createHwyCmd represents the serve command
-var highwayObjectCmd = &cobra.Command{
-	Use:   "object",
-	Short: "Manage Objects on the Sonr Blockchain.",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("serve called")
-	},
-}
-
 // serveCmd represents the serve command
 var highwayChannelCmd = &cobra.Command{
 	Use:   "channel",
@@ -24,24 +15,6 @@ var highwayChannelCmd = &cobra.Command{
 	},
 }
 
-// serveCmd represents the serve command
-var highwayBucketCmd = &cobra.Command{
-	Use:   "bucket",
-	Short: "Manage Project Buckets for the Sonr Blockchain.",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("serve called")
-	},
-}
-
-// serveCmd represents the serve command
-var highwayBlobCmd = &cobra.Command{
-	Use:   "blob",
-	Short: "Upload/Download/Delete Blobs stored on IPFS",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("serve called")
-	},
-}
-
 // HighwayCmd represents the deploy command
 var HighwayCmd = &cobra.Command{
 	Use:   "highway",
@@ -66,7 +39,7 @@ to quickly create a Cobra application.`,
 }
 
 func init() {
-	HighwayCmd.AddCommand(highwayObjectCmd, highwayChannelCmd, highwayBucketCmd, highwayBlobCmd)
+	HighwayCmd.AddCommand(highwayObjectCmd, highwayChannelCmd, highwayBucketCmd, highwayBlobCmd, highwayDbCmd, highwaySessionKeysCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	"github.com/spf13/cobra"
+)
+
+// highwayObjectCmd represents the object command
+var highwayObjectCmd = &cobra.Command{
+	Use:   "object",
+	Short: "Manage Object schemas stored on the Highway.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+// objectCreateCmd creates an object schema
+var objectCreateCmd = &cobra.Command{
+	Use:   "create <label>",
+	Short: "Create an object schema from name:kind fields",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		specs, _ := cmd.Flags().GetStringSlice("field")
+		fields := make([]docstore.Field, 0, len(specs))
+		for _, spec := range specs {
+			name, kind := spec, ""
+			if i := strings.LastIndexByte(spec, ':'); i >= 0 {
+				name, kind = spec[:i], spec[i+1:]
+			}
+			fields = append(fields, docstore.Field{Name: name, Kind: docstore.Kind(kind)})
+		}
+		description, _ := cmd.Flags().GetString("description")
+		o, err := store.CreateObject(ownerFlag(cmd), docstore.Object{
+			Label:       args[0],
+			Description: description,
+			Fields:      fields,
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(o)
+	},
+}
+
+// objectGetCmd prints an object schema
+var objectGetCmd = &cobra.Command{
+	Use:   "get <did>",
+	Short: "Show an object schema",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		o, err := store.Object(ownerFlag(cmd), args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(o)
+	},
+}
+
+// objectListCmd lists the owner's object schemas
+var objectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List object schemas",
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		objects, err := store.Objects(ownerFlag(cmd))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(objects)
+	},
+}
+
+// objectRmCmd deletes an object schema
+var objectRmCmd = &cobra.Command{
+	Use:   "rm <did>",
+	Short: "Delete an object schema that no bucket uses",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openDocstore()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := store.DeleteObject(ownerFlag(cmd), args[0]); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("deleted", args[0])
+	},
+}
+
+// openDocstore opens the document store used by this node.
+func openDocstore() (*docstore.Store, error) {
+	cnfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return docstore.Open(cnfg.CacheFolder().JoinPath("documents"))
+}
+
+// ownerFlag returns the DID given with --did.
+func ownerFlag(cmd *cobra.Command) string {
+	owner, _ := cmd.Flags().GetString("did")
+	return owner
+}
+
+func printJSON(v interface{}) {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(buf))
+}
+
+func init() {
+	highwayObjectCmd.PersistentFlags().String("did", "", "DID of the owner")
+	highwayObjectCmd.MarkPersistentFlagRequired("did")
+	objectCreateCmd.Flags().StringSlice("field", nil, "Field as name:kind, where kind is one of string, number, bool, array, timestamp, geopoint or blob")
+	objectCreateCmd.Flags().String("description", "", "Description of the object")
+
+	highwayObjectCmd.AddCommand(objectCreateCmd, objectGetCmd, objectListCmd, objectRmCmd)
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/session"
+	"github.com/spf13/cobra"
+)
+
+// highwaySessionKeysCmd represents the session-keys command
+var highwaySessionKeysCmd = &cobra.Command{
+	Use:   "session-keys",
+	Short: "Manage the keys that sign and encrypt session cookies",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+// sessionKeysGenerateCmd creates a key file, or prints a key pair for
+// SESSION_KEYS when no file is configured
+var sessionKeysGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate session keys into a new key file",
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := session.GenerateKeyPair()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		path, err := sessionKeyFile(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if path == "" {
+			fmt.Printf("SESSION_KEYS=%s\n", key)
+			return
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if _, err := os.Stat(path); err == nil && !force {
+			fmt.Printf("%s already exists; rotate it, or pass --force to replace it and end every session\n", path)
+			return
+		}
+		if err := session.WriteKeyFile(path, session.KeyPairs{key}); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("wrote session keys to", path)
+	},
+}
+
+// sessionKeysRotateCmd adds a new key pair to the key file and retires the
+// oldest
+var sessionKeysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Add a new session key pair and retire the oldest",
+	Long: `Adds a new key pair to the front of the key file. New cookies are written
+with it while cookies written with the older pairs kept by --keep are still
+read, so sessions survive the rotation. Restart every server sharing the file
+to pick it up.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := sessionKeyFile(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if path == "" {
+			fmt.Println("no key file; set SESSION_KEY_FILE or pass --file")
+			return
+		}
+		keys, err := session.ReadKeyFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("%s does not exist; create it with generate\n", path)
+			return
+		} else if err != nil {
+			fmt.Println(err)
+			return
+		}
+		keep, _ := cmd.Flags().GetInt("keep")
+		if keep < 1 {
+			fmt.Println("--keep must be at least 1")
+			return
+		}
+		rotated, err := keys.Rotate(keep)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := session.WriteKeyFile(path, rotated); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("rotated session keys in %s, %d active, %d retired\n", path, len(rotated), len(keys)+1-len(rotated))
+	},
+}
+
+// sessionKeyFile returns the key file given by --file, or else the one
+// configured for this node.
+func sessionKeyFile(cmd *cobra.Command) (string, error) {
+	if path, _ := cmd.Flags().GetString("file"); path != "" {
+		return path, nil
+	}
+	cnfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return cnfg.SessionKeyFile, nil
+}
+
+func init() {
+	highwaySessionKeysCmd.PersistentFlags().String("file", "", "Key file to manage (default SESSION_KEY_FILE)")
+	sessionKeysGenerateCmd.Flags().Bool("force", false, "Replace an existing key file, ending every session")
+	sessionKeysRotateCmd.Flags().Int("keep", 2, "Number of key pairs to keep, including the new one")
+
+	highwaySessionKeysCmd.AddCommand(sessionKeysGenerateCmd, sessionKeysRotateCmd)
+}
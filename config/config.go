@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -18,6 +19,7 @@ type Config struct {
 	RelyingParty string `json:"relying_party"` // RelyingParty is the name of the WebAuthn relying party.
 	RPID         string
 	RPOrigin     string
+	SessionKeys  [][]byte // SessionKeys authenticate and encrypt session cookies; see session.NewStore.
 }
 
 // LoadConfig loads a configuration at the provided filepath, returning the
@@ -48,6 +50,34 @@ type SonrConfig struct {
 	// HighwayPort is the port of the Sonr Highway node for http
 	HttpPort string `json:"http_port"`
 
+	// MuxPort serves gRPC and HTTP together on a single port when set,
+	// replacing the separate GrpcPort and HttpPort listeners
+	MuxPort string `json:"mux_port"`
+
+	// GrpcWebOrigins are the browser origins allowed to call the node over
+	// gRPC-Web. "*" allows any origin.
+	GrpcWebOrigins []string `json:"grpc_web_origins"`
+
+	// GrpcReflection registers the gRPC server reflection service
+	GrpcReflection bool `json:"grpc_reflection"`
+
+	// TLSCertFile is the PEM certificate chain served by the node
+	TLSCertFile string `json:"tls_cert_file"`
+
+	// TLSKeyFile is the PEM private key of TLSCertFile
+	TLSKeyFile string `json:"tls_key_file"`
+
+	// TLSCAFile is the PEM bundle client certificates are verified against
+	TLSCAFile string `json:"tls_ca_file"`
+
+	// TLSClientAuth requires clients to present a certificate identifying
+	// their DID
+	TLSClientAuth bool `json:"tls_client_auth"`
+
+	// TLSStrict refuses to start without TLS instead of falling back to
+	// plaintext. Setting TLSClientAuth or any of the files above does too.
+	TLSStrict bool `json:"tls_strict"`
+
 	// HighwayNetwork is the network of the Sonr Highway node.
 	HighwayNetwork string `json:"highway_network"`
 
@@ -57,9 +87,15 @@ type SonrConfig struct {
 	// IPFSPort is the port of the IPFS node.
 	IPFSPort int `json:"ipfs_port"`
 
-	// IPFSPath is the path of the IPFS node.
+	// IPFSPath is the path of the IPFS node. Blobs are stored beneath it.
 	IPFSPath string `json:"ipfs_path"`
 
+	// IPFSAddress is the host of the IPFS node's HTTP API.
+	IPFSAddress string `json:"ipfs_address"`
+
+	// BlobStore selects the blob store backend: "local" or "ipfs".
+	BlobStore string `json:"blob_store"`
+
 	// LibP2PLowWater is the low water mark for the libp2p connection pool.
 	LibP2PLowWater int `json:"libp2p_low_water"`
 
@@ -96,6 +132,49 @@ type SonrConfig struct {
 	// AccountName is the account name of the Sonr node.
 	AccountName string `json:"account_name"`
 
+	// DbBackend selects the user store: "mongo" (the default), "sqlite" or
+	// "memory". The SQLite database lives at SqlPath.
+	DbBackend string `json:"db_backend"`
+
+	// DbMigrate applies pending schema migrations when the node starts.
+	// Disable it to run them separately with `highway db migrate`.
+	DbMigrate bool `json:"db_migrate"`
+
+	// SignCountPolicy decides what happens when an authenticator's sign
+	// count fails to advance, a sign it may have been cloned: "block" (the
+	// default) refuses the login, "warn" allows it, and "reregister" also
+	// disables the credential so the user has to register a new one. Every
+	// case is recorded in the user's audit log.
+	SignCountPolicy string `json:"sign_count_policy"`
+
+	// AttestationMetadataFile is a FIDO Metadata Service (MDS3) blob and
+	// AttestationRootFile the PEM root certificate it is signed under. The
+	// blob is read at startup; download a fresh one to update it.
+	AttestationMetadataFile string `json:"attestation_metadata_file"`
+	AttestationRootFile     string `json:"attestation_root_file"`
+
+	// AttestationMinLevel is the lowest FIDO certification level, "L1" to
+	// "L3+", an authenticator needs to register. AttestationAllow lists the
+	// only AAGUIDs that may register and AttestationDeny AAGUIDs that may
+	// not. A minimum level or allow list requires the metadata blob.
+	AttestationMinLevel string   `json:"attestation_min_level"`
+	AttestationAllow    []string `json:"attestation_allow"`
+	AttestationDeny     []string `json:"attestation_deny"`
+
+	// SessionIdleTimeout ends a login session that has not been used for
+	// that long, and SessionLifetime ends it that long after it started
+	// however much it is used. Sessions are stored in the configured
+	// database, so they can be listed and signed out.
+	SessionIdleTimeout time.Duration `json:"session_idle_timeout"`
+	SessionLifetime    time.Duration `json:"session_lifetime"`
+
+	// SessionKeys are the keys that authenticate and encrypt session
+	// cookies, newest first, each as <hash>:<encryption> in base64. Without
+	// them they are read from SessionKeyFile, which `highway session-keys`
+	// generates and rotates. Replicas sharing the keys share sessions.
+	SessionKeys    []string `json:"session_keys"`
+	SessionKeyFile string   `json:"session_key_file"`
+
 	// MongoUri is URI to connect to the mongodb
 	MongoUri string `json:"mongo_uri"`
 
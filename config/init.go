@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/denisbrodbeck/machineid"
 	"github.com/kataras/golog"
@@ -87,6 +88,7 @@ func Load() (*SonrConfig, error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
+	viper.SetDefault("DB_MIGRATE", true)
 
 	err = viper.ReadInConfig()
 	if err != nil {
@@ -102,24 +104,48 @@ func Load() (*SonrConfig, error) {
 		WalletDir: wf.String(),
 		DeviceId:  id,
 		//HighwayAddress:  viper.GetString("highway.address"),
-		HighwayAddress:      viper.GetString("HOST"),
-		GrpcPort:            viper.GetString("GRPC_PORT"),
-		HttpPort:            viper.GetString("HTTP_PORT"),
-		HighwayNetwork:      viper.GetString("highway.network"),
-		MongoUri:            viper.GetString("MONGO_URI"),
-		MongoCollectionName: viper.GetString("MONGO_COLLECTION_NAME"),
-		MongoDbName:         viper.GetString("MONGO_DB_NAME"),
-		SecretKey:           viper.GetString("SECRET_KEY"),
-		DevAccount:          viper.GetString("DEV_ACCOUNT"),
-		SqlName:             viper.GetString("SQL_NAME"),
-		SqlPath:             viper.GetString("SQL_PATH"),
-		RelyingParty:        viper.GetString("RELYING_PARTY"),
-		RPOrigin:            viper.GetString("RP_ORIGIN"),
-		RPPort:              viper.GetString("RP_PORT"),
-		StripeKey:           viper.GetString("STRIPE_KEY"),
-		LibP2PLowWater:      viper.GetInt("libp2p.lowWater"),
-		LibP2PHighWater:     viper.GetInt("libp2p.highWater"),
-		LibP2PRendevouz:     viper.GetString("libp2p.rendevouz"),
+		HighwayAddress:          viper.GetString("HOST"),
+		GrpcPort:                viper.GetString("GRPC_PORT"),
+		HttpPort:                viper.GetString("HTTP_PORT"),
+		MuxPort:                 viper.GetString("MUX_PORT"),
+		GrpcWebOrigins:          splitList(viper.GetString("GRPC_WEB_ORIGINS")),
+		GrpcReflection:          viper.GetBool("GRPC_REFLECTION"),
+		TLSCertFile:             viper.GetString("TLS_CERT_FILE"),
+		TLSKeyFile:              viper.GetString("TLS_KEY_FILE"),
+		TLSCAFile:               viper.GetString("TLS_CA_FILE"),
+		TLSClientAuth:           viper.GetBool("TLS_CLIENT_AUTH"),
+		TLSStrict:               viper.GetBool("TLS_STRICT"),
+		HighwayNetwork:          viper.GetString("highway.network"),
+		DbBackend:               viper.GetString("DB_BACKEND"),
+		DbMigrate:               viper.GetBool("DB_MIGRATE"),
+		SignCountPolicy:         viper.GetString("SIGN_COUNT_POLICY"),
+		AttestationMetadataFile: viper.GetString("ATTESTATION_METADATA_FILE"),
+		AttestationRootFile:     viper.GetString("ATTESTATION_ROOT_FILE"),
+		AttestationMinLevel:     viper.GetString("ATTESTATION_MIN_LEVEL"),
+		AttestationAllow:        splitList(viper.GetString("ATTESTATION_ALLOW")),
+		AttestationDeny:         splitList(viper.GetString("ATTESTATION_DENY")),
+		SessionIdleTimeout:      viper.GetDuration("SESSION_IDLE_TIMEOUT"),
+		SessionLifetime:         viper.GetDuration("SESSION_LIFETIME"),
+		SessionKeys:             splitList(viper.GetString("SESSION_KEYS")),
+		SessionKeyFile:          viper.GetString("SESSION_KEY_FILE"),
+		MongoUri:                viper.GetString("MONGO_URI"),
+		MongoCollectionName:     viper.GetString("MONGO_COLLECTION_NAME"),
+		MongoDbName:             viper.GetString("MONGO_DB_NAME"),
+		SecretKey:               viper.GetString("SECRET_KEY"),
+		DevAccount:              viper.GetString("DEV_ACCOUNT"),
+		SqlName:                 viper.GetString("SQL_NAME"),
+		SqlPath:                 viper.GetString("SQL_PATH"),
+		RelyingParty:            viper.GetString("RELYING_PARTY"),
+		RPOrigin:                viper.GetString("RP_ORIGIN"),
+		RPPort:                  viper.GetString("RP_PORT"),
+		StripeKey:               viper.GetString("STRIPE_KEY"),
+		IPFSPort:                viper.GetInt("IPFS_PORT"),
+		IPFSPath:                viper.GetString("IPFS_PATH"),
+		IPFSAddress:             viper.GetString("IPFS_ADDRESS"),
+		BlobStore:               viper.GetString("BLOB_STORE"),
+		LibP2PLowWater:          viper.GetInt("libp2p.lowWater"),
+		LibP2PHighWater:         viper.GetInt("libp2p.highWater"),
+		LibP2PRendevouz:         viper.GetString("libp2p.rendevouz"),
 	}
 
 	config.Save()
@@ -191,3 +217,14 @@ func Platform() string {
 		return "Unknown"
 	}
 }
+
+// splitList splits a comma separated setting, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
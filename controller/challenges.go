@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	db "github.com/sonr-io/webauthn.io/database"
+	log "github.com/sonr-io/webauthn.io/logger"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// OpenChallenge stores the session data of a ceremony that outlives the
+// session cookie for ttl, and returns the ID to finish it with. Challenges
+// are handed out before anyone logs in, so at most limit are held at once
+// and more are refused with models.ErrTooManyChallenges. The count and the
+// insert are separate calls, so replicas racing at the limit may overshoot
+// it by a few.
+func (ctrl *Controller) OpenChallenge(ctx context.Context, data *webauthn.SessionData, ttl time.Duration, limit int) (string, error) {
+	now := time.Now()
+	// Challenges of abandoned ceremonies are cleaned up as others open.
+	if err := ctrl.client.DeleteExpiredChallenges(ctx, now); err != nil {
+		log.Warnf("error deleting expired challenges: %v", err)
+	}
+	n, err := ctrl.client.CountChallenges(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	if n >= limit {
+		return "", models.ErrTooManyChallenges
+	}
+	id, c, err := models.NewChallenge(data, now.Add(ttl))
+	if err != nil {
+		return "", err
+	}
+	if err := ctrl.client.CreateChallenge(ctx, c); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// TakeChallenge removes and returns the session data stored under id. Each
+// challenge can be taken once, so a signed assertion cannot be replayed; an
+// unknown, used or expired ID is models.ErrChallengeNotFound.
+func (ctrl *Controller) TakeChallenge(ctx context.Context, id string) (webauthn.SessionData, error) {
+	c, err := ctrl.client.TakeChallenge(ctx, models.HashSessionToken(id))
+	if errors.Is(err, db.ErrNotFound) {
+		return webauthn.SessionData{}, models.ErrChallengeNotFound
+	} else if err != nil {
+		return webauthn.SessionData{}, err
+	}
+	if !c.Active(time.Now()) {
+		return webauthn.SessionData{}, models.ErrChallengeNotFound
+	}
+	return c.SessionData()
+}
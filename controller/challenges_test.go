@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+func TestChallenges(t *testing.T) {
+	ctrl, _ := newTestController(t)
+
+	first, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{Challenge: "first"}, time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{Challenge: "second"}, time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatalf("challenge IDs repeat: %s", first)
+	}
+
+	data, err := ctrl.TakeChallenge(ctx, second)
+	if err != nil || data.Challenge != "second" {
+		t.Fatalf("TakeChallenge = %+v, %v", data, err)
+	}
+	if _, err := ctrl.TakeChallenge(ctx, second); !errors.Is(err, models.ErrChallengeNotFound) {
+		t.Fatalf("taken twice: %v", err)
+	}
+	if _, err := ctrl.TakeChallenge(ctx, "unknown"); !errors.Is(err, models.ErrChallengeNotFound) {
+		t.Fatalf("unknown ID: %v", err)
+	}
+
+	expired, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{}, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.TakeChallenge(ctx, expired); !errors.Is(err, models.ErrChallengeNotFound) {
+		t.Fatalf("expired challenge: %v", err)
+	}
+}
+
+func TestChallengeLimit(t *testing.T) {
+	ctrl, store := newTestController(t)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{}, time.Minute, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{}, time.Minute, 2); !errors.Is(err, models.ErrTooManyChallenges) {
+		t.Fatalf("OpenChallenge past the limit: %v", err)
+	}
+
+	// Taking a challenge frees its slot.
+	if _, err := ctrl.TakeChallenge(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{}, time.Minute, 2); err != nil {
+		t.Fatalf("OpenChallenge after a challenge was taken: %v", err)
+	}
+
+	// So does expiring: abandoned challenges are dropped as others open.
+	if err := store.DeleteExpiredChallenges(ctx, time.Now().Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.OpenChallenge(ctx, &webauthn.SessionData{}, time.Minute, 2); err != nil {
+		t.Fatalf("OpenChallenge after challenges expired: %v", err)
+	}
+	if n, err := store.CountChallenges(ctx, time.Now()); err != nil || n != 1 {
+		t.Fatalf("%d challenges held, %v; want 1", n, err)
+	}
+}
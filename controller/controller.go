@@ -2,113 +2,500 @@ package controller
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/duo-labs/webauthn/protocol"
 	"github.com/duo-labs/webauthn/webauthn"
 	"github.com/kataras/jwt"
 	"github.com/sonr-io/sonr/x/registry/types"
 	"github.com/sonr-io/webauthn.io/config"
 	db "github.com/sonr-io/webauthn.io/database"
+	log "github.com/sonr-io/webauthn.io/logger"
 	"github.com/sonr-io/webauthn.io/models"
+	"github.com/sonr-io/webauthn.io/pkg/attestation"
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	"github.com/sonr-io/webauthn.io/pkg/did"
 	"github.com/stripe/stripe-go/v72"
 	"github.com/stripe/stripe-go/v72/paymentintent"
 	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
 )
 
+// Policies applied when an authenticator's sign count fails to advance.
+const (
+	SignCountBlock      = "block"
+	SignCountWarn       = "warn"
+	SignCountReregister = "reregister"
+)
+
 // any other services required by http server will flow through here
 type Controller struct {
-	client      *db.MongoClient
-	privateKey  string
-	devAccount  string
-	stripeKey   string
-	highwayStub *models.HighwayStub
+	client          db.Store
+	privateKey      string
+	devAccount      string
+	stripeKey       string
+	signCountPolicy string
+	attestation     *attestation.Policy
+	sessionIdle     time.Duration
+	sessionLifetime time.Duration
+	highwayStub     *models.HighwayStub
 }
 
-func New(mongoClient *db.MongoClient, cnfg *config.SonrConfig, stub *models.HighwayStub) (*Controller, error) {
+func New(store db.Store, cnfg *config.SonrConfig, stub *models.HighwayStub) (*Controller, error) {
+	policy := cnfg.SignCountPolicy
+	switch policy {
+	case "":
+		policy = SignCountBlock
+	case SignCountBlock, SignCountWarn, SignCountReregister:
+	default:
+		return nil, fmt.Errorf("unknown sign count policy %q", policy)
+	}
+	minLevel, err := attestation.ParseLevel(cnfg.AttestationMinLevel)
+	if err != nil {
+		return nil, err
+	}
+	attestationPolicy, err := attestation.New(attestation.Config{
+		MetadataFile: cnfg.AttestationMetadataFile,
+		RootFile:     cnfg.AttestationRootFile,
+		MinLevel:     minLevel,
+		Allow:        cnfg.AttestationAllow,
+		Deny:         cnfg.AttestationDeny,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("attestation policy: %w", err)
+	}
 	return &Controller{
-		client:      mongoClient,
-		privateKey:  cnfg.SecretKey,
-		devAccount:  cnfg.DevAccount,
-		highwayStub: stub,
-		stripeKey:   cnfg.StripeKey,
+		client:          store,
+		privateKey:      cnfg.SecretKey,
+		devAccount:      cnfg.DevAccount,
+		highwayStub:     stub,
+		stripeKey:       cnfg.StripeKey,
+		signCountPolicy: policy,
+		attestation:     attestationPolicy,
+		sessionIdle:     orDefault(cnfg.SessionIdleTimeout, DefaultSessionIdleTimeout),
+		sessionLifetime: orDefault(cnfg.SessionLifetime, DefaultSessionLifetime),
 	}, nil
 }
 
 func (ctrl *Controller) CheckName(ctx context.Context, name string) (bool, error) {
-	result, err := ctrl.client.CheckName(name)
+	return ctrl.client.CheckName(ctx, name)
+}
+
+func (ctrl *Controller) InsertRecord(ctx context.Context, name string, did string) error {
+	return ctrl.client.StoreRecord(ctx, name, did)
+}
+
+func (ctrl *Controller) NewUser(ctx context.Context, user *models.User) error {
+	return ctrl.client.NewUser(ctx, user)
+}
+
+func (ctrl *Controller) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	return ctrl.client.GetUser(ctx, id)
+}
+
+// FindUserByName returns the user owning name, or models.ErrNameNotFound.
+func (ctrl *Controller) FindUserByName(ctx context.Context, name string) (*models.User, error) {
+	user, err := ctrl.client.FindUserByName(ctx, name)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, models.ErrNameNotFound
+	}
+	return user, err
+}
+
+func (ctrl *Controller) GetUserByUsername(ctx context.Context, name string) (*models.User, error) {
+	return ctrl.client.GetUserByUsername(ctx, name)
+}
+
+func (ctrl *Controller) PutUser(ctx context.Context, u *models.User) error {
+	return ctrl.client.PutUser(ctx, u)
+}
+
+func (ctrl *Controller) GetCredentialsForUser(ctx context.Context, user *models.User) ([]models.Credential, error) {
+	return ctrl.client.GetCredentialsForUser(ctx, user)
+}
+
+func (ctrl *Controller) CreateAuthenticator(ctx context.Context, auth webauthn.Authenticator, attestationType string) (*models.Authenticator, error) {
+	return ctrl.client.CreateAuthenticator(ctx, auth, attestationType)
+}
+
+func (ctrl *Controller) CreateCredential(ctx context.Context, c *models.Credential) error {
+	return ctrl.client.CreateCredential(ctx, c)
+}
+
+func (ctrl *Controller) DeleteCredentialByID(ctx context.Context, id string) error {
+	return ctrl.client.DeleteCredentialByID(ctx, id)
+}
+
+func (ctrl *Controller) GetCredentialForUser(ctx context.Context, user *models.User, credentialID string) (models.Credential, error) {
+	return ctrl.client.GetCredentialForUser(ctx, user, credentialID)
+}
+
+// AttestationConveyance is the attestation registrations have to request
+// for the attestation policy, or empty when the client may choose.
+func (ctrl *Controller) AttestationConveyance() protocol.ConveyancePreference {
+	return ctrl.attestation.Conveyance()
+}
+
+// VerifyAttestation applies the attestation policy to a registration and
+// returns its attestation type. Refusals wrap attestation.ErrRejected.
+func (ctrl *Controller) VerifyAttestation(c *protocol.ParsedCredentialCreationData) (string, error) {
+	res, err := ctrl.attestation.Verify(attestation.FromCreation(c))
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	return result, nil
+	log.Infof("Accepted %s attestation of authenticator %s (trusted %v, %s)", res.Type, res.AAGUID, res.Trusted, res.Level)
+	return res.Type, nil
 }
 
-func (ctrl *Controller) InsertRecord(ctx context.Context, name string, did string) error {
-	successful := ctrl.client.StoreRecord(name, did)
+// ListCredentials returns every credential of owner, disabled ones included.
+func (ctrl *Controller) ListCredentials(ctx context.Context, owner *models.User) ([]models.Credential, error) {
+	return ctrl.client.GetCredentialsForUser(ctx, owner)
+}
 
-	if !successful {
-		return errors.New("mongo error in insert record")
+// RenameCredential sets the nickname of one of owner's credentials.
+func (ctrl *Controller) RenameCredential(ctx context.Context, owner *models.User, credentialID string, nickname string) (*models.Credential, error) {
+	nickname = strings.TrimSpace(nickname)
+	if utf8.RuneCountInString(nickname) > models.MaxNicknameLength {
+		return nil, models.ErrInvalidNickname
 	}
+	cred, err := ownedCredential(ctx, ctrl.client, owner, credentialID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctrl.client.RenameCredential(ctx, credentialID, nickname); err != nil {
+		return nil, err
+	}
+	cred.Nickname = nickname
+	return &cred, nil
+}
 
-	return nil
+// RevokeCredential deletes one of owner's credentials and signs out the
+// sessions it logged in. The last credential that can still log in is kept
+// unless owner has a recovery method, so revoking cannot lock them out.
+func (ctrl *Controller) RevokeCredential(ctx context.Context, owner *models.User, credentialID string) error {
+	return ctrl.client.Transaction(ctx, func(ctx context.Context, tx db.Store) error {
+		cred, err := ownedCredential(ctx, tx, owner, credentialID)
+		if err != nil {
+			return err
+		}
+		if !cred.Disabled && !owner.HasRecovery() && len(owner.WebAuthnCredentials()) <= 1 {
+			return models.ErrLastCredential
+		}
+		if err := tx.DeleteCredentialByID(ctx, credentialID); err != nil {
+			return err
+		}
+		return tx.DeleteSessions(ctx, owner.ID, credentialID)
+	})
 }
 
-func (ctrl *Controller) NewUser(ctx context.Context, user models.User) error {
-	return ctrl.client.NewUser(user)
+// ownedCredential returns owner's credential with the given ID, reloading
+// owner. Credentials of other users are reported as models.ErrCredentialNotFound
+// so their IDs cannot be probed.
+func ownedCredential(ctx context.Context, store db.Store, owner *models.User, credentialID string) (models.Credential, error) {
+	cred, err := store.GetCredentialForUser(ctx, owner, credentialID)
+	if errors.Is(err, db.ErrNotFound) {
+		return cred, models.ErrCredentialNotFound
+	}
+	return cred, err
 }
 
-func (ctrl *Controller) GetUser(id uint) (*models.User, error) {
-	return ctrl.client.GetUser(id), nil
+// CheckSignCount stores the sign count a login's authenticator reported.
+// If the count did not advance the event is audited and the sign count
+// policy applied: models.ErrCredentialCloned or models.ErrCredentialDisabled
+// mean the login must be refused.
+func (ctrl *Controller) CheckSignCount(ctx context.Context, user *models.User, cred *webauthn.Credential) error {
+	credentialID := base64.URLEncoding.EncodeToString(cred.ID)
+	err := ctrl.client.UpdateSignCount(ctx, credentialID, cred.Authenticator.SignCount)
+	if !cred.Authenticator.CloneWarning && !errors.Is(err, db.ErrStale) {
+		return err
+	}
+
+	log.Warnf("sign count of credential %s for %s did not advance, applying %q policy", credentialID, user.Username, ctrl.signCountPolicy)
+	err = ctrl.client.AddAuditEvent(ctx, &models.AuditEvent{
+		UserID:       user.ID,
+		Kind:         models.AuditSignCountRegression,
+		CredentialID: credentialID,
+		Detail:       fmt.Sprintf("reported sign count %d; policy %s", cred.Authenticator.SignCount, ctrl.signCountPolicy),
+	})
+	if err != nil {
+		return err
+	}
+	switch ctrl.signCountPolicy {
+	case SignCountWarn:
+		return nil
+	case SignCountReregister:
+		if err := ctrl.client.DisableCredential(ctx, credentialID); err != nil {
+			return err
+		}
+		return models.ErrCredentialDisabled
+	default:
+		return models.ErrCredentialCloned
+	}
 }
 
-func (ctrl *Controller) FindUserByName(ctx context.Context, name string) *models.User {
-	return ctrl.client.FindUserByName(name)
+// Recovery attempts are limited per account: after MaxRecoveryAttempts
+// failures within RecoveryLockout, recovery is refused until the window has
+// passed.
+const (
+	MaxRecoveryAttempts = 5
+	RecoveryLockout     = time.Hour
+)
+
+// RecoveryTimeout is how long a recovery grant can be used to enroll a new
+// credential.
+const RecoveryTimeout = 15 * time.Minute
+
+// IssueRecoveryCodes replaces user's recovery codes with a new set and
+// returns the codes. They cannot be read back, so they must be shown to the
+// user now.
+func (ctrl *Controller) IssueRecoveryCodes(ctx context.Context, user *models.User) ([]string, error) {
+	codes, hashes, err := models.NewRecoveryCodes(models.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	err = ctrl.client.Transaction(ctx, func(ctx context.Context, tx db.Store) error {
+		if err := tx.SetRecoveryCodes(ctx, user.ID, hashes); err != nil {
+			return err
+		}
+		return tx.AddAuditEvent(ctx, &models.AuditEvent{
+			UserID: user.ID,
+			Kind:   models.AuditRecoveryCodesIssued,
+			Detail: fmt.Sprintf("%d codes", len(codes)),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.RecoveryCodes = hashes
+	return codes, nil
 }
 
-func (ctrl *Controller) GetUserByUsername(name string) (*models.User, error) {
-	return ctrl.client.GetUserByUsername(name), nil
+// RecoverAccount consumes a recovery code of the user named username and
+// returns the user, who may then enroll a new credential. Every attempt on an
+// existing account is audited. An unknown account, or a wrong or used code,
+// is models.ErrInvalidRecoveryCode, so accounts cannot be probed.
+func (ctrl *Controller) RecoverAccount(ctx context.Context, username string, code string) (*models.User, error) {
+	user, err := ctrl.client.GetUserByUsername(ctx, username)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, models.ErrInvalidRecoveryCode
+	} else if err != nil {
+		return nil, err
+	}
+	events, err := ctrl.client.GetAuditEvents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if recoveryFailures(events, time.Now().Add(-RecoveryLockout)) >= MaxRecoveryAttempts {
+		log.Warnf("refused recovery of %s after repeated failures", user.Username)
+		return nil, models.ErrRecoveryLocked
+	}
+
+	err = ctrl.client.UseRecoveryCode(ctx, user.ID, models.HashRecoveryCode(code))
+	if errors.Is(err, db.ErrNotFound) {
+		log.Warnf("failed recovery attempt for %s", user.Username)
+		if err := ctrl.client.AddAuditEvent(ctx, &models.AuditEvent{UserID: user.ID, Kind: models.AuditRecoveryFailed}); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvalidRecoveryCode
+	} else if err != nil {
+		return nil, err
+	}
+	user.RecoveryCodes = user.RecoveryCodes.Without(models.HashRecoveryCode(code))
+	log.Infof("recovery code used for %s, %d left", user.Username, len(user.RecoveryCodes))
+	err = ctrl.client.AddAuditEvent(ctx, &models.AuditEvent{
+		UserID: user.ID,
+		Kind:   models.AuditRecoveryCodeUsed,
+		Detail: fmt.Sprintf("%d codes left", len(user.RecoveryCodes)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-func (ctrl *Controller) PutUser(u *models.User) error {
-	ctrl.client.PutUser(u)
-	return nil
+// recoveryFailures counts the failed recovery attempts since the later of
+// since and the last successful one. events are newest first, as the store
+// returns them.
+func recoveryFailures(events []models.AuditEvent, since time.Time) int {
+	failures := 0
+	for _, e := range events {
+		if e.CreatedAt.Before(since) || e.Kind == models.AuditRecoveryCodeUsed {
+			break
+		}
+		if e.Kind == models.AuditRecoveryFailed {
+			failures++
+		}
+	}
+	return failures
 }
 
-func (ctrl *Controller) GetCredentialsForUser(user *models.User) ([]models.Credential, error) {
-	return ctrl.client.GetCredentialsForUser(user)
+// OpenRecovery grants the browser that recovered user's account the right
+// to enroll one credential. It returns the token for the browser's cookie,
+// which is only stored hashed.
+func (ctrl *Controller) OpenRecovery(ctx context.Context, user *models.User) (string, *models.RecoveryGrant, error) {
+	token, hash, err := models.NewSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	if err := ctrl.client.DeleteExpiredRecoveryGrants(ctx, now); err != nil {
+		log.Warnf("error deleting expired recovery grants: %v", err)
+	}
+	grant := &models.RecoveryGrant{TokenHash: hash, UserID: user.ID, Expires: now.Add(RecoveryTimeout)}
+	if err := ctrl.client.CreateRecoveryGrant(ctx, grant); err != nil {
+		return "", nil, err
+	}
+	return token, grant, nil
 }
 
-func (ctrl *Controller) CreateAuthenticator(auth webauthn.Authenticator) (models.Authenticator, error) {
-	return *ctrl.client.CreateAuthenticator(auth), nil
+// ResumeRecovery returns the grant a cookie's token belongs to and its user.
+// A token of no active grant is models.ErrRecoveryExpired.
+func (ctrl *Controller) ResumeRecovery(ctx context.Context, token string) (*models.RecoveryGrant, *models.User, error) {
+	grant, err := ctrl.client.GetRecoveryGrant(ctx, models.HashSessionToken(token))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil, models.ErrRecoveryExpired
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if !grant.Active(time.Now()) {
+		if err := ctrl.client.DeleteRecoveryGrant(ctx, grant.ID); err != nil && !errors.Is(err, db.ErrNotFound) {
+			return nil, nil, err
+		}
+		return nil, nil, models.ErrRecoveryExpired
+	}
+	user, err := ctrl.client.GetUser(ctx, grant.UserID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil, models.ErrRecoveryExpired
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return grant, user, nil
 }
 
-func (ctrl *Controller) CreateCredential(c *models.Credential) error {
-	return ctrl.client.CreateCredential(c)
+// RecoverCredential stores a credential enrolled with grant and audits it.
+// The grant is consumed in the same transaction, so it enrolls one
+// credential only; a grant already used is models.ErrRecoveryExpired. The
+// user lost access to their other credentials, which may now be in someone
+// else's hands, so every session is signed out.
+func (ctrl *Controller) RecoverCredential(ctx context.Context, grant *models.RecoveryGrant, user *models.User, cred *webauthn.Credential, attestationType string) (*models.Credential, error) {
+	if grant.UserID != user.ID {
+		return nil, models.ErrRecoveryExpired
+	}
+	var c *models.Credential
+	err := ctrl.client.Transaction(ctx, func(ctx context.Context, tx db.Store) error {
+		if err := tx.DeleteRecoveryGrant(ctx, grant.ID); errors.Is(err, db.ErrNotFound) {
+			return models.ErrRecoveryExpired
+		} else if err != nil {
+			return err
+		}
+		var err error
+		c, err = registerCredential(ctx, tx, user, cred, attestationType, false)
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteSessions(ctx, user.ID, ""); err != nil {
+			return err
+		}
+		return tx.AddAuditEvent(ctx, &models.AuditEvent{
+			UserID:       user.ID,
+			Kind:         models.AuditRecoveryCredentialAdded,
+			CredentialID: c.CredentialID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
-func (ctrl *Controller) DeleteCredentialByID(id string) error {
-	return ctrl.client.DeleteCredentialByID(id)
+func (ctrl *Controller) GetAuditEvents(ctx context.Context, userID uint) ([]models.AuditEvent, error) {
+	return ctrl.client.GetAuditEvents(ctx, userID)
 }
 
-func (ctrl *Controller) GetCredentialForUser(user models.User, credentialID string) (models.Credential, error) {
-	return ctrl.client.GetCredentialForUser(&user, credentialID)
+// FindDid returns the user holding did, which may be stored in the form it
+// was issued in before ids were escaped.
+func (ctrl *Controller) FindDid(ctx context.Context, did string) (*models.User, error) {
+	return findDid(ctx, ctrl.client, did)
 }
-func (ctrl *Controller) UpdateAuthenticatorSignCount(id uint, count uint32) error {
-	return ctrl.client.UpdateAuthenticatorSignCount(id, count)
+
+func (ctrl *Controller) AttachDid(ctx context.Context, userID uint, placeHolderDid string, newDid string) error {
+	return ctrl.client.AttachDid(ctx, userID, placeHolderDid, newDid)
 }
 
-func (ctrl *Controller) FindDid(ctx context.Context, did string) *models.User {
-	return ctrl.client.FindDid(did)
+func (ctrl *Controller) GiveUserCred(ctx context.Context, username string, cred *models.Credential) error {
+	return ctrl.client.GiveUserCred(ctx, username, cred)
 }
 
-func (ctrl *Controller) AttachDid(ctx context.Context, placeHolderDid string, newDid string) error {
-	return ctrl.client.AttachDid(placeHolderDid, newDid)
+// RegisterCredential stores a credential created by a registration
+// ceremony, with its authenticator, and points the user's DID at it. When
+// create is set the user is new and is stored too. Everything is written in
+// one transaction, so a failed registration leaves nothing behind.
+func (ctrl *Controller) RegisterCredential(ctx context.Context, user *models.User, cred *webauthn.Credential, attestationType string, create bool) (*models.Credential, error) {
+	var c *models.Credential
+	err := ctrl.client.Transaction(ctx, func(ctx context.Context, tx db.Store) error {
+		var err error
+		c, err = registerCredential(ctx, tx, user, cred, attestationType, create)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
-func (ctrl *Controller) GiveUserCred(username string, cred *models.Credential) error {
-	ctrl.client.GiveUserCred(username, cred)
-	return nil
+// registerCredential writes a registration within the transaction tx.
+func registerCredential(ctx context.Context, tx db.Store, user *models.User, cred *webauthn.Credential, attestationType string, create bool) (*models.Credential, error) {
+	// For our use case, we're encoding the raw credential ID as URL-safe
+	// base64 since we anticipate rendering it in templates. If you choose to
+	// do this, make sure to decode the credential ID before passing it back to
+	// the webauthn library.
+	credentialID := base64.URLEncoding.EncodeToString(cred.ID)
+	userDid := did.Sonr(credentialID).String()
+
+	if create {
+		user.Did = userDid
+		if err := tx.NewUser(ctx, user); err != nil {
+			return nil, err
+		}
+	} else {
+		// Users registered before placeholders were dropped may still hold
+		// one; others already have their DID. The placeholder is derived
+		// from the display name, which anyone can choose, so only this
+		// user's own is replaced. Placeholders were written with the name
+		// unescaped.
+		placeholder := did.Sonr("temp" + user.DisplayName)
+		err := tx.AttachDid(ctx, user.ID, placeholder.String(), userDid)
+		if errors.Is(err, db.ErrNotFound) && placeholder.Unescaped() != placeholder.String() {
+			err = tx.AttachDid(ctx, user.ID, placeholder.Unescaped(), userDid)
+		}
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return nil, err
+		}
+	}
+	authenticator, err := tx.CreateAuthenticator(ctx, cred.Authenticator, attestationType)
+	if err != nil {
+		return nil, err
+	}
+	c := &models.Credential{
+		Authenticator:   *authenticator,
+		AuthenticatorID: authenticator.ID,
+		UserID:          user.ID,
+		PublicKey:       cred.PublicKey,
+		CredentialID:    credentialID,
+	}
+	if err := tx.CreateCredential(ctx, c); err != nil {
+		return nil, err
+	}
+	if err := tx.GiveUserCred(ctx, user.Username, c); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // func (ctrl *Controller) AddCreds(ctx context.Context, user webauthn.User, authenticator webauthn.Authenticator) error {
@@ -133,16 +520,16 @@ func (ctrl *Controller) StripeIntent(item models.SnrItem, name string) (*stripe.
 	return paymentintent.New(params)
 }
 
-func (ctrl *Controller) AttachIntent(piID string, name string) {
-	ctrl.client.AttachIntent(piID, name)
+func (ctrl *Controller) AttachIntent(ctx context.Context, piID string, name string) error {
+	return ctrl.client.AttachIntent(ctx, piID, name)
 }
 
-func (ctrl *Controller) UpdatePayment(piID string) {
-	ctrl.client.SuccessfulPayment(piID)
+func (ctrl *Controller) UpdatePayment(ctx context.Context, piID string) error {
+	return ctrl.client.SuccessfulPayment(ctx, piID)
 }
 
-func (ctrl *Controller) RecordPayment(name string) error {
-	return ctrl.client.RecordPayment(name)
+func (ctrl *Controller) RecordPayment(ctx context.Context, name string) error {
+	return ctrl.client.RecordPayment(ctx, name)
 }
 
 func calculateOrderAmount(item models.SnrItem) int64 {
@@ -165,14 +552,28 @@ func (ctrl *Controller) GenerateDid(ctx context.Context, signature string, token
 	}
 
 	//figure out did
-	did := "did:sonr:" + signature
+	userDid := did.Sonr(signature).String()
 
-	if ctrl.client.FindDid(did).Did == "" {
+	// A DID issued before ids were escaped is returned as it was stored.
+	user, err := findDid(ctx, ctrl.client, userDid)
+	if errors.Is(err, db.ErrNotFound) {
 		// no record exist make a new one
-		ctrl.client.AddDid(did, result)
+		err = ctrl.client.AddDid(ctx, userDid, result)
+	} else if err == nil {
+		userDid = user.Did
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return []byte(did), err
+	return []byte(userDid), nil
+}
+
+// SessionToken issues a token authenticating did to the RPC service for the
+// login session s. It is signed with the node's secret key and stops working
+// when s ends; see CheckSession.
+func (ctrl *Controller) SessionToken(did string, s *models.Session) (string, time.Time, error) {
+	return auth.IssueToken([]byte(ctrl.privateKey), did, strconv.FormatUint(uint64(s.ID), 10), auth.TokenTTL)
 }
 
 func (ctrl *Controller) RegisterName(ctx context.Context, req *rt.MsgRegisterName, did string, cred *models.Credential) (*rt.MsgRegisterNameResponse, error) {
@@ -192,9 +593,8 @@ func (ctrl *Controller) RegisterName(ctx context.Context, req *rt.MsgRegisterNam
 
 	// check for name in db
 	fmt.Println(did)
-	user := ctrl.client.FindUserByName(req.NameToRegister)
-	if user.DisplayName == "" {
-		return &rt.MsgRegisterNameResponse{}, errors.New("user does not exist in DB")
+	if _, err := ctrl.FindUserByName(ctx, req.NameToRegister); err != nil {
+		return &rt.MsgRegisterNameResponse{}, err
 	}
 	// define a message to create a post
 	msg := &types.MsgRegisterName{
@@ -216,7 +616,9 @@ func (ctrl *Controller) RegisterName(ctx context.Context, req *rt.MsgRegisterNam
 		success = true
 	}
 	if success {
-		ctrl.client.StoreRecord(req.NameToRegister, did)
+		if err := ctrl.client.StoreRecord(ctx, req.NameToRegister, did); err != nil {
+			return &rt.MsgRegisterNameResponse{}, err
+		}
 	}
 
 	// WTF
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/config"
+	db "github.com/sonr-io/webauthn.io/database"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+var ctx = context.Background()
+
+// newTestController returns a controller over an empty memory store, which
+// is returned too for arranging state the controller has no call for.
+func newTestController(t *testing.T) (*Controller, *db.MemoryStore) {
+	store := db.NewMemoryStore()
+	ctrl, err := New(store, &config.SonrConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctrl, store
+}
+
+// register stores a credential with the raw ID id for user, creating the
+// user with the first one, and returns the credential's ID as stored.
+func register(t *testing.T, ctrl *Controller, user *models.User, id string) string {
+	cred := &webauthn.Credential{ID: []byte(id), PublicKey: []byte("key-" + id)}
+	c, err := ctrl.RegisterCredential(ctx, user, cred, "none", user.ID == 0)
+	if err != nil {
+		t.Fatalf("RegisterCredential(%s) = %v", id, err)
+	}
+	return c.CredentialID
+}
+
+// reload returns user as currently stored, with their credentials.
+func reload(t *testing.T, ctrl *Controller, user *models.User) *models.User {
+	u, err := ctrl.GetUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestRevokeCredentialOwnership(t *testing.T) {
+	ctrl, _ := newTestController(t)
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	first := register(t, ctrl, alice, "alice-1")
+	register(t, ctrl, alice, "alice-2")
+	bob := &models.User{Username: "bob", DisplayName: "bob"}
+	register(t, ctrl, bob, "bob-1")
+	bobs := register(t, ctrl, bob, "bob-2")
+
+	// Another user's credential is reported missing, and left alone.
+	err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), bobs)
+	if !errors.Is(err, models.ErrCredentialNotFound) {
+		t.Fatalf("revoking another user's credential: %v", err)
+	}
+	if n := len(reload(t, ctrl, bob).WebAuthnCredentials()); n != 2 {
+		t.Fatalf("bob has %d credentials after alice's attempt, want 2", n)
+	}
+	err = ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), base64.URLEncoding.EncodeToString([]byte("unknown")))
+	if !errors.Is(err, models.ErrCredentialNotFound) {
+		t.Fatalf("revoking an unknown credential: %v", err)
+	}
+
+	// The owner can revoke their own, which signs out only the sessions it
+	// logged in.
+	revoked, _, err := ctrl.StartSession(ctx, alice, first, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, _, err := ctrl.StartSession(ctx, alice, base64.URLEncoding.EncodeToString([]byte("alice-2")), "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), first); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ctrl.ResumeSession(ctx, revoked); !errors.Is(err, models.ErrSessionExpired) {
+		t.Fatalf("session of the revoked credential: %v", err)
+	}
+	if _, _, err := ctrl.ResumeSession(ctx, kept); err != nil {
+		t.Fatalf("session of another credential: %v", err)
+	}
+}
+
+func TestRevokeLastCredential(t *testing.T) {
+	ctrl, store := newTestController(t)
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	first := register(t, ctrl, alice, "alice-1")
+	second := register(t, ctrl, alice, "alice-2")
+
+	if err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), first); err != nil {
+		t.Fatal(err)
+	}
+	// Without recovery codes the last credential is kept.
+	err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), second)
+	if !errors.Is(err, models.ErrLastCredential) {
+		t.Fatalf("revoking the last credential: %v", err)
+	}
+	if n := len(reload(t, ctrl, alice).WebAuthnCredentials()); n != 1 {
+		t.Fatalf("alice has %d credentials, want 1", n)
+	}
+
+	// A disabled credential cannot log in, so it does not count and can
+	// always be revoked.
+	third := register(t, ctrl, alice, "alice-3")
+	if err := store.DisableCredential(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), third); !errors.Is(err, models.ErrLastCredential) {
+		t.Fatalf("revoking the last enabled credential: %v", err)
+	}
+	if err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), second); err != nil {
+		t.Fatalf("revoking a disabled credential: %v", err)
+	}
+
+	// With recovery codes the user can enroll another, so the last one
+	// goes too.
+	if _, err := ctrl.IssueRecoveryCodes(ctx, reload(t, ctrl, alice)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.RevokeCredential(ctx, reload(t, ctrl, alice), third); err != nil {
+		t.Fatalf("revoking the last credential with recovery codes: %v", err)
+	}
+	if n := len(reload(t, ctrl, alice).WebAuthnCredentials()); n != 0 {
+		t.Fatalf("alice has %d credentials, want none", n)
+	}
+}
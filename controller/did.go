@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/sonr-io/webauthn.io/database"
+	"github.com/sonr-io/webauthn.io/models"
+	"github.com/sonr-io/webauthn.io/pkg/did"
+)
+
+// Subject implements did.Source using the stored user that owns the DID.
+// Every WebAuthn credential becomes a verification method and every name an
+// alias.
+func (ctrl *Controller) Subject(ctx context.Context, id string) (*did.Subject, error) {
+	user, err := findDid(ctx, ctrl.client, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, did.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	subject := &did.Subject{}
+	for _, name := range user.Names {
+		subject.Names = append(subject.Names, models.TrimName(name)+models.NameSuffix)
+	}
+	for _, cred := range user.Credentials {
+		subject.Keys = append(subject.Keys, did.Key{
+			ID:        cred.CredentialID,
+			PublicKey: cred.PublicKey,
+		})
+	}
+	return subject, nil
+}
+
+// ResolveDid resolves a did:sonr identifier to its DID document.
+func (ctrl *Controller) ResolveDid(ctx context.Context, id string) (*did.Document, error) {
+	return did.NewResolver(ctrl).Resolve(ctx, id)
+}
+
+// findDid returns the user holding id. DIDs stored before method-specific
+// ids were escaped hold the id as it was given, so that form is looked up
+// too.
+func findDid(ctx context.Context, store db.Store, id string) (*models.User, error) {
+	user, err := store.FindDid(ctx, id)
+	if !errors.Is(err, db.ErrNotFound) {
+		return user, err
+	}
+	d, perr := did.Parse(id)
+	if perr != nil || d.Unescaped() == d.Base().String() {
+		return nil, err
+	}
+	return store.FindDid(ctx, d.Unescaped())
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/kataras/jwt"
+	"github.com/sonr-io/webauthn.io/models"
+	"github.com/sonr-io/webauthn.io/pkg/did"
+)
+
+func TestLegacyDidLookups(t *testing.T) {
+	ctrl, store := newTestController(t)
+
+	// A DID issued before ids were escaped is found by its escaped form,
+	// and generating it again returns the stored one instead of a copy.
+	signature := "c2lnbmF0dXJl=="
+	legacy := "did:sonr:" + signature
+	if err := store.AddDid(ctx, legacy, models.Jwt{Snr: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := ctrl.FindDid(ctx, did.Sonr(signature).String()); err != nil || u.Did != legacy {
+		t.Fatalf("FindDid(escaped) = %+v, %v", u, err)
+	}
+	token, err := jwt.Sign(jwt.HS256, []byte(signature), models.Jwt{Snr: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ctrl.GenerateDid(ctx, signature, string(token))
+	if err != nil || string(got) != legacy {
+		t.Fatalf("GenerateDid = %s, %v, want %s", got, err, legacy)
+	}
+	if _, err := store.FindDid(ctx, did.Sonr(signature).String()); err == nil {
+		t.Fatal("GenerateDid stored the escaped form of an issued DID")
+	}
+	if _, err := ctrl.ResolveDid(ctx, legacy); err != nil {
+		t.Fatalf("ResolveDid(legacy) = %v", err)
+	}
+}
+
+func TestLegacyPlaceholderReplaced(t *testing.T) {
+	ctrl, store := newTestController(t)
+	user := &models.User{Username: "alice", DisplayName: "Alice Smith", Did: "did:sonr:tempAlice Smith"}
+	if err := store.NewUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	cred := register(t, ctrl, user, "alice-1")
+	want := did.Sonr(cred).String()
+	if u := reload(t, ctrl, user); u.Did != want {
+		t.Fatalf("Did = %q after registering, want %q", u.Did, want)
+	}
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/sonr-io/webauthn.io/database"
+	"github.com/sonr-io/webauthn.io/models"
+	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
+)
+
+// NameAvailable reports whether name can be registered, accounting for the
+// ".snr" suffix and the reserved list.
+func (ctrl *Controller) NameAvailable(ctx context.Context, name string) (bool, error) {
+	name = models.TrimName(name)
+	if models.IsReservedName(name) {
+		return false, nil
+	}
+	return ctrl.CheckName(ctx, name)
+}
+
+// UpdateName updates the public profile attached to a registered DID. The
+// "display_name" and "icon" metadata keys are recognized.
+func (ctrl *Controller) UpdateName(ctx context.Context, req *rt.MsgUpdateName) (*rt.MsgUpdateNameResponse, error) {
+	user, err := findDid(ctx, ctrl.client, req.GetDid())
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, models.ErrNameNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	md := req.GetMetadata()
+	if v, ok := md["display_name"]; ok {
+		user.DisplayName = v
+	}
+	if v, ok := md["icon"]; ok {
+		user.Icon = v
+	}
+	if err := ctrl.client.UpdateProfile(ctx, user.Did, user.DisplayName, user.Icon); err != nil {
+		return nil, err
+	}
+	return &rt.MsgUpdateNameResponse{}, nil
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	db "github.com/sonr-io/webauthn.io/database"
+	log "github.com/sonr-io/webauthn.io/logger"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// Session timeouts used when the configuration sets none.
+const (
+	DefaultSessionIdleTimeout = 30 * time.Minute
+	DefaultSessionLifetime    = 24 * time.Hour
+)
+
+// sessionTouchInterval is how stale a session's last use may be before a
+// request records it again, so browsing does not write on every request.
+const sessionTouchInterval = time.Minute
+
+func orDefault(d time.Duration, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// StartSession logs user in on the browser with the given user agent and
+// IP address, using the credential credentialID. It returns the token for
+// the browser's cookie, which is only stored hashed.
+func (ctrl *Controller) StartSession(ctx context.Context, user *models.User, credentialID string, userAgent string, ip string) (string, *models.Session, error) {
+	token, hash, err := models.NewSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	// Sessions abandoned without signing out are cleaned up as others
+	// start.
+	if err := ctrl.client.DeleteExpiredSessions(ctx, now); err != nil {
+		log.Warnf("error deleting expired sessions: %v", err)
+	}
+	s := &models.Session{
+		TokenHash:    hash,
+		UserID:       user.ID,
+		CredentialID: credentialID,
+		UserAgent:    userAgent,
+		Device:       models.DescribeUserAgent(userAgent),
+		IP:           ip,
+		LastSeen:     now,
+		Expires:      now.Add(ctrl.sessionLifetime),
+	}
+	s.IdleExpires = ctrl.idleExpiry(s, now)
+	if err := ctrl.client.CreateSession(ctx, s); err != nil {
+		return "", nil, err
+	}
+	return token, s, nil
+}
+
+// ResumeSession returns the session a cookie's token belongs to and its
+// user, recording the use. A token of no active session is
+// models.ErrSessionExpired.
+func (ctrl *Controller) ResumeSession(ctx context.Context, token string) (*models.Session, *models.User, error) {
+	s, err := ctrl.client.GetSession(ctx, models.HashSessionToken(token))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil, models.ErrSessionExpired
+	} else if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if !s.Active(now) {
+		if err := ctrl.client.DeleteSession(ctx, s.ID); err != nil && !errors.Is(err, db.ErrNotFound) {
+			return nil, nil, err
+		}
+		return nil, nil, models.ErrSessionExpired
+	}
+	user, err := ctrl.client.GetUser(ctx, s.UserID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil, models.ErrSessionExpired
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if now.Sub(s.LastSeen) >= sessionTouchInterval {
+		s.LastSeen, s.IdleExpires = now, ctrl.idleExpiry(s, now)
+		if err := ctrl.client.TouchSession(ctx, s.ID, s.LastSeen, s.IdleExpires); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s, user, nil
+}
+
+// idleExpiry is when s times out if it is not used again after now. It
+// never extends past the session's absolute timeout.
+func (ctrl *Controller) idleExpiry(s *models.Session, now time.Time) time.Time {
+	idle := now.Add(ctrl.sessionIdle)
+	if idle.After(s.Expires) {
+		return s.Expires
+	}
+	return idle
+}
+
+// ListSessions returns owner's active sessions, newest first.
+func (ctrl *Controller) ListSessions(ctx context.Context, owner *models.User) ([]models.Session, error) {
+	sessions, err := ctrl.client.GetSessions(ctx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	active := sessions[:0]
+	for _, s := range sessions {
+		if s.Active(now) {
+			active = append(active, s)
+		}
+	}
+	return active, nil
+}
+
+// EndSession signs out one of owner's sessions. Sessions of other users
+// are reported as models.ErrSessionNotFound.
+func (ctrl *Controller) EndSession(ctx context.Context, owner *models.User, id uint) error {
+	sessions, err := ctrl.client.GetSessions(ctx, owner.ID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.ID == id {
+			err := ctrl.client.DeleteSession(ctx, id)
+			if errors.Is(err, db.ErrNotFound) {
+				return models.ErrSessionNotFound
+			}
+			return err
+		}
+	}
+	return models.ErrSessionNotFound
+}
+
+// CheckSession returns models.ErrSessionExpired unless the login session with
+// the given ID is active and belongs to the user owning did. The RPC service
+// checks it for every session token.
+func (ctrl *Controller) CheckSession(ctx context.Context, session string, did string) error {
+	id, err := strconv.ParseUint(session, 10, 64)
+	if err != nil {
+		return models.ErrSessionExpired
+	}
+	user, err := findDid(ctx, ctrl.client, did)
+	if errors.Is(err, db.ErrNotFound) {
+		return models.ErrSessionExpired
+	} else if err != nil {
+		return err
+	}
+	sessions, err := ctrl.client.GetSessions(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, s := range sessions {
+		if s.ID == uint(id) && s.Active(now) {
+			return nil
+		}
+	}
+	return models.ErrSessionExpired
+}
+
+// EndAllSessions signs owner out everywhere.
+func (ctrl *Controller) EndAllSessions(ctx context.Context, owner *models.User) error {
+	return ctrl.client.DeleteSessions(ctx, owner.ID, "")
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddAuditEvent records e, assigning it an ID.
+func (db *MongoClient) AddAuditEvent(ctx context.Context, e *models.AuditEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	id, err := db.nextID(ctx, "audit")
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	e.CreatedAt = time.Now()
+	e.UpdatedAt = e.CreatedAt
+	_, err = db.audit.InsertOne(ctx, e)
+	return mongoError(err)
+}
+
+// GetAuditEvents returns the user's audit events, newest first.
+func (db *MongoClient) GetAuditEvents(ctx context.Context, userID uint) ([]models.AuditEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	opts := options.Find().SetSort(bson.D{{Key: "model.id", Value: -1}})
+	cur, err := db.audit.Find(ctx, bson.M{"userid": userID}, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	events := []models.AuditEvent{}
+	if err := cur.All(ctx, &events); err != nil {
+		return nil, mongoError(err)
+	}
+	return events, nil
+}
@@ -10,33 +10,74 @@ import (
 )
 
 // GetAuthenticator returns the authenticator the given id corresponds to. If
-// no authenticator is found, an error is thrown.
-func (db *MongoClient) GetAuthenticator(id uint) *models.Authenticator {
-	collection := db.auths
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// no authenticator is found, ErrNotFound is returned.
+func (db *MongoClient) GetAuthenticator(ctx context.Context, id uint) (*models.Authenticator, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
 	auth := &models.Authenticator{}
-	collection.FindOne(ctx, bson.M{"id": id}).Decode(auth)
-	return auth
+	if err := db.auths.FindOne(ctx, bson.M{"model.id": id}).Decode(auth); err != nil {
+		return nil, mongoError(err)
+	}
+	return auth, nil
 }
 
 // CreateAuthenticator creates a new authenticator that's tied to a Credential.
-func (db *MongoClient) CreateAuthenticator(a webauthn.Authenticator) *models.Authenticator {
-	collection := db.auths
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (db *MongoClient) CreateAuthenticator(ctx context.Context, a webauthn.Authenticator, attestationType string) (*models.Authenticator, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
+	id, err := db.nextID(ctx, "auths")
+	if err != nil {
+		return nil, err
+	}
 	auth := &models.Authenticator{}
+	auth.ID = id
+	auth.CreatedAt = time.Now()
+	auth.UpdatedAt = auth.CreatedAt
 	auth.Authenticator = a
-	collection.InsertOne(ctx, auth)
-	return auth
+	auth.AttestationType = attestationType
+	if _, err := db.auths.InsertOne(ctx, auth); err != nil {
+		return nil, mongoError(err)
+	}
+	return auth, nil
 }
 
-// UpdateAuthenticatorSignCount updates a specific authenticator's sign count for tracking
-// potential clone attempts.
-func (db *MongoClient) UpdateAuthenticatorSignCount(id uint, count uint32) error {
-	collection := db.auths
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// signCountPath is where a credential document keeps its authenticator's
+// sign count.
+const signCountPath = "authenticator.authenticator.signcount"
+
+// UpdateSignCount stores the sign count the credential's authenticator
+// reported. The user's embedded copy, which logins read, is checked and set
+// in one update; the creds and auths copies follow with $max, so they never
+// move backwards whatever order concurrent logins land in.
+func (db *MongoClient) UpdateSignCount(ctx context.Context, credentialID string, count uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	collection.FindOneAndUpdate(ctx, bson.M{"id": id}, bson.M{"sign_count": count})
-	return nil
+	accepted := bson.M{"$lt": count}
+	if count == 0 {
+		accepted = bson.M{"$eq": 0}
+	}
+	res, err := db.users.UpdateOne(ctx,
+		bson.M{"credentials": bson.M{"$elemMatch": bson.M{"credentialid": credentialID, signCountPath: accepted}}},
+		bson.M{"$set": bson.M{"credentials.$." + signCountPath: count}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.MatchedCount == 0 {
+		n, err := db.users.CountDocuments(ctx, bson.M{"credentials.credentialid": credentialID})
+		if err != nil {
+			return mongoError(err)
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return ErrStale
+	}
+
+	cred := &models.Credential{}
+	err = db.creds.FindOneAndUpdate(ctx, bson.M{"credentialid": credentialID}, bson.M{"$max": bson.M{signCountPath: count}}).Decode(cred)
+	if err != nil {
+		return mongoError(err)
+	}
+	_, err = db.auths.UpdateOne(ctx, bson.M{"model.id": cred.AuthenticatorID}, bson.M{"$max": bson.M{"authenticator.signcount": count}})
+	return mongoError(err)
 }
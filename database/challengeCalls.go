@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CreateChallenge stores c, assigning it an ID.
+func (db *MongoClient) CreateChallenge(ctx context.Context, c *models.Challenge) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	id, err := db.nextID(ctx, "challenges")
+	if err != nil {
+		return err
+	}
+	c.ID = id
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+	_, err = db.challenges.InsertOne(ctx, c)
+	return mongoError(err)
+}
+
+// TakeChallenge deletes and returns the challenge whose ID hashes to
+// idHash in one step, so that concurrent requests cannot both use it.
+func (db *MongoClient) TakeChallenge(ctx context.Context, idHash string) (*models.Challenge, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	c := &models.Challenge{}
+	if err := db.challenges.FindOneAndDelete(ctx, bson.M{"idhash": idHash}).Decode(c); err != nil {
+		return nil, mongoError(err)
+	}
+	return c, nil
+}
+
+// CountChallenges counts the challenges still active at now.
+func (db *MongoClient) CountChallenges(ctx context.Context, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	n, err := db.challenges.CountDocuments(ctx, bson.M{"expires": bson.M{"$gt": now}})
+	return int(n), mongoError(err)
+}
+
+// DeleteExpiredChallenges deletes the challenges past their timeout at now,
+// ahead of the TTL index.
+func (db *MongoClient) DeleteExpiredChallenges(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	_, err := db.challenges.DeleteMany(ctx, bson.M{"expires": bson.M{"$lte": now}})
+	return mongoError(err)
+}
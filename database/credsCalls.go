@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/sonr-io/webauthn.io/models"
@@ -11,79 +10,137 @@ import (
 )
 
 // CreateCredential creates a new credential object
-func (db *MongoClient) CreateCredential(c *models.Credential) error {
-	collection := db.creds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (db *MongoClient) CreateCredential(ctx context.Context, c *models.Credential) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	collection.InsertOne(ctx, c)
-	return nil
+	if c.ID == 0 {
+		id, err := db.nextID(ctx, "creds")
+		if err != nil {
+			return err
+		}
+		c.ID = id
+	}
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+	_, err := db.creds.InsertOne(ctx, c)
+	return mongoError(err)
 }
 
 // UpdateCredential updates the credential with new attributes.
-func (db *MongoClient) UpdateCredential(c *models.Credential) error {
-	collection := db.creds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (db *MongoClient) UpdateCredential(ctx context.Context, c *models.Credential) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	collection.FindOneAndUpdate(ctx, bson.M{"id": c.ID}, c)
+	c.UpdatedAt = time.Now()
+	res, err := db.creds.ReplaceOne(ctx, bson.M{"model.id": c.ID}, c)
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
 // GetCredentialsForUser retrieves all credentials for a provided user regardless of relying party.
-func (db *MongoClient) GetCredentialsForUser(user *models.User) ([]models.Credential, error) {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (db *MongoClient) GetCredentialsForUser(ctx context.Context, user *models.User) ([]models.Credential, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	opts := options.FindOne().SetProjection(bson.D{{"credentials", 1}})
-	result := collection.FindOne(ctx, bson.M{"id": user.ID}, opts)
+	opts := options.FindOne().SetProjection(bson.D{{Key: "credentials", Value: 1}})
 	temp := models.User{}
-	err := result.Decode(temp)
-	if err != nil {
-		return nil, err
+	if err := db.users.FindOne(ctx, bson.M{"model.id": user.ID}, opts).Decode(&temp); err != nil {
+		return nil, mongoError(err)
 	}
 	creds := temp.Credentials
+	if creds == nil {
+		creds = []models.Credential{}
+	}
 	return creds, nil
 }
 
-// GetCredentialForUser retrieves a specific credential for a user.
-func (db *MongoClient) GetCredentialForUser(user *models.User, credentialID string) (models.Credential, error) {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	collection.FindOne(ctx, bson.M{"id": user.ID}).Decode(user)
+// GetCredentialForUser retrieves a specific credential for a user, reloading
+// the user in place.
+func (db *MongoClient) GetCredentialForUser(ctx context.Context, user *models.User, credentialID string) (models.Credential, error) {
+	stored, err := db.GetUser(ctx, user.ID)
+	if err != nil {
+		return models.Credential{}, err
+	}
+	*user = *stored
 
 	for _, v := range user.Credentials {
-		if string(v.ID) == credentialID {
+		if v.CredentialID == credentialID {
 			return v, nil
 		}
 	}
-
-	return models.Credential{}, errors.New("cred not found on user")
+	return models.Credential{}, ErrNotFound
 }
 
-// DeleteCredentialByID gets a credential by its ID. In practice, this would be a bad function without
-// some other checks (like what user is logged in) because someone could hypothetically delete ANY credential.
-func (db *MongoClient) DeleteCredentialByID(credentialID string) error {
-	collection := db.creds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// DeleteCredentialByID deletes a credential by its ID. It does not check who
+// owns the credential; callers must.
+func (db *MongoClient) DeleteCredentialByID(ctx context.Context, credentialID string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	collection.FindOneAndDelete(ctx, bson.M{"id": credentialID})
+	deleted, err := db.creds.DeleteOne(ctx, bson.M{"credentialid": credentialID})
+	if err != nil {
+		return mongoError(err)
+	}
+	pulled, err := db.users.UpdateOne(ctx,
+		bson.M{"credentials.credentialid": credentialID},
+		bson.M{"$pull": bson.M{"credentials": bson.M{"credentialid": credentialID}}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if deleted.DeletedCount == 0 && pulled.ModifiedCount == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
-// GetCredentialForUser retrieves a specific credential for a user.
-func (db *MongoClient) GiveUserCred(username string, cred *models.Credential) error {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// setCredential sets fields on both stored copies of a credential.
+func (db *MongoClient) setCredential(ctx context.Context, credentialID string, fields bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
+	stored, err := db.creds.UpdateOne(ctx, bson.M{"credentialid": credentialID}, bson.M{"$set": fields})
+	if err != nil {
+		return mongoError(err)
+	}
+	embeddedFields := bson.M{}
+	for k, v := range fields {
+		embeddedFields["credentials.$."+k] = v
+	}
+	embedded, err := db.users.UpdateOne(ctx,
+		bson.M{"credentials.credentialid": credentialID},
+		bson.M{"$set": embeddedFields})
+	if err != nil {
+		return mongoError(err)
+	}
+	if stored.MatchedCount == 0 && embedded.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DisableCredential stops the credential from being used to log in.
+func (db *MongoClient) DisableCredential(ctx context.Context, credentialID string) error {
+	return db.setCredential(ctx, credentialID, bson.M{"disabled": true})
+}
+
+// RenameCredential sets the credential's nickname.
+func (db *MongoClient) RenameCredential(ctx context.Context, credentialID string, nickname string) error {
+	return db.setCredential(ctx, credentialID, bson.M{"nickname": nickname})
+}
+
+// GiveUserCred attaches a copy of cred to the user with the given username.
+func (db *MongoClient) GiveUserCred(ctx context.Context, username string, cred *models.Credential) error {
 	newCred := models.Credential{
+		Model:           cred.Model,
 		CredentialID:    cred.CredentialID,
 		User:            cred.User,
 		UserID:          cred.UserID,
 		Authenticator:   cred.Authenticator,
 		AuthenticatorID: cred.AuthenticatorID,
 		PublicKey:       cred.PublicKey,
+		Nickname:        cred.Nickname,
+		Disabled:        cred.Disabled,
 	}
-
-	collection.FindOneAndUpdate(ctx, bson.M{"username": username}, bson.M{"$push": bson.M{"credentials": newCred}})
-	return nil
+	return db.updateUser(ctx, bson.M{"username": username}, bson.M{"$push": bson.M{"credentials": newCred}})
 }
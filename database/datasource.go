@@ -4,15 +4,27 @@ import (
 	"context"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// queryTimeout bounds every query on top of the caller's deadline.
+const queryTimeout = 5 * time.Second
+
 type MongoClient struct {
-	client *mongo.Client
-	users  *mongo.Collection
-	auths  *mongo.Collection
-	creds  *mongo.Collection
+	client     *mongo.Client
+	users      *mongo.Collection
+	auths      *mongo.Collection
+	creds      *mongo.Collection
+	counters   *mongo.Collection
+	migrations *mongo.Collection
+	audit      *mongo.Collection
+	sessions   *mongo.Collection
+
+	recoveryGrants *mongo.Collection
+	challenges     *mongo.Collection
 }
 
 func Connect(mongoURI string, collection string, mongoName string) (*MongoClient, error) {
@@ -23,15 +35,60 @@ func Connect(mongoURI string, collection string, mongoName string) (*MongoClient
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	client.Connect(ctx)
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
 	return &MongoClient{
-		client: client,
-		users:  client.Database(mongoName).Collection("users"),
-		auths:  client.Database(mongoName).Collection("auths"),
-		creds:  client.Database(mongoName).Collection("creds"),
+		client:     client,
+		users:      client.Database(mongoName).Collection("users"),
+		auths:      client.Database(mongoName).Collection("auths"),
+		creds:      client.Database(mongoName).Collection("creds"),
+		counters:   client.Database(mongoName).Collection("counters"),
+		migrations: client.Database(mongoName).Collection("migrations"),
+		audit:      client.Database(mongoName).Collection("audit"),
+		sessions:   client.Database(mongoName).Collection("sessions"),
+
+		recoveryGrants: client.Database(mongoName).Collection("recoverygrants"),
+		challenges:     client.Database(mongoName).Collection("challenges"),
 	}, nil
 }
 
-func (db *MongoClient) Disconnect() {
-	db.client.Disconnect(context.Background())
+// nextID hands out the next primary key for collection. Mongo has no
+// autoincrement, so keys are drawn from one counter document per collection.
+func (db *MongoClient) nextID(ctx context.Context, collection string) (uint, error) {
+	var counter struct {
+		Seq uint64 `bson:"seq"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := db.counters.FindOneAndUpdate(ctx, bson.M{"_id": collection}, bson.M{"$inc": bson.M{"seq": 1}}, opts).Decode(&counter)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	return uint(counter.Seq), nil
+}
+
+// Transaction runs fn in a multi-document transaction, retrying it on
+// transient errors. Mongo only supports transactions on replica sets and
+// sharded clusters; a standalone server rejects them.
+func (db *MongoClient) Transaction(ctx context.Context, fn func(ctx context.Context, tx Store) error) error {
+	sess, err := db.client.StartSession()
+	if err != nil {
+		return mongoError(err)
+	}
+	defer sess.EndSession(ctx)
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc, db)
+	})
+	return mongoError(err)
+}
+
+// Ping blocks until the primary is reachable or ctx expires.
+func (db *MongoClient) Ping(ctx context.Context) error {
+	return mongoError(db.client.Ping(ctx, readpref.Primary()))
+}
+
+// Disconnect closes every pooled connection, waiting for in-use connections
+// to be returned until ctx expires.
+func (db *MongoClient) Disconnect(ctx context.Context) error {
+	return db.client.Disconnect(ctx)
 }
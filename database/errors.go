@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/mattn/go-sqlite3"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by every Store. Backend errors are wrapped, so callers
+// should compare with errors.Is.
+var (
+	ErrNotFound    error = &storeError{"record not found", codes.NotFound}
+	ErrDuplicate   error = &storeError{"record already exists", codes.AlreadyExists}
+	ErrUnavailable error = &storeError{"database unavailable", codes.Unavailable}
+	ErrStale       error = &storeError{"update is older than the stored record", codes.FailedPrecondition}
+)
+
+// storeError is a store failure of a known kind. It carries the gRPC status
+// it is reported as, so the RPC layer can map it without importing this
+// package.
+type storeError struct {
+	msg  string
+	code codes.Code
+}
+
+func (e *storeError) Error() string {
+	return e.msg
+}
+
+// GRPCStatus implements the interface grpc/status looks for.
+func (e *storeError) GRPCStatus() *status.Status {
+	return status.New(e.code, e.msg)
+}
+
+// wrap annotates err as kind while keeping the driver's message.
+func wrap(kind error, err error) error {
+	return fmt.Errorf("%w: %v", kind, err)
+}
+
+// mongoError translates a mongo driver error into a store error.
+func mongoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return wrap(ErrDuplicate, err)
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected):
+		return wrap(ErrUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// sqlError translates a gorm or sqlite error into a store error.
+func sqlError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrNotFound
+	}
+	var se sqlite3.Error
+	if errors.As(err, &se) {
+		switch {
+		case se.ExtendedCode == sqlite3.ErrConstraintUnique, se.ExtendedCode == sqlite3.ErrConstraintPrimaryKey:
+			return wrap(ErrDuplicate, err)
+		case se.Code == sqlite3.ErrBusy, se.Code == sqlite3.ErrLocked, se.Code == sqlite3.ErrCantOpen, se.Code == sqlite3.ErrIoErr:
+			return wrap(ErrUnavailable, err)
+		}
+	}
+	if errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) {
+		return wrap(ErrUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,806 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// MemoryStore is a Store that keeps everything in process memory. Nothing
+// survives a restart; it is meant for local development and tests.
+type MemoryStore struct {
+	mu       sync.Mutex
+	nextID   uint
+	users    map[uint]models.User
+	creds    map[uint]models.Credential
+	auths    map[uint]models.Authenticator
+	audit    []models.AuditEvent
+	sessions map[uint]models.Session
+	grants   map[uint]models.RecoveryGrant
+	chals    map[uint]models.Challenge
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:    make(map[uint]models.User),
+		creds:    make(map[uint]models.Credential),
+		auths:    make(map[uint]models.Authenticator),
+		sessions: make(map[uint]models.Session),
+		grants:   make(map[uint]models.RecoveryGrant),
+		chals:    make(map[uint]models.Challenge),
+	}
+}
+
+// lock acquires mu unless ctx is already done.
+func (m *MemoryStore) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	return nil
+}
+
+// id hands out primary keys the way an autoincrement column would. The
+// caller holds mu.
+func (m *MemoryStore) id() uint {
+	m.nextID++
+	return m.nextID
+}
+
+// find returns the oldest user matching fn. The caller holds mu.
+func (m *MemoryStore) find(fn func(u *models.User) bool) (models.User, bool) {
+	var found models.User
+	for _, u := range m.users {
+		if fn(&u) && (found.ID == 0 || u.ID < found.ID) {
+			found = u
+		}
+	}
+	return found, found.ID != 0
+}
+
+// get returns a copy of the oldest user matching fn with its credentials
+// attached. The caller holds mu.
+func (m *MemoryStore) get(fn func(u *models.User) bool) (*models.User, error) {
+	u, ok := m.find(fn)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	u.Names = append([]string(nil), u.Names...)
+	u.RecoveryCodes = append(models.RecoveryCodes(nil), u.RecoveryCodes...)
+	u.Credentials = m.credentialsFor(u.ID)
+	return &u, nil
+}
+
+func (m *MemoryStore) credentialsFor(userID uint) []models.Credential {
+	creds := []models.Credential{}
+	for _, c := range m.creds {
+		if c.UserID == userID {
+			c.Authenticator = m.auths[c.AuthenticatorID]
+			creds = append(creds, c)
+		}
+	}
+	sort.Slice(creds, func(i, j int) bool { return creds[i].ID < creds[j].ID })
+	return creds
+}
+
+// save stores a copy of u, assigning it an ID if it has none. Names,
+// usernames, DIDs and payment intents are unique across users when set, as
+// the other backends' indexes enforce. The caller holds mu.
+func (m *MemoryStore) save(u *models.User) error {
+	for _, name := range u.Names {
+		if owner, taken := m.find(func(o *models.User) bool { return hasName(o, name) }); taken && owner.ID != u.ID {
+			return ErrDuplicate
+		}
+	}
+	for _, o := range m.users {
+		if o.ID != u.ID && (sameKey(o.Username, u.Username) || sameKey(o.Did, u.Did) || sameKey(o.PiID, u.PiID)) {
+			return ErrDuplicate
+		}
+	}
+	if u.ID == 0 {
+		u.ID = m.id()
+	}
+	if _, ok := m.users[u.ID]; !ok {
+		u.CreatedAt = time.Now()
+	}
+	u.UpdatedAt = time.Now()
+	stored := *u
+	stored.Names = append([]string(nil), u.Names...)
+	stored.RecoveryCodes = append(models.RecoveryCodes(nil), u.RecoveryCodes...)
+	stored.Credentials = nil
+	m.users[u.ID] = stored
+	return nil
+}
+
+// update applies fn to the oldest user matching match. The caller holds mu.
+func (m *MemoryStore) update(match func(u *models.User) bool, fn func(u *models.User)) error {
+	u, ok := m.find(match)
+	if !ok {
+		return ErrNotFound
+	}
+	fn(&u)
+	return m.save(&u)
+}
+
+func (m *MemoryStore) NewUser(ctx context.Context, user *models.User) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.users[user.ID]; ok {
+		return ErrDuplicate
+	}
+	user.Credentials = []models.Credential{}
+	user.Created = time.Now()
+	return m.save(user)
+}
+
+func (m *MemoryStore) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.get(func(u *models.User) bool { return u.ID == id })
+}
+
+func (m *MemoryStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.get(func(u *models.User) bool { return u.Username == username })
+}
+
+// PutUser creates u, or replaces the stored user with the same ID.
+func (m *MemoryStore) PutUser(ctx context.Context, u *models.User) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.save(u)
+}
+
+// UpdateProfile sets the public display fields of the user owning did.
+func (m *MemoryStore) UpdateProfile(ctx context.Context, did string, displayName string, icon string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.Did == did }, func(u *models.User) {
+		u.DisplayName = displayName
+		u.Icon = icon
+	})
+}
+
+// SetRecoveryCodes replaces the recovery code hashes of a user.
+func (m *MemoryStore) SetRecoveryCodes(ctx context.Context, userID uint, hashes models.RecoveryCodes) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.ID == userID }, func(u *models.User) {
+		u.RecoveryCodes = append(models.RecoveryCodes{}, hashes...)
+	})
+}
+
+// UseRecoveryCode removes hash from the user's recovery codes.
+func (m *MemoryStore) UseRecoveryCode(ctx context.Context, userID uint, hash string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	u, ok := m.users[userID]
+	if !ok || len(u.RecoveryCodes.Without(hash)) == len(u.RecoveryCodes) {
+		return ErrNotFound
+	}
+	u.RecoveryCodes = u.RecoveryCodes.Without(hash)
+	return m.save(&u)
+}
+
+func (m *MemoryStore) FindDid(ctx context.Context, did string) (*models.User, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.get(func(u *models.User) bool { return u.Did == did })
+}
+
+func (m *MemoryStore) AddDid(ctx context.Context, did string, jwt models.Jwt) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.save(&models.User{Did: did, Jwt: jwt})
+}
+
+func (m *MemoryStore) AttachDid(ctx context.Context, userID uint, placeHolderDid string, newDid string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.ID == userID && u.Did == placeHolderDid }, func(u *models.User) { u.Did = newDid })
+}
+
+// CheckName reports whether name is available.
+func (m *MemoryStore) CheckName(ctx context.Context, name string) (bool, error) {
+	if err := m.lock(ctx); err != nil {
+		return false, err
+	}
+	defer m.mu.Unlock()
+	_, taken := m.find(func(u *models.User) bool { return hasName(u, name) })
+	return !taken, nil
+}
+
+// StoreRecord adds nameToRecord to the names of the user owning did.
+func (m *MemoryStore) StoreRecord(ctx context.Context, nameToRecord string, did string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.Did == did }, func(u *models.User) {
+		if !hasName(u, nameToRecord) {
+			u.Names = append(u.Names, nameToRecord)
+		}
+	})
+}
+
+func (m *MemoryStore) FindUserByName(ctx context.Context, name string) (*models.User, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.get(func(u *models.User) bool { return hasName(u, name) })
+}
+
+// sameKey reports whether two values of a unique key collide. Empty values
+// never do.
+func sameKey(a, b string) bool {
+	return a != "" && a == b
+}
+
+func hasName(u *models.User, name string) bool {
+	for _, n := range u.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCredential creates a new credential object
+func (m *MemoryStore) CreateCredential(ctx context.Context, c *models.Credential) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.creds[c.ID]; ok {
+		return ErrDuplicate
+	}
+	for _, o := range m.creds {
+		if sameKey(o.CredentialID, c.CredentialID) {
+			return ErrDuplicate
+		}
+	}
+	m.saveCredential(c)
+	return nil
+}
+
+// saveCredential stores a copy of c, assigning it an ID if it has none. The
+// caller holds mu.
+func (m *MemoryStore) saveCredential(c *models.Credential) {
+	if c.ID == 0 {
+		c.ID = m.id()
+	}
+	if _, ok := m.creds[c.ID]; !ok {
+		c.CreatedAt = time.Now()
+	}
+	c.UpdatedAt = time.Now()
+	stored := *c
+	stored.User = models.User{}
+	m.creds[c.ID] = stored
+}
+
+// UpdateCredential updates the credential with new attributes.
+func (m *MemoryStore) UpdateCredential(ctx context.Context, c *models.Credential) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.creds[c.ID]; !ok {
+		return ErrNotFound
+	}
+	m.saveCredential(c)
+	return nil
+}
+
+// GetCredentialsForUser retrieves all credentials for a provided user regardless of relying party.
+func (m *MemoryStore) GetCredentialsForUser(ctx context.Context, user *models.User) ([]models.Credential, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.users[user.ID]; !ok {
+		return nil, ErrNotFound
+	}
+	return m.credentialsFor(user.ID), nil
+}
+
+// GetCredentialForUser retrieves a specific credential for a user, reloading
+// the user in place.
+func (m *MemoryStore) GetCredentialForUser(ctx context.Context, user *models.User, credentialID string) (models.Credential, error) {
+	if err := m.lock(ctx); err != nil {
+		return models.Credential{}, err
+	}
+	defer m.mu.Unlock()
+	id := user.ID
+	stored, err := m.get(func(u *models.User) bool { return u.ID == id })
+	if err != nil {
+		return models.Credential{}, err
+	}
+	*user = *stored
+	for _, c := range user.Credentials {
+		if c.CredentialID == credentialID {
+			return c, nil
+		}
+	}
+	return models.Credential{}, ErrNotFound
+}
+
+// DeleteCredentialByID deletes the credential with the given credential ID.
+func (m *MemoryStore) DeleteCredentialByID(ctx context.Context, credentialID string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	deleted := false
+	for id, c := range m.creds {
+		if c.CredentialID == credentialID {
+			delete(m.creds, id)
+			deleted = true
+		}
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DisableCredential stops the credential from being used to log in.
+func (m *MemoryStore) DisableCredential(ctx context.Context, credentialID string) error {
+	return m.updateCredential(ctx, credentialID, func(c *models.Credential) { c.Disabled = true })
+}
+
+// RenameCredential sets the credential's nickname.
+func (m *MemoryStore) RenameCredential(ctx context.Context, credentialID string, nickname string) error {
+	return m.updateCredential(ctx, credentialID, func(c *models.Credential) { c.Nickname = nickname })
+}
+
+// updateCredential applies fn to the credential with the given ID.
+func (m *MemoryStore) updateCredential(ctx context.Context, credentialID string, fn func(c *models.Credential)) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	c, ok := m.credential(credentialID)
+	if !ok {
+		return ErrNotFound
+	}
+	fn(&c)
+	c.UpdatedAt = time.Now()
+	m.creds[c.ID] = c
+	return nil
+}
+
+// credential returns the credential with the given credential ID. The caller
+// holds mu.
+func (m *MemoryStore) credential(credentialID string) (models.Credential, bool) {
+	for _, c := range m.creds {
+		if c.CredentialID == credentialID {
+			return c, true
+		}
+	}
+	return models.Credential{}, false
+}
+
+// GiveUserCred attaches cred to the user with the given username.
+func (m *MemoryStore) GiveUserCred(ctx context.Context, username string, cred *models.Credential) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	u, ok := m.find(func(u *models.User) bool { return u.Username == username })
+	if !ok {
+		return ErrNotFound
+	}
+	cred.UserID = u.ID
+	m.saveCredential(cred)
+	return nil
+}
+
+// GetAuthenticator returns the authenticator the given id corresponds to.
+func (m *MemoryStore) GetAuthenticator(ctx context.Context, id uint) (*models.Authenticator, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	a, ok := m.auths[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &a, nil
+}
+
+// CreateAuthenticator creates a new authenticator that's tied to a Credential.
+func (m *MemoryStore) CreateAuthenticator(ctx context.Context, a webauthn.Authenticator, attestationType string) (*models.Authenticator, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	auth := models.Authenticator{Authenticator: a, AttestationType: attestationType}
+	auth.ID = m.id()
+	auth.CreatedAt = time.Now()
+	auth.UpdatedAt = auth.CreatedAt
+	m.auths[auth.ID] = auth
+	return &auth, nil
+}
+
+// UpdateSignCount stores the sign count the credential's authenticator
+// reported.
+func (m *MemoryStore) UpdateSignCount(ctx context.Context, credentialID string, count uint32) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	c, ok := m.credential(credentialID)
+	if !ok {
+		return ErrNotFound
+	}
+	a, ok := m.auths[c.AuthenticatorID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !signCountAdvances(a.SignCount, count) {
+		return ErrStale
+	}
+	a.SignCount = count
+	a.UpdatedAt = time.Now()
+	m.auths[a.ID] = a
+	return nil
+}
+
+// AddAuditEvent records e, assigning it an ID.
+func (m *MemoryStore) AddAuditEvent(ctx context.Context, e *models.AuditEvent) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	e.ID = m.id()
+	e.CreatedAt = time.Now()
+	e.UpdatedAt = e.CreatedAt
+	m.audit = append(m.audit, *e)
+	return nil
+}
+
+// GetAuditEvents returns the user's audit events, newest first.
+func (m *MemoryStore) GetAuditEvents(ctx context.Context, userID uint) ([]models.AuditEvent, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	events := []models.AuditEvent{}
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		if m.audit[i].UserID == userID {
+			events = append(events, m.audit[i])
+		}
+	}
+	return events, nil
+}
+
+// CreateSession stores s, assigning it an ID. Token hashes are unique, as
+// the other backends' indexes enforce.
+func (m *MemoryStore) CreateSession(ctx context.Context, s *models.Session) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	for _, o := range m.sessions {
+		if o.TokenHash == s.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	s.ID = m.id()
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	m.sessions[s.ID] = *s
+	return nil
+}
+
+func (m *MemoryStore) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	for _, s := range m.sessions {
+		if s.TokenHash == tokenHash {
+			return &s, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// GetSessions returns the user's sessions, newest first.
+func (m *MemoryStore) GetSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	sessions := []models.Session{}
+	for _, s := range m.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID > sessions[j].ID })
+	return sessions, nil
+}
+
+func (m *MemoryStore) TouchSession(ctx context.Context, id uint, lastSeen time.Time, idleExpires time.Time) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.LastSeen = lastSeen
+	s.IdleExpires = idleExpires
+	s.UpdatedAt = time.Now()
+	m.sessions[id] = s
+	return nil
+}
+
+func (m *MemoryStore) DeleteSession(ctx context.Context, id uint) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.sessions, id)
+	return nil
+}
+
+// DeleteSessions deletes every session of a user, or only those logged in
+// with credentialID when it is not empty.
+func (m *MemoryStore) DeleteSessions(ctx context.Context, userID uint, credentialID string) error {
+	return m.deleteSessions(ctx, func(s *models.Session) bool {
+		return s.UserID == userID && (credentialID == "" || s.CredentialID == credentialID)
+	})
+}
+
+// DeleteExpiredSessions deletes the sessions past either timeout at now.
+func (m *MemoryStore) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	return m.deleteSessions(ctx, func(s *models.Session) bool { return !s.Active(now) })
+}
+
+func (m *MemoryStore) deleteSessions(ctx context.Context, match func(s *models.Session) bool) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	for id, s := range m.sessions {
+		if match(&s) {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// CreateRecoveryGrant stores g, assigning it an ID. Token hashes are unique,
+// as the other backends' indexes enforce.
+func (m *MemoryStore) CreateRecoveryGrant(ctx context.Context, g *models.RecoveryGrant) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	for _, o := range m.grants {
+		if o.TokenHash == g.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	g.ID = m.id()
+	g.CreatedAt = time.Now()
+	g.UpdatedAt = g.CreatedAt
+	m.grants[g.ID] = *g
+	return nil
+}
+
+func (m *MemoryStore) GetRecoveryGrant(ctx context.Context, tokenHash string) (*models.RecoveryGrant, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	for _, g := range m.grants {
+		if g.TokenHash == tokenHash {
+			return &g, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MemoryStore) DeleteRecoveryGrant(ctx context.Context, id uint) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if _, ok := m.grants[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.grants, id)
+	return nil
+}
+
+// DeleteExpiredRecoveryGrants deletes the grants past their timeout at now.
+func (m *MemoryStore) DeleteExpiredRecoveryGrants(ctx context.Context, now time.Time) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	for id, g := range m.grants {
+		if !g.Active(now) {
+			delete(m.grants, id)
+		}
+	}
+	return nil
+}
+
+// CreateChallenge stores c, assigning it an ID. ID hashes are unique, as
+// the other backends' indexes enforce.
+func (m *MemoryStore) CreateChallenge(ctx context.Context, c *models.Challenge) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	for _, o := range m.chals {
+		if o.IDHash == c.IDHash {
+			return ErrDuplicate
+		}
+	}
+	c.ID = m.id()
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+	m.chals[c.ID] = *c
+	return nil
+}
+
+func (m *MemoryStore) TakeChallenge(ctx context.Context, idHash string) (*models.Challenge, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	for id, c := range m.chals {
+		if c.IDHash == idHash {
+			delete(m.chals, id)
+			return &c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// CountChallenges counts the challenges still active at now.
+func (m *MemoryStore) CountChallenges(ctx context.Context, now time.Time) (int, error) {
+	if err := m.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer m.mu.Unlock()
+	n := 0
+	for _, c := range m.chals {
+		if c.Active(now) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteExpiredChallenges deletes the challenges past their timeout at now.
+func (m *MemoryStore) DeleteExpiredChallenges(ctx context.Context, now time.Time) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	for id, c := range m.chals {
+		if !c.Active(now) {
+			delete(m.chals, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) RecordPayment(ctx context.Context, name string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.Username == name }, func(u *models.User) { u.Paid = true })
+}
+
+func (m *MemoryStore) AttachIntent(ctx context.Context, piID string, name string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.Username == name }, func(u *models.User) { u.PiID = piID })
+}
+
+func (m *MemoryStore) SuccessfulPayment(ctx context.Context, piID string) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	return m.update(func(u *models.User) bool { return u.PiID == piID }, func(u *models.User) { u.Paid = true })
+}
+
+// Transaction runs fn against a copy of the store and swaps the copy in if
+// fn succeeds. The store stays locked meanwhile, so transactions are
+// serialised with every other call.
+func (m *MemoryStore) Transaction(ctx context.Context, fn func(ctx context.Context, tx Store) error) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	tx := &MemoryStore{
+		nextID:   m.nextID,
+		users:    make(map[uint]models.User, len(m.users)),
+		creds:    make(map[uint]models.Credential, len(m.creds)),
+		auths:    make(map[uint]models.Authenticator, len(m.auths)),
+		audit:    append([]models.AuditEvent(nil), m.audit...),
+		sessions: make(map[uint]models.Session, len(m.sessions)),
+		grants:   make(map[uint]models.RecoveryGrant, len(m.grants)),
+		chals:    make(map[uint]models.Challenge, len(m.chals)),
+	}
+	for id, u := range m.users {
+		tx.users[id] = u
+	}
+	for id, c := range m.creds {
+		tx.creds[id] = c
+	}
+	for id, a := range m.auths {
+		tx.auths[id] = a
+	}
+	for id, s := range m.sessions {
+		tx.sessions[id] = s
+	}
+	for id, g := range m.grants {
+		tx.grants[id] = g
+	}
+	for id, c := range m.chals {
+		tx.chals[id] = c
+	}
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	m.nextID, m.users, m.creds, m.auths, m.audit, m.sessions, m.grants, m.chals = tx.nextID, tx.users, tx.creds, tx.auths, tx.audit, tx.sessions, tx.grants, tx.chals
+	return nil
+}
+
+// Migrate is a no-op; the store has no schema.
+func (m *MemoryStore) Migrate(ctx context.Context) error {
+	return nil
+}
+
+// Ping always succeeds.
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Disconnect is a no-op; the data stays readable until the store is dropped.
+func (m *MemoryStore) Disconnect(ctx context.Context) error {
+	return nil
+}
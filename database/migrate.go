@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sonr-io/webauthn.io/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migration is one versioned change to the Mongo schema. Versions are
+// applied in ascending order and recorded in the migrations collection, so
+// each runs exactly once per database. Released migrations must never be
+// edited; append a new one instead.
+type migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *MongoClient) error
+}
+
+// MigrationRecord is a document of the migrations collection.
+type MigrationRecord struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// migrationTimeout bounds a single migration, which may rewrite every
+// document of a collection.
+const migrationTimeout = 5 * time.Minute
+
+var migrations = []migration{
+	{1, "default user arrays", migrateUserArrays},
+	{2, "unique user and credential keys", migrateUniqueIndexes},
+	{3, "create collections", migrateCollections},
+	{4, "audit events by user", migrateAuditIndex},
+	{5, "login sessions", migrateSessions},
+	{6, "recovery grants", migrateRecoveryGrants},
+	{7, "unique user ids", migrateUniqueUserIDs},
+	{8, "challenges", migrateChallenges},
+}
+
+// migrateUserArrays gives users written before names and credentials were
+// part of models.User empty arrays, so array updates such as $addToSet and
+// $push apply to them.
+func migrateUserArrays(ctx context.Context, db *MongoClient) error {
+	for _, field := range []string{"names", "credentials"} {
+		_, err := db.users.UpdateMany(ctx,
+			bson.M{field: bson.M{"$not": bson.M{"$type": "array"}}},
+			bson.M{"$set": bson.M{field: bson.A{}}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateUniqueIndexes enforces the keys the application assumes unique.
+// The indexes are partial: users created from a bare DID have no username
+// and most users have no payment intent, so only non-empty strings are
+// indexed. The names index is multikey, making each name unique across
+// users rather than within one user's list.
+func migrateUniqueIndexes(ctx context.Context, db *MongoClient) error {
+	unique := func(field string) mongo.IndexModel {
+		return mongo.IndexModel{
+			Keys: bson.D{{Key: field, Value: 1}},
+			Options: options.Index().
+				SetName(field + "_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{field: bson.M{"$gt": ""}}),
+		}
+	}
+	users := []mongo.IndexModel{unique("names"), unique("username"), unique("did"), unique("piid")}
+	if _, err := db.users.Indexes().CreateMany(ctx, users); err != nil {
+		return err
+	}
+	_, err := db.creds.Indexes().CreateOne(ctx, unique("credentialid"))
+	return err
+}
+
+// migrateCollections creates the collections written inside transactions.
+// Servers before 4.4 cannot create a collection within one.
+func migrateCollections(ctx context.Context, db *MongoClient) error {
+	database := db.users.Database()
+	existing, err := database.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		found[name] = true
+	}
+	for _, c := range []*mongo.Collection{db.users, db.auths, db.creds, db.counters} {
+		if found[c.Name()] {
+			continue
+		}
+		if err := database.CreateCollection(ctx, c.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAuditIndex supports listing a user's audit events, newest first.
+func migrateAuditIndex(ctx context.Context, db *MongoClient) error {
+	_, err := db.audit.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "userid", Value: 1}, {Key: "model.id", Value: -1}},
+	})
+	return err
+}
+
+// migrateSessions indexes login sessions by token hash, which is unique,
+// and by user for listing and signing out everywhere. Expired sessions are
+// also removed by a TTL index on their absolute timeout.
+func migrateSessions(ctx context.Context, db *MongoClient) error {
+	_, err := db.sessions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenhash", Value: 1}},
+			Options: options.Index().SetName("tokenhash_unique").SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "model.id", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "expires", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// migrateRecoveryGrants indexes recovery grants by token hash, which is
+// unique, and removes expired grants with a TTL index.
+func migrateRecoveryGrants(ctx context.Context, db *MongoClient) error {
+	_, err := db.recoveryGrants.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenhash", Value: 1}},
+			Options: options.Index().SetName("tokenhash_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// migrateUniqueUserIDs makes inserting a user whose ID is taken fail. IDs
+// of users registering are chosen before they are stored, so a collision
+// must not leave two users sharing credentials looked up by ID.
+func migrateUniqueUserIDs(ctx context.Context, db *MongoClient) error {
+	_, err := db.users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "model.id", Value: 1}},
+		Options: options.Index().SetName("id_unique").SetUnique(true),
+	})
+	return err
+}
+
+// migrateChallenges indexes challenges by ID hash, which is unique, and
+// removes expired challenges with a TTL index.
+func migrateChallenges(ctx context.Context, db *MongoClient) error {
+	_, err := db.challenges.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "idhash", Value: 1}},
+			Options: options.Index().SetName("idhash_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// Migrate applies every migration not yet recorded in the database. A
+// failed migration is not recorded and stops the run, so it is retried on
+// the next call once the cause, such as duplicate documents blocking a
+// unique index, has been fixed.
+func (db *MongoClient) Migrate(ctx context.Context) error {
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		log.Infof("Applying database migration %d: %s", m.Version, m.Name)
+		if err := db.runMigration(ctx, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, mongoError(err))
+		}
+	}
+	return nil
+}
+
+func (db *MongoClient) runMigration(ctx context.Context, m migration) error {
+	ctx, cancel := context.WithTimeout(ctx, migrationTimeout)
+	defer cancel()
+	if err := m.Up(ctx, db); err != nil {
+		return err
+	}
+	_, err := db.migrations.InsertOne(ctx, MigrationRecord{
+		Version:   m.Version,
+		Name:      m.Name,
+		AppliedAt: time.Now(),
+	})
+	return err
+}
+
+// MigrationStatus lists every known migration with the time it was
+// applied; pending migrations have a zero AppliedAt.
+func (db *MongoClient) MigrationStatus(ctx context.Context) ([]MigrationRecord, error) {
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationRecord, len(migrations))
+	for i, m := range migrations {
+		status[i] = MigrationRecord{Version: m.Version, Name: m.Name, AppliedAt: applied[m.Version].AppliedAt}
+	}
+	return status, nil
+}
+
+func (db *MongoClient) appliedMigrations(ctx context.Context) (map[int]MigrationRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	cur, err := db.migrations.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	var docs []MigrationRecord
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, mongoError(err)
+	}
+	applied := make(map[int]MigrationRecord, len(docs))
+	for _, d := range docs {
+		applied[d.Version] = d
+	}
+	return applied, nil
+}
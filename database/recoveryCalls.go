@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CreateRecoveryGrant stores g, assigning it an ID.
+func (db *MongoClient) CreateRecoveryGrant(ctx context.Context, g *models.RecoveryGrant) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	id, err := db.nextID(ctx, "recoverygrants")
+	if err != nil {
+		return err
+	}
+	g.ID = id
+	g.CreatedAt = time.Now()
+	g.UpdatedAt = g.CreatedAt
+	_, err = db.recoveryGrants.InsertOne(ctx, g)
+	return mongoError(err)
+}
+
+func (db *MongoClient) GetRecoveryGrant(ctx context.Context, tokenHash string) (*models.RecoveryGrant, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	g := &models.RecoveryGrant{}
+	if err := db.recoveryGrants.FindOne(ctx, bson.M{"tokenhash": tokenHash}).Decode(g); err != nil {
+		return nil, mongoError(err)
+	}
+	return g, nil
+}
+
+// DeleteRecoveryGrant deletes a grant, or returns ErrNotFound if another
+// request already deleted it.
+func (db *MongoClient) DeleteRecoveryGrant(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	res, err := db.recoveryGrants.DeleteOne(ctx, bson.M{"model.id": id})
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteExpiredRecoveryGrants deletes the grants past their timeout at now,
+// ahead of the TTL index.
+func (db *MongoClient) DeleteExpiredRecoveryGrants(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	_, err := db.recoveryGrants.DeleteMany(ctx, bson.M{"expires": bson.M{"$lte": now}})
+	return mongoError(err)
+}
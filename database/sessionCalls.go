@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateSession stores s, assigning it an ID.
+func (db *MongoClient) CreateSession(ctx context.Context, s *models.Session) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	id, err := db.nextID(ctx, "sessions")
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	_, err = db.sessions.InsertOne(ctx, s)
+	return mongoError(err)
+}
+
+func (db *MongoClient) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	s := &models.Session{}
+	if err := db.sessions.FindOne(ctx, bson.M{"tokenhash": tokenHash}).Decode(s); err != nil {
+		return nil, mongoError(err)
+	}
+	return s, nil
+}
+
+// GetSessions returns the user's sessions, newest first.
+func (db *MongoClient) GetSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	opts := options.Find().SetSort(bson.D{{Key: "model.id", Value: -1}})
+	cur, err := db.sessions.Find(ctx, bson.M{"userid": userID}, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	sessions := []models.Session{}
+	if err := cur.All(ctx, &sessions); err != nil {
+		return nil, mongoError(err)
+	}
+	return sessions, nil
+}
+
+func (db *MongoClient) TouchSession(ctx context.Context, id uint, lastSeen time.Time, idleExpires time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	res, err := db.sessions.UpdateOne(ctx, bson.M{"model.id": id}, bson.M{"$set": bson.M{
+		"lastseen":        lastSeen,
+		"idleexpires":     idleExpires,
+		"model.updatedat": time.Now(),
+	}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (db *MongoClient) DeleteSession(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	res, err := db.sessions.DeleteOne(ctx, bson.M{"model.id": id})
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSessions deletes every session of a user, or only those logged in
+// with credentialID when it is not empty.
+func (db *MongoClient) DeleteSessions(ctx context.Context, userID uint, credentialID string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	filter := bson.M{"userid": userID}
+	if credentialID != "" {
+		filter["credentialid"] = credentialID
+	}
+	_, err := db.sessions.DeleteMany(ctx, filter)
+	return mongoError(err)
+}
+
+// DeleteExpiredSessions deletes the sessions past either timeout at now. The
+// TTL index removes those past their absolute timeout on its own, but only
+// about once a minute.
+func (db *MongoClient) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	_, err := db.sessions.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"idleexpires": bson.M{"$lte": now}},
+		bson.M{"expires": bson.M{"$lte": now}},
+	}})
+	return mongoError(err)
+}
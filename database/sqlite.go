@@ -0,0 +1,651 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// DefaultSQLitePath is the database file used when SQL_PATH is not set.
+const DefaultSQLitePath = "webauthn.db"
+
+// SQLiteStore is a Store backed by a SQLite file through gorm. Users,
+// credentials, authenticators and sessions map onto the models' own tables; a user's
+// names are kept in a side table since gorm cannot store a string slice.
+//
+// gorm v1 has no context support, so ctx is only checked before each query.
+type SQLiteStore struct {
+	db *gorm.DB
+	// tx is set on the copy handed to a Transaction callback, whose db is
+	// the open transaction.
+	tx bool
+}
+
+// userName is a row of the user_names table.
+type userName struct {
+	ID     uint   `gorm:"primary_key"`
+	UserID uint   `gorm:"index"`
+	Name   string `gorm:"unique_index"`
+}
+
+// OpenSQLite opens the SQLite database at path, creating any missing
+// tables and columns.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if path == "" {
+		path = DefaultSQLitePath
+	}
+	conn, err := gorm.Open("sqlite3", path)
+	if err != nil {
+		return nil, sqlError(err)
+	}
+	// Associations are written explicitly; a credential must never update
+	// its user as a side effect.
+	s := &SQLiteStore{db: conn.Set("gorm:save_associations", false)}
+	if err := s.Migrate(context.Background()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// uniqueIndexes mirror the Mongo unique keys. Like them they skip empty
+// values, which users created from a bare DID or without a payment intent
+// share.
+var uniqueIndexes = []string{
+	"CREATE UNIQUE INDEX IF NOT EXISTS users_username_unique ON users(username) WHERE username <> ''",
+	"CREATE UNIQUE INDEX IF NOT EXISTS users_did_unique ON users(did) WHERE did <> ''",
+	"CREATE UNIQUE INDEX IF NOT EXISTS users_pi_id_unique ON users(pi_id) WHERE pi_id <> ''",
+	"CREATE UNIQUE INDEX IF NOT EXISTS credentials_credential_id_unique ON credentials(credential_id) WHERE credential_id <> ''",
+}
+
+// Migrate creates any missing tables, columns and indexes. OpenSQLite
+// already calls it; the file is local, so there is nothing to gain from
+// deferring it.
+func (s *SQLiteStore) Migrate(ctx context.Context) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Credential{}, &models.Authenticator{}, &models.AuditEvent{}, &models.Session{}, &models.RecoveryGrant{}, &models.Challenge{}, &userName{}).Error; err != nil {
+		return sqlError(err)
+	}
+	for _, stmt := range uniqueIndexes {
+		if err := db.Exec(stmt).Error; err != nil {
+			return sqlError(err)
+		}
+	}
+	return nil
+}
+
+// conn returns the database handle unless ctx is already done.
+func (s *SQLiteStore) conn(ctx context.Context) (*gorm.DB, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.db, nil
+}
+
+// user loads the first user matching the query with its names and
+// credentials.
+func (s *SQLiteStore) user(ctx context.Context, query interface{}, args ...interface{}) (*models.User, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u := &models.User{}
+	if err := db.Preload("Credentials.Authenticator").Where(query, args...).Order("id").First(u).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	var names []userName
+	if err := db.Where("user_id = ?", u.ID).Order("id").Find(&names).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	for _, n := range names {
+		u.Names = append(u.Names, n.Name)
+	}
+	if u.Credentials == nil {
+		u.Credentials = []models.Credential{}
+	}
+	return u, nil
+}
+
+// addNames records the names of u not yet stored.
+func (s *SQLiteStore) addNames(db *gorm.DB, u *models.User) error {
+	for _, name := range u.Names {
+		if err := db.FirstOrCreate(&userName{}, userName{UserID: u.ID, Name: name}).Error; err != nil {
+			return sqlError(err)
+		}
+	}
+	return nil
+}
+
+// transaction runs fn in a transaction on db, joining the open one if s is
+// already inside Transaction. gorm cannot nest them.
+func (s *SQLiteStore) transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if s.tx {
+		return fn(db)
+	}
+	return db.Transaction(fn)
+}
+
+// Transaction runs fn in a SQL transaction.
+func (s *SQLiteStore) Transaction(ctx context.Context, fn func(ctx context.Context, tx Store) error) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(s.transaction(db, func(tx *gorm.DB) error {
+		return fn(ctx, &SQLiteStore{db: tx, tx: true})
+	}))
+}
+
+// updateUsers applies updates to every user matching query.
+func (s *SQLiteStore) updateUsers(ctx context.Context, updates map[string]interface{}, query interface{}, args ...interface{}) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Model(&models.User{}).Where(query, args...).Updates(updates)
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) NewUser(ctx context.Context, user *models.User) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	user.Credentials = []models.Credential{}
+	user.Created = time.Now()
+	return sqlError(s.transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return s.addNames(tx, user)
+	}))
+}
+
+func (s *SQLiteStore) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	return s.user(ctx, "id = ?", id)
+}
+
+func (s *SQLiteStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.user(ctx, "username = ?", username)
+}
+
+// PutUser creates u, or replaces the stored user with the same ID.
+func (s *SQLiteStore) PutUser(ctx context.Context, u *models.User) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(s.transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Save(u).Error; err != nil {
+			return err
+		}
+		return s.addNames(tx, u)
+	}))
+}
+
+// UpdateProfile sets the public display fields of the user owning did.
+func (s *SQLiteStore) UpdateProfile(ctx context.Context, did string, displayName string, icon string) error {
+	return s.updateUsers(ctx, map[string]interface{}{"display_name": displayName, "icon": icon}, "did = ?", did)
+}
+
+// SetRecoveryCodes replaces the recovery code hashes of a user.
+func (s *SQLiteStore) SetRecoveryCodes(ctx context.Context, userID uint, hashes models.RecoveryCodes) error {
+	return s.updateUsers(ctx, map[string]interface{}{"recovery_codes": hashes}, "id = ?", userID)
+}
+
+// UseRecoveryCode removes hash from the user's recovery codes. The update is
+// conditional on the codes read, so a concurrent use of the same code finds
+// it gone.
+func (s *SQLiteStore) UseRecoveryCode(ctx context.Context, userID uint, hash string) error {
+	u, err := s.user(ctx, "id = ?", userID)
+	if err != nil {
+		return err
+	}
+	rest := u.RecoveryCodes.Without(hash)
+	if len(rest) == len(u.RecoveryCodes) {
+		return ErrNotFound
+	}
+	return s.updateUsers(ctx, map[string]interface{}{"recovery_codes": rest}, "id = ? AND recovery_codes = ?", userID, u.RecoveryCodes)
+}
+
+func (s *SQLiteStore) FindDid(ctx context.Context, did string) (*models.User, error) {
+	return s.user(ctx, "did = ?", did)
+}
+
+func (s *SQLiteStore) AddDid(ctx context.Context, did string, jwt models.Jwt) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Create(&models.User{Did: did, Jwt: jwt}).Error)
+}
+
+func (s *SQLiteStore) AttachDid(ctx context.Context, userID uint, placeHolderDid string, newDid string) error {
+	return s.updateUsers(ctx, map[string]interface{}{"did": newDid}, "id = ? AND did = ?", userID, placeHolderDid)
+}
+
+// CheckName reports whether name is available.
+func (s *SQLiteStore) CheckName(ctx context.Context, name string) (bool, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	if err := db.Model(&userName{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, sqlError(err)
+	}
+	return count == 0, nil
+}
+
+// StoreRecord adds nameToRecord to the names of the user owning did.
+func (s *SQLiteStore) StoreRecord(ctx context.Context, nameToRecord string, did string) error {
+	u, err := s.FindDid(ctx, did)
+	if err != nil {
+		return err
+	}
+	u.Names = []string{nameToRecord}
+	return s.addNames(s.db, u)
+}
+
+func (s *SQLiteStore) FindUserByName(ctx context.Context, name string) (*models.User, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var n userName
+	if err := db.Where("name = ?", name).First(&n).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return s.GetUser(ctx, n.UserID)
+}
+
+// CreateCredential creates a new credential object
+func (s *SQLiteStore) CreateCredential(ctx context.Context, c *models.Credential) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Create(c).Error)
+}
+
+// UpdateCredential updates the credential with new attributes.
+func (s *SQLiteStore) UpdateCredential(ctx context.Context, c *models.Credential) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Model(c).Updates(c)
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetCredentialsForUser retrieves all credentials for a provided user regardless of relying party.
+func (s *SQLiteStore) GetCredentialsForUser(ctx context.Context, user *models.User) ([]models.Credential, error) {
+	u, err := s.GetUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return u.Credentials, nil
+}
+
+// GetCredentialForUser retrieves a specific credential for a user, reloading
+// the user in place.
+func (s *SQLiteStore) GetCredentialForUser(ctx context.Context, user *models.User, credentialID string) (models.Credential, error) {
+	stored, err := s.GetUser(ctx, user.ID)
+	if err != nil {
+		return models.Credential{}, err
+	}
+	*user = *stored
+	for _, c := range user.Credentials {
+		if c.CredentialID == credentialID {
+			return c, nil
+		}
+	}
+	return models.Credential{}, ErrNotFound
+}
+
+// DeleteCredentialByID deletes the credential with the given credential ID.
+func (s *SQLiteStore) DeleteCredentialByID(ctx context.Context, credentialID string) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Unscoped().Where("credential_id = ?", credentialID).Delete(&models.Credential{})
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DisableCredential stops the credential from being used to log in.
+func (s *SQLiteStore) DisableCredential(ctx context.Context, credentialID string) error {
+	return s.updateCredential(ctx, credentialID, "disabled", true)
+}
+
+// RenameCredential sets the credential's nickname.
+func (s *SQLiteStore) RenameCredential(ctx context.Context, credentialID string, nickname string) error {
+	return s.updateCredential(ctx, credentialID, "nickname", nickname)
+}
+
+// updateCredential sets a column of the credential with the given ID.
+func (s *SQLiteStore) updateCredential(ctx context.Context, credentialID string, column string, value interface{}) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Model(&models.Credential{}).Where("credential_id = ?", credentialID).Update(column, value)
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GiveUserCred attaches cred to the user with the given username.
+func (s *SQLiteStore) GiveUserCred(ctx context.Context, username string, cred *models.Credential) error {
+	u, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	cred.UserID = u.ID
+	return sqlError(s.db.Save(cred).Error)
+}
+
+// GetAuthenticator returns the authenticator the given id corresponds to.
+func (s *SQLiteStore) GetAuthenticator(ctx context.Context, id uint) (*models.Authenticator, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auth := &models.Authenticator{}
+	if err := db.First(auth, id).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return auth, nil
+}
+
+// CreateAuthenticator creates a new authenticator that's tied to a Credential.
+func (s *SQLiteStore) CreateAuthenticator(ctx context.Context, a webauthn.Authenticator, attestationType string) (*models.Authenticator, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auth := &models.Authenticator{Authenticator: a, AttestationType: attestationType}
+	if err := db.Create(auth).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return auth, nil
+}
+
+// UpdateSignCount stores the sign count the credential's authenticator
+// reported. The comparison is part of the UPDATE, so concurrent logins
+// cannot both move the count.
+func (s *SQLiteStore) UpdateSignCount(ctx context.Context, credentialID string, count uint32) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	var c models.Credential
+	if err := db.Where("credential_id = ?", credentialID).First(&c).Error; err != nil {
+		return sqlError(err)
+	}
+	q := db.Model(&models.Authenticator{}).Where("id = ?", c.AuthenticatorID)
+	if count == 0 {
+		q = q.Where("sign_count = 0")
+	} else {
+		q = q.Where("sign_count < ?", count)
+	}
+	res := q.Update("sign_count", count)
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrStale
+	}
+	return nil
+}
+
+// AddAuditEvent records e, assigning it an ID.
+func (s *SQLiteStore) AddAuditEvent(ctx context.Context, e *models.AuditEvent) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Create(e).Error)
+}
+
+// GetAuditEvents returns the user's audit events, newest first.
+func (s *SQLiteStore) GetAuditEvents(ctx context.Context, userID uint) ([]models.AuditEvent, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := []models.AuditEvent{}
+	if err := db.Where("user_id = ?", userID).Order("id desc").Find(&events).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return events, nil
+}
+
+func (s *SQLiteStore) CreateSession(ctx context.Context, session *models.Session) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Create(session).Error)
+}
+
+func (s *SQLiteStore) GetSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{}
+	if err := db.Where("token_hash = ?", tokenHash).First(session).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return session, nil
+}
+
+// GetSessions returns the user's sessions, newest first.
+func (s *SQLiteStore) GetSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions := []models.Session{}
+	if err := db.Where("user_id = ?", userID).Order("id desc").Find(&sessions).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return sessions, nil
+}
+
+func (s *SQLiteStore) TouchSession(ctx context.Context, id uint, lastSeen time.Time, idleExpires time.Time) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Model(&models.Session{}).Where("id = ?", id).Updates(map[string]interface{}{"last_seen": lastSeen, "idle_expires": idleExpires})
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteSession(ctx context.Context, id uint) error {
+	return s.deleteSessions(ctx, true, "id = ?", id)
+}
+
+// DeleteSessions deletes every session of a user, or only those logged in
+// with credentialID when it is not empty.
+func (s *SQLiteStore) DeleteSessions(ctx context.Context, userID uint, credentialID string) error {
+	if credentialID == "" {
+		return s.deleteSessions(ctx, false, "user_id = ?", userID)
+	}
+	return s.deleteSessions(ctx, false, "user_id = ? AND credential_id = ?", userID, credentialID)
+}
+
+// DeleteExpiredSessions deletes the sessions past either timeout at now.
+func (s *SQLiteStore) DeleteExpiredSessions(ctx context.Context, now time.Time) error {
+	return s.deleteSessions(ctx, false, "idle_expires <= ? OR expires <= ?", now, now)
+}
+
+// deleteSessions deletes the sessions matching query. With must set, finding
+// none is ErrNotFound.
+func (s *SQLiteStore) deleteSessions(ctx context.Context, must bool, query interface{}, args ...interface{}) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Unscoped().Where(query, args...).Delete(&models.Session{})
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if must && res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateRecoveryGrant(ctx context.Context, g *models.RecoveryGrant) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Create(g).Error)
+}
+
+func (s *SQLiteStore) GetRecoveryGrant(ctx context.Context, tokenHash string) (*models.RecoveryGrant, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g := &models.RecoveryGrant{}
+	if err := db.Where("token_hash = ?", tokenHash).First(g).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	return g, nil
+}
+
+// DeleteRecoveryGrant deletes a grant, or returns ErrNotFound if another
+// request already deleted it.
+func (s *SQLiteStore) DeleteRecoveryGrant(ctx context.Context, id uint) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	res := db.Unscoped().Where("id = ?", id).Delete(&models.RecoveryGrant{})
+	if res.Error != nil {
+		return sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteExpiredRecoveryGrants deletes the grants past their timeout at now.
+func (s *SQLiteStore) DeleteExpiredRecoveryGrants(ctx context.Context, now time.Time) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Unscoped().Where("expires <= ?", now).Delete(&models.RecoveryGrant{}).Error)
+}
+
+func (s *SQLiteStore) CreateChallenge(ctx context.Context, c *models.Challenge) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Create(c).Error)
+}
+
+// TakeChallenge deletes and returns a challenge. Only the request whose
+// delete removes the row gets it, so concurrent requests cannot both use it.
+func (s *SQLiteStore) TakeChallenge(ctx context.Context, idHash string) (*models.Challenge, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &models.Challenge{}
+	if err := db.Where("id_hash = ?", idHash).First(c).Error; err != nil {
+		return nil, sqlError(err)
+	}
+	res := db.Unscoped().Where("id = ?", c.ID).Delete(&models.Challenge{})
+	if res.Error != nil {
+		return nil, sqlError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	return c, nil
+}
+
+// CountChallenges counts the challenges still active at now.
+func (s *SQLiteStore) CountChallenges(ctx context.Context, now time.Time) (int, error) {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	err = db.Model(&models.Challenge{}).Where("expires > ?", now).Count(&n).Error
+	return n, sqlError(err)
+}
+
+// DeleteExpiredChallenges deletes the challenges past their timeout at now.
+func (s *SQLiteStore) DeleteExpiredChallenges(ctx context.Context, now time.Time) error {
+	db, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return sqlError(db.Unscoped().Where("expires <= ?", now).Delete(&models.Challenge{}).Error)
+}
+
+func (s *SQLiteStore) RecordPayment(ctx context.Context, name string) error {
+	return s.updateUsers(ctx, map[string]interface{}{"paid": true}, "username = ?", name)
+}
+
+func (s *SQLiteStore) AttachIntent(ctx context.Context, piID string, name string) error {
+	return s.updateUsers(ctx, map[string]interface{}{"pi_id": piID}, "username = ?", name)
+}
+
+func (s *SQLiteStore) SuccessfulPayment(ctx context.Context, piID string) error {
+	return s.updateUsers(ctx, map[string]interface{}{"paid": true}, "pi_id = ?", piID)
+}
+
+// Ping checks the database file is still usable.
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return sqlError(s.db.DB().PingContext(ctx))
+}
+
+// Disconnect closes the database.
+func (s *SQLiteStore) Disconnect(ctx context.Context) error {
+	return s.db.Close()
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// Backends a Store can be opened on.
+const (
+	BackendMongo  = "mongo"
+	BackendSQLite = "sqlite"
+	BackendMemory = "memory"
+)
+
+// Store persists users, their names, credentials, authenticators, login
+// sessions, open challenges and payments. MongoClient is the production
+// implementation; SQLiteStore and MemoryStore let local development and
+// tests run without a Mongo server.
+//
+// Every method honours ctx and reports failures as ErrNotFound, ErrDuplicate,
+// ErrUnavailable or ErrStale where the cause is known.
+type Store interface {
+	// Users
+	NewUser(ctx context.Context, user *models.User) error
+	GetUser(ctx context.Context, id uint) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	PutUser(ctx context.Context, u *models.User) error
+	UpdateProfile(ctx context.Context, did string, displayName string, icon string) error
+	// SetRecoveryCodes replaces the recovery code hashes of a user.
+	SetRecoveryCodes(ctx context.Context, userID uint, hashes models.RecoveryCodes) error
+	// UseRecoveryCode removes hash from the user's recovery codes. The check
+	// and the removal are atomic, so a code works once; ErrNotFound is
+	// returned when the user has no such code.
+	UseRecoveryCode(ctx context.Context, userID uint, hash string) error
+
+	// DIDs
+	FindDid(ctx context.Context, did string) (*models.User, error)
+	AddDid(ctx context.Context, did string, jwt models.Jwt) error
+	// AttachDid replaces the placeholder DID of the user userID.
+	AttachDid(ctx context.Context, userID uint, placeHolderDid string, newDid string) error
+
+	// Names
+	CheckName(ctx context.Context, name string) (bool, error)
+	StoreRecord(ctx context.Context, nameToRecord string, did string) error
+	FindUserByName(ctx context.Context, name string) (*models.User, error)
+
+	// Credentials
+	CreateCredential(ctx context.Context, c *models.Credential) error
+	UpdateCredential(ctx context.Context, c *models.Credential) error
+	GetCredentialsForUser(ctx context.Context, user *models.User) ([]models.Credential, error)
+	GetCredentialForUser(ctx context.Context, user *models.User, credentialID string) (models.Credential, error)
+	DeleteCredentialByID(ctx context.Context, credentialID string) error
+	DisableCredential(ctx context.Context, credentialID string) error
+	RenameCredential(ctx context.Context, credentialID string, nickname string) error
+	GiveUserCred(ctx context.Context, username string, cred *models.Credential) error
+
+	// Authenticators
+	GetAuthenticator(ctx context.Context, id uint) (*models.Authenticator, error)
+	CreateAuthenticator(ctx context.Context, a webauthn.Authenticator, attestationType string) (*models.Authenticator, error)
+	// UpdateSignCount stores the sign count the credential's authenticator
+	// reported, provided it advances on the stored one. The check and the
+	// write are atomic; ErrStale is returned when the count did not advance.
+	UpdateSignCount(ctx context.Context, credentialID string, count uint32) error
+
+	// Audit
+	AddAuditEvent(ctx context.Context, e *models.AuditEvent) error
+	GetAuditEvents(ctx context.Context, userID uint) ([]models.AuditEvent, error)
+
+	// Sessions
+	CreateSession(ctx context.Context, s *models.Session) error
+	// GetSession returns the session whose token hashes to tokenHash.
+	GetSession(ctx context.Context, tokenHash string) (*models.Session, error)
+	// GetSessions returns the user's sessions, newest first.
+	GetSessions(ctx context.Context, userID uint) ([]models.Session, error)
+	// TouchSession records that a session was used at lastSeen and moves
+	// its idle timeout to idleExpires.
+	TouchSession(ctx context.Context, id uint, lastSeen time.Time, idleExpires time.Time) error
+	DeleteSession(ctx context.Context, id uint) error
+	// DeleteSessions deletes every session of a user, or only those logged
+	// in with credentialID when it is not empty.
+	DeleteSessions(ctx context.Context, userID uint, credentialID string) error
+	// DeleteExpiredSessions deletes the sessions past either timeout at now.
+	DeleteExpiredSessions(ctx context.Context, now time.Time) error
+
+	// Recovery grants
+	CreateRecoveryGrant(ctx context.Context, g *models.RecoveryGrant) error
+	// GetRecoveryGrant returns the grant whose token hashes to tokenHash.
+	GetRecoveryGrant(ctx context.Context, tokenHash string) (*models.RecoveryGrant, error)
+	// DeleteRecoveryGrant deletes a grant, or returns ErrNotFound if it is
+	// already gone, so a grant can only be used once.
+	DeleteRecoveryGrant(ctx context.Context, id uint) error
+	// DeleteExpiredRecoveryGrants deletes the grants past their timeout at
+	// now.
+	DeleteExpiredRecoveryGrants(ctx context.Context, now time.Time) error
+
+	// Challenges
+	CreateChallenge(ctx context.Context, c *models.Challenge) error
+	// TakeChallenge deletes and returns the challenge whose ID hashes to
+	// idHash, or returns ErrNotFound if it is already gone, so a challenge
+	// can only be used once.
+	TakeChallenge(ctx context.Context, idHash string) (*models.Challenge, error)
+	// CountChallenges counts the challenges still active at now.
+	CountChallenges(ctx context.Context, now time.Time) (int, error)
+	// DeleteExpiredChallenges deletes the challenges past their timeout at
+	// now.
+	DeleteExpiredChallenges(ctx context.Context, now time.Time) error
+
+	// Payments
+	RecordPayment(ctx context.Context, name string) error
+	AttachIntent(ctx context.Context, piID string, name string) error
+	SuccessfulPayment(ctx context.Context, piID string) error
+
+	// Transaction runs fn atomically: either every write fn makes through
+	// tx is committed or none is. fn must use tx and the ctx it is given,
+	// and may be retried on transient conflicts.
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx Store) error) error
+
+	// Migrate brings the backend's schema up to date. It is safe to call
+	// on every start.
+	Migrate(ctx context.Context) error
+	// Ping reports whether the backend is reachable.
+	Ping(ctx context.Context) error
+	// Disconnect releases the backend's connections.
+	Disconnect(ctx context.Context) error
+}
+
+var (
+	_ Store = (*MongoClient)(nil)
+	_ Store = (*SQLiteStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
+
+// signCountAdvances reports whether an authenticator reporting count after
+// having reported stored is consistent with a single device. Authenticators
+// without a counter always report zero.
+func signCountAdvances(stored, count uint32) bool {
+	return count > stored || count == 0 && stored == 0
+}
+
+// Open connects to the backend selected by cnfg.DbBackend. Mongo is used
+// when none is set.
+func Open(cnfg *config.SonrConfig) (Store, error) {
+	switch cnfg.DbBackend {
+	case "", BackendMongo:
+		client, err := Connect(cnfg.MongoUri, cnfg.MongoCollectionName, cnfg.MongoDbName)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case BackendSQLite:
+		store, err := OpenSQLite(cnfg.SqlPath)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown database backend %q", cnfg.DbBackend)
+	}
+}
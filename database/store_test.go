@@ -0,0 +1,632 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ctx = context.Background()
+
+// stores returns every Store that runs without an external server.
+func stores(t *testing.T) map[string]Store {
+	sqlite, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Disconnect(ctx) })
+	return map[string]Store{
+		BackendMemory: NewMemoryStore(),
+		BackendSQLite: sqlite,
+	}
+}
+
+func TestUsers(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			u := &models.User{Username: "alice@sonr.io", DisplayName: "alice"}
+			if err := s.PutUser(ctx, u); err != nil {
+				t.Fatal(err)
+			}
+			if u.ID == 0 {
+				t.Fatal("PutUser did not assign an ID")
+			}
+			if got, err := s.GetUser(ctx, u.ID); err != nil || got.Username != "alice@sonr.io" {
+				t.Fatalf("GetUser = %+v, %v", got, err)
+			}
+			if _, err := s.GetUserByUsername(ctx, "bob@sonr.io"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("unknown user: %v", err)
+			}
+
+			placeholder := &models.User{Username: "placeholder", Did: "did:sonr:temp"}
+			if err := s.NewUser(ctx, placeholder); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.AttachDid(ctx, u.ID, "did:sonr:temp", "did:sonr:mallory"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("AttachDid of another user's placeholder: %v", err)
+			}
+			if err := s.AttachDid(ctx, placeholder.ID, "did:sonr:temp", "did:sonr:alice"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.UpdateProfile(ctx, "did:sonr:alice", "Alice", "icon.png"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.FindDid(ctx, "did:sonr:alice")
+			if err != nil || got.Username != "placeholder" || got.DisplayName != "Alice" || got.Icon != "icon.png" {
+				t.Fatalf("FindDid = %+v, %v", got, err)
+			}
+			if err := s.AttachDid(ctx, placeholder.ID, "did:sonr:temp", "did:sonr:bob"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("AttachDid on a missing DID: %v", err)
+			}
+
+			if err := s.AddDid(ctx, "did:sonr:bob", models.Jwt{Snr: "bob"}); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := s.FindDid(ctx, "did:sonr:bob"); err != nil || got.Jwt.Snr != "bob" {
+				t.Fatalf("AddDid stored %+v, %v", got, err)
+			}
+
+			taken := &models.User{Username: "carol@sonr.io"}
+			taken.ID = u.ID
+			if err := s.NewUser(ctx, taken); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("NewUser with a taken ID: %v", err)
+			}
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.StoreRecord(ctx, "alice", "did:sonr:alice"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("name recorded for an unknown DID: %v", err)
+			}
+			for _, did := range []string{"did:sonr:alice", "did:sonr:bob"} {
+				if err := s.AddDid(ctx, did, models.Jwt{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 0; i < 2; i++ {
+				if err := s.StoreRecord(ctx, "alice", "did:sonr:alice"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.StoreRecord(ctx, "alice", "did:sonr:bob"); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("name recorded twice: %v", err)
+			}
+			if ok, err := s.CheckName(ctx, "alice"); err != nil || ok {
+				t.Fatalf("CheckName(alice) = %v, %v", ok, err)
+			}
+			if ok, err := s.CheckName(ctx, "bob"); err != nil || !ok {
+				t.Fatalf("CheckName(bob) = %v, %v", ok, err)
+			}
+			got, err := s.FindUserByName(ctx, "alice")
+			if err != nil || got.Did != "did:sonr:alice" || len(got.Names) != 1 {
+				t.Fatalf("FindUserByName = %+v, %v", got, err)
+			}
+			if _, err := s.FindUserByName(ctx, "bob"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("FindUserByName(bob): %v", err)
+			}
+		})
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			u := &models.User{Username: "alice@sonr.io"}
+			if err := s.PutUser(ctx, u); err != nil {
+				t.Fatal(err)
+			}
+
+			auth, err := s.CreateAuthenticator(ctx, webauthn.Authenticator{AAGUID: []byte("aaguid"), SignCount: 1}, "basic_full")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &models.Credential{
+				CredentialID:    "cred-1",
+				UserID:          u.ID,
+				Authenticator:   *auth,
+				AuthenticatorID: auth.ID,
+				PublicKey:       []byte("key"),
+			}
+			if err := s.CreateCredential(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.GiveUserCred(ctx, u.Username, c); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.GiveUserCred(ctx, "bob@sonr.io", c); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("GiveUserCred to a missing user: %v", err)
+			}
+			creds, err := s.GetCredentialsForUser(ctx, u)
+			if err != nil || len(creds) != 1 {
+				t.Fatalf("GetCredentialsForUser = %v, %v", creds, err)
+			}
+			if got, err := s.GetUser(ctx, u.ID); err != nil || len(got.WebAuthnCredentials()) != 1 {
+				t.Fatalf("user has %d credentials, %v", len(got.WebAuthnCredentials()), err)
+			}
+
+			if err := s.UpdateSignCount(ctx, "cred-1", 7); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.UpdateSignCount(ctx, "cred-2", 7); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("UpdateSignCount on a missing credential: %v", err)
+			}
+			lookup := &models.User{}
+			lookup.ID = u.ID
+			cred, err := s.GetCredentialForUser(ctx, lookup, "cred-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cred.Authenticator.SignCount != 7 || cred.Authenticator.AttestationType != "basic_full" || lookup.Username != u.Username {
+				t.Fatalf("GetCredentialForUser = %+v for %+v", cred, lookup)
+			}
+
+			if err := s.RenameCredential(ctx, "cred-1", "laptop"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.RenameCredential(ctx, "cred-2", "phone"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("RenameCredential on a missing credential: %v", err)
+			}
+			if cred, err := s.GetCredentialForUser(ctx, lookup, "cred-1"); err != nil || cred.Nickname != "laptop" {
+				t.Fatalf("renamed credential = %+v, %v", cred, err)
+			}
+
+			if err := s.DeleteCredentialByID(ctx, "cred-1"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetCredentialForUser(ctx, u, "cred-1"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("deleted credential: %v", err)
+			}
+			if err := s.DeleteCredentialByID(ctx, "cred-1"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("deleted twice: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignCount(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			u := &models.User{Username: "alice@sonr.io"}
+			if err := s.PutUser(ctx, u); err != nil {
+				t.Fatal(err)
+			}
+			for i, start := range []uint32{5, 0} {
+				auth, err := s.CreateAuthenticator(ctx, webauthn.Authenticator{SignCount: start}, "none")
+				if err != nil {
+					t.Fatal(err)
+				}
+				c := &models.Credential{CredentialID: fmt.Sprintf("cred-%d", i), UserID: u.ID, Authenticator: *auth, AuthenticatorID: auth.ID}
+				if err := s.CreateCredential(ctx, c); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.GiveUserCred(ctx, u.Username, c); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, step := range []struct {
+				count uint32
+				want  error
+			}{{6, nil}, {6, ErrStale}, {3, ErrStale}, {0, ErrStale}, {9, nil}} {
+				if err := s.UpdateSignCount(ctx, "cred-0", step.count); !errors.Is(err, step.want) {
+					t.Errorf("UpdateSignCount(%d) = %v, want %v", step.count, err, step.want)
+				}
+			}
+			cred, err := s.GetCredentialForUser(ctx, u, "cred-0")
+			if err != nil || cred.Authenticator.SignCount != 9 {
+				t.Fatalf("stored sign count = %d, %v", cred.Authenticator.SignCount, err)
+			}
+			// Authenticators without a counter keep reporting zero.
+			for i := 0; i < 2; i++ {
+				if err := s.UpdateSignCount(ctx, "cred-1", 0); err != nil {
+					t.Errorf("zero sign count rejected: %v", err)
+				}
+			}
+
+			if err := s.DisableCredential(ctx, "cred-0"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DisableCredential(ctx, "cred-9"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("DisableCredential on a missing credential: %v", err)
+			}
+			got, err := s.GetUser(ctx, u.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds := got.WebAuthnCredentials(); len(creds) != 1 {
+				t.Errorf("disabled credential still offered: %d credentials", len(creds))
+			}
+		})
+	}
+}
+
+func TestAuditEvents(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i, userID := range []uint{1, 2, 1} {
+				e := &models.AuditEvent{UserID: userID, Kind: models.AuditSignCountRegression, CredentialID: fmt.Sprint(i)}
+				if err := s.AddAuditEvent(ctx, e); err != nil || e.ID == 0 {
+					t.Fatalf("AddAuditEvent = %v, ID %d", err, e.ID)
+				}
+			}
+			events, err := s.GetAuditEvents(ctx, 1)
+			if err != nil || len(events) != 2 || events[0].CredentialID != "2" {
+				t.Fatalf("GetAuditEvents = %+v, %v", events, err)
+			}
+			if events, err := s.GetAuditEvents(ctx, 3); err != nil || len(events) != 0 {
+				t.Fatalf("GetAuditEvents for a user without events = %+v, %v", events, err)
+			}
+		})
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			u := &models.User{Username: "alice@sonr.io"}
+			if err := s.PutUser(ctx, u); err != nil {
+				t.Fatal(err)
+			}
+			codes, hashes, err := models.NewRecoveryCodes(3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SetRecoveryCodes(ctx, u.ID, hashes); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := s.GetUser(ctx, u.ID); err != nil || len(got.RecoveryCodes) != 3 || !got.HasRecovery() {
+				t.Fatalf("stored codes %v, %v", got.RecoveryCodes, err)
+			}
+
+			used := models.HashRecoveryCode(strings.ToUpper(codes[1]))
+			if err := s.UseRecoveryCode(ctx, u.ID, used); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.UseRecoveryCode(ctx, u.ID, used); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("code used twice: %v", err)
+			}
+			if err := s.UseRecoveryCode(ctx, u.ID+1, hashes[0]); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("code used for another user: %v", err)
+			}
+			got, err := s.GetUser(ctx, u.ID)
+			if err != nil || len(got.RecoveryCodes) != 2 || got.RecoveryCodes[0] != hashes[0] || got.RecoveryCodes[1] != hashes[2] {
+				t.Fatalf("codes left %v, %v", got.RecoveryCodes, err)
+			}
+
+			if err := s.SetRecoveryCodes(ctx, u.ID, models.RecoveryCodes{}); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := s.GetUser(ctx, u.ID); got.HasRecovery() {
+				t.Fatalf("codes left after clearing: %v", got.RecoveryCodes)
+			}
+		})
+	}
+}
+
+func TestSessions(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Round(time.Second)
+			open := func(userID uint, credentialID string, expires time.Time) *models.Session {
+				_, hash, err := models.NewSessionToken()
+				if err != nil {
+					t.Fatal(err)
+				}
+				session := &models.Session{TokenHash: hash, UserID: userID, CredentialID: credentialID, IdleExpires: expires, Expires: expires}
+				if err := s.CreateSession(ctx, session); err != nil || session.ID == 0 {
+					t.Fatalf("CreateSession = %v, ID %d", err, session.ID)
+				}
+				return session
+			}
+			first := open(1, "cred-a", now.Add(time.Hour))
+			second := open(1, "cred-b", now.Add(time.Hour))
+			other := open(2, "cred-c", now.Add(time.Hour))
+			expired := open(2, "cred-c", now.Add(-time.Minute))
+
+			if err := s.CreateSession(ctx, &models.Session{TokenHash: first.TokenHash, UserID: 3}); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("duplicate token hash: %v", err)
+			}
+			got, err := s.GetSession(ctx, second.TokenHash)
+			if err != nil || got.ID != second.ID || got.CredentialID != "cred-b" {
+				t.Fatalf("GetSession = %+v, %v", got, err)
+			}
+			if _, err := s.GetSession(ctx, "unknown"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("unknown token: %v", err)
+			}
+			if list, err := s.GetSessions(ctx, 1); err != nil || len(list) != 2 || list[0].ID != second.ID {
+				t.Fatalf("GetSessions = %+v, %v", list, err)
+			}
+
+			if err := s.TouchSession(ctx, first.ID, now, now.Add(2*time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := s.GetSession(ctx, first.TokenHash); !got.LastSeen.Equal(now) || !got.IdleExpires.Equal(now.Add(2*time.Hour)) {
+				t.Fatalf("touched session %+v", got)
+			}
+
+			if err := s.DeleteExpiredSessions(ctx, now); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetSession(ctx, expired.TokenHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expired session kept: %v", err)
+			}
+			if err := s.DeleteSessions(ctx, 1, "cred-a"); err != nil {
+				t.Fatal(err)
+			}
+			if list, _ := s.GetSessions(ctx, 1); len(list) != 1 || list[0].ID != second.ID {
+				t.Fatalf("sessions left after revoking a credential's: %+v", list)
+			}
+			if err := s.DeleteSessions(ctx, 1, ""); err != nil {
+				t.Fatal(err)
+			}
+			if list, _ := s.GetSessions(ctx, 1); len(list) != 0 {
+				t.Fatalf("sessions left after signing out everywhere: %+v", list)
+			}
+			if err := s.DeleteSession(ctx, other.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DeleteSession(ctx, other.ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("session deleted twice: %v", err)
+			}
+		})
+	}
+}
+
+func TestRecoveryGrants(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Round(time.Second)
+			open := func(expires time.Time) *models.RecoveryGrant {
+				_, hash, err := models.NewSessionToken()
+				if err != nil {
+					t.Fatal(err)
+				}
+				grant := &models.RecoveryGrant{TokenHash: hash, UserID: 1, Expires: expires}
+				if err := s.CreateRecoveryGrant(ctx, grant); err != nil || grant.ID == 0 {
+					t.Fatalf("CreateRecoveryGrant = %v, ID %d", err, grant.ID)
+				}
+				return grant
+			}
+			grant := open(now.Add(time.Hour))
+			expired := open(now.Add(-time.Minute))
+
+			if err := s.CreateRecoveryGrant(ctx, &models.RecoveryGrant{TokenHash: grant.TokenHash, UserID: 2}); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("duplicate token hash: %v", err)
+			}
+			got, err := s.GetRecoveryGrant(ctx, grant.TokenHash)
+			if err != nil || got.ID != grant.ID || got.UserID != 1 || !got.Expires.Equal(grant.Expires) {
+				t.Fatalf("GetRecoveryGrant = %+v, %v", got, err)
+			}
+			if err := s.DeleteExpiredRecoveryGrants(ctx, now); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetRecoveryGrant(ctx, expired.TokenHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expired grant kept: %v", err)
+			}
+
+			// A grant is consumed once; a second use finds it gone.
+			if err := s.DeleteRecoveryGrant(ctx, grant.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DeleteRecoveryGrant(ctx, grant.ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("grant consumed twice: %v", err)
+			}
+			if _, err := s.GetRecoveryGrant(ctx, grant.TokenHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("consumed grant found: %v", err)
+			}
+		})
+	}
+}
+
+func TestChallenges(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Round(time.Second)
+			open := func(expires time.Time) (string, *models.Challenge) {
+				id, c, err := models.NewChallenge(&webauthn.SessionData{Challenge: "c-" + expires.String()}, expires)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := s.CreateChallenge(ctx, c); err != nil || c.ID == 0 {
+					t.Fatalf("CreateChallenge = %v, ID %d", err, c.ID)
+				}
+				return id, c
+			}
+			_, c := open(now.Add(time.Hour))
+			_, expired := open(now.Add(-time.Minute))
+
+			if err := s.CreateChallenge(ctx, &models.Challenge{IDHash: c.IDHash}); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("duplicate ID hash: %v", err)
+			}
+			if n, err := s.CountChallenges(ctx, now); err != nil || n != 1 {
+				t.Fatalf("CountChallenges = %d, %v; want 1", n, err)
+			}
+			if err := s.DeleteExpiredChallenges(ctx, now); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.TakeChallenge(ctx, expired.IDHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expired challenge kept: %v", err)
+			}
+
+			// A challenge is taken once; a second use finds it gone.
+			got, err := s.TakeChallenge(ctx, c.IDHash)
+			if err != nil || got.ID != c.ID || got.Data != c.Data || !got.Expires.Equal(c.Expires) {
+				t.Fatalf("TakeChallenge = %+v, %v", got, err)
+			}
+			if _, err := s.TakeChallenge(ctx, c.IDHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("challenge taken twice: %v", err)
+			}
+		})
+	}
+}
+
+func TestPayments(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, username := range []string{"alice@sonr.io", "bob@sonr.io"} {
+				if err := s.PutUser(ctx, &models.User{Username: username}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := s.AttachIntent(ctx, "pi_123", "alice@sonr.io"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SuccessfulPayment(ctx, "pi_123"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.RecordPayment(ctx, "bob@sonr.io"); err != nil {
+				t.Fatal(err)
+			}
+			for _, username := range []string{"alice@sonr.io", "bob@sonr.io"} {
+				if u, err := s.GetUserByUsername(ctx, username); err != nil || !u.Paid {
+					t.Errorf("%s not marked paid: %v", username, err)
+				}
+			}
+			if u, _ := s.GetUserByUsername(ctx, "alice@sonr.io"); u.PiID != "pi_123" {
+				t.Errorf("PiID = %q", u.PiID)
+			}
+			if err := s.SuccessfulPayment(ctx, "pi_unknown"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("unknown payment intent: %v", err)
+			}
+		})
+	}
+}
+
+func TestUniqueKeys(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.Migrate(ctx); err != nil {
+				t.Fatalf("second Migrate: %v", err)
+			}
+			// Users created from a bare DID share an empty username.
+			for _, did := range []string{"did:sonr:alice", "did:sonr:bob"} {
+				if err := s.AddDid(ctx, did, models.Jwt{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.AddDid(ctx, "did:sonr:alice", models.Jwt{}); !errors.Is(err, ErrDuplicate) {
+				t.Errorf("duplicate DID: %v", err)
+			}
+			if err := s.PutUser(ctx, &models.User{Username: "alice@sonr.io"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.PutUser(ctx, &models.User{Username: "alice@sonr.io"}); !errors.Is(err, ErrDuplicate) {
+				t.Errorf("duplicate username: %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				err := s.CreateCredential(ctx, &models.Credential{CredentialID: "cred-1"})
+				if i == 1 && !errors.Is(err, ErrDuplicate) {
+					t.Errorf("duplicate credential ID: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			register := func(username string, fail error) error {
+				return s.Transaction(ctx, func(ctx context.Context, tx Store) error {
+					u := &models.User{Username: username, Did: "did:sonr:" + username}
+					if err := tx.NewUser(ctx, u); err != nil {
+						return err
+					}
+					auth, err := tx.CreateAuthenticator(ctx, webauthn.Authenticator{SignCount: 1}, "none")
+					if err != nil {
+						return err
+					}
+					c := &models.Credential{CredentialID: "cred-" + username, Authenticator: *auth, AuthenticatorID: auth.ID}
+					if err := tx.CreateCredential(ctx, c); err != nil {
+						return err
+					}
+					if err := tx.GiveUserCred(ctx, username, c); err != nil {
+						return err
+					}
+					return fail
+				})
+			}
+
+			failed := errors.New("attestation rejected")
+			if err := register("alice", failed); err != failed {
+				t.Fatalf("Transaction returned %v, want fn's error", err)
+			}
+			if _, err := s.GetUserByUsername(ctx, "alice"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("rolled back user was stored: %v", err)
+			}
+			if err := s.DeleteCredentialByID(ctx, "cred-alice"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("rolled back credential was stored: %v", err)
+			}
+
+			if err := register("alice", nil); err != nil {
+				t.Fatal(err)
+			}
+			u, err := s.GetUserByUsername(ctx, "alice")
+			if err != nil || len(u.Credentials) != 1 {
+				t.Fatalf("committed user = %+v, %v", u, err)
+			}
+			if err := register("alice", nil); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("second registration: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrationOrder(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 || m.Name == "" || m.Up == nil {
+			t.Errorf("migration %d is %+v; versions must run 1, 2, 3...", i, m)
+		}
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for name, s := range stores(t) {
+		if _, err := s.GetUser(canceled, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: GetUser with a canceled context: %v", name, err)
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	for err, want := range map[error]codes.Code{
+		ErrNotFound:                              codes.NotFound,
+		wrap(ErrDuplicate, errors.New("E11000")): codes.AlreadyExists,
+		wrap(ErrUnavailable, errors.New("down")): codes.Unavailable,
+	} {
+		var se interface{ GRPCStatus() *status.Status }
+		if !errors.As(err, &se) || se.GRPCStatus().Code() != want {
+			t.Errorf("%v: want %v", err, want)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := Open(&config.SonrConfig{DbBackend: "postgres"}); err == nil {
+		t.Fatal("unknown backend accepted")
+	}
+	s, err := Open(&config.SonrConfig{DbBackend: BackendMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
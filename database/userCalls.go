@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/sonr-io/webauthn.io/models"
@@ -15,140 +14,139 @@ type RecordNameObj struct {
 	// TimeStamp time.Time
 }
 
-func (db *MongoClient) FindDid(did string) *models.User {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// findUser returns the first user matching filter.
+func (db *MongoClient) findUser(ctx context.Context, filter bson.M) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	record := models.User{}
-	collection.FindOne(ctx, bson.M{"did": did}).Decode(&record)
-	return &record
+	user := &models.User{}
+	if err := db.users.FindOne(ctx, filter).Decode(user); err != nil {
+		return nil, mongoError(err)
+	}
+	return user, nil
 }
 
-func (db *MongoClient) AddDid(did string, jwt models.Jwt) error {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// updateUser applies update to the first user matching filter.
+func (db *MongoClient) updateUser(ctx context.Context, filter bson.M, update bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-
-	record := &models.User{
-		Did: did,
-		Jwt: jwt,
+	res, err := db.users.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
 	}
-
-	res, err := collection.InsertOne(ctx, record)
-	if err != nil || res == nil {
-		log.Print("\nunable to insert entry into DB in database package\n")
-		return err
+	if res.MatchedCount == 0 {
+		return ErrNotFound
 	}
 	return nil
 }
 
-func (db *MongoClient) StoreRecord(nameToRecord string, did string) bool {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// insertUser stores a new user, assigning it an ID if it has none.
+func (db *MongoClient) insertUser(ctx context.Context, user *models.User) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
+	if user.ID == 0 {
+		id, err := db.nextID(ctx, "users")
+		if err != nil {
+			return err
+		}
+		user.ID = id
+	}
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	_, err := db.users.InsertOne(ctx, user)
+	return mongoError(err)
+}
 
-	q1 := bson.M{"did": did}
-	q2 := bson.M{"$addToSet": bson.M{"names": nameToRecord}}
-
-	record := models.User{}
-	collection.FindOne(ctx, q1).Decode(&record)
+func (db *MongoClient) FindDid(ctx context.Context, did string) (*models.User, error) {
+	return db.findUser(ctx, bson.M{"did": did})
+}
 
-	if record.Did == "" {
-		return false
-	}
+func (db *MongoClient) AddDid(ctx context.Context, did string, jwt models.Jwt) error {
+	return db.insertUser(ctx, &models.User{
+		Did: did,
+		Jwt: jwt,
+	})
+}
 
-	collection.FindOneAndUpdate(ctx, q1, q2)
-	return true
+// StoreRecord adds nameToRecord to the names of the user owning did.
+func (db *MongoClient) StoreRecord(ctx context.Context, nameToRecord string, did string) error {
+	return db.updateUser(ctx, bson.M{"did": did}, bson.M{"$addToSet": bson.M{"names": nameToRecord}})
 }
 
 // check if name is available, if available return true
-func (db *MongoClient) CheckName(name string) (bool, error) {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (db *MongoClient) CheckName(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	result, err := collection.CountDocuments(ctx, bson.M{"names": name})
-
+	result, err := db.users.CountDocuments(ctx, bson.M{"names": name})
 	if err != nil {
-		return false, err
+		return false, mongoError(err)
 	}
-
-	//fmt.Println(result)
-
 	return result == 0, nil
 }
 
-func (db *MongoClient) NewUser(user models.User) error {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (db *MongoClient) NewUser(ctx context.Context, user *models.User) error {
 	user.Credentials = []models.Credential{}
 	user.Created = time.Now()
-	collection.InsertOne(ctx, user)
-	return nil
+	return db.insertUser(ctx, user)
 }
 
-func (db *MongoClient) FindUserByName(name string) *models.User {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	user := &models.User{}
-	collection.FindOne(ctx, bson.M{"names": name}).Decode(user)
-	return user
+func (db *MongoClient) FindUserByName(ctx context.Context, name string) (*models.User, error) {
+	return db.findUser(ctx, bson.M{"names": name})
+}
+
+func (db *MongoClient) AttachDid(ctx context.Context, userID uint, placeHolderDid string, newDid string) error {
+	return db.updateUser(ctx, bson.M{"model.id": userID, "did": placeHolderDid}, bson.M{"$set": bson.M{"did": newDid}})
+}
+
+func (db *MongoClient) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	return db.findUser(ctx, bson.M{"model.id": id})
 }
 
-func (db *MongoClient) AttachDid(placeHolderDid string, newDid string) error {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (db *MongoClient) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return db.findUser(ctx, bson.M{"username": username})
+}
+
+// PutUser creates u, or replaces the stored user with the same ID.
+func (db *MongoClient) PutUser(ctx context.Context, u *models.User) error {
+	if u.ID == 0 {
+		return db.insertUser(ctx, u)
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	user := models.User{}
-	collection.FindOneAndUpdate(ctx, bson.M{"did": placeHolderDid}, bson.M{"$set": bson.M{"did": newDid}}).Decode(user)
+	u.UpdatedAt = time.Now()
+	res, err := db.users.ReplaceOne(ctx, bson.M{"model.id": u.ID}, u)
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
-func (db *MongoClient) GetUser(id uint) *models.User {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	user := &models.User{}
-	collection.FindOne(ctx, bson.M{"model.id": id}).Decode(user)
-	return user
+func (db *MongoClient) RecordPayment(ctx context.Context, name string) error {
+	return db.updateUser(ctx, bson.M{"username": name}, bson.M{"$set": bson.M{"paid": true}})
 }
 
-func (db *MongoClient) GetUserByUsername(username string) *models.User {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	user := &models.User{}
-	collection.FindOne(ctx, bson.M{"username": username}).Decode(user)
-	return user
+func (db *MongoClient) AttachIntent(ctx context.Context, piID string, name string) error {
+	return db.updateUser(ctx, bson.M{"username": name}, bson.M{"$set": bson.M{"piid": piID}})
 }
 
-func (db *MongoClient) PutUser(u *models.User) *models.User {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	collection.FindOneAndUpdate(ctx, bson.M{"id": u.ID}, u).Decode(u)
-	return u
+func (db *MongoClient) SuccessfulPayment(ctx context.Context, piID string) error {
+	return db.updateUser(ctx, bson.M{"piid": piID}, bson.M{"$set": bson.M{"paid": true}})
 }
 
-func (db *MongoClient) RecordPayment(name string) error {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	collection.FindOneAndUpdate(ctx, bson.M{"username": name}, bson.M{"$set": bson.M{"paid": true}})
-	return nil
+// UpdateProfile sets the public display fields of the user owning did.
+func (db *MongoClient) UpdateProfile(ctx context.Context, did string, displayName string, icon string) error {
+	return db.updateUser(ctx, bson.M{"did": did}, bson.M{"$set": bson.M{"displayname": displayName, "icon": icon}})
 }
 
-func (db *MongoClient) AttachIntent(piID string, name string) {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	collection.FindOneAndUpdate(ctx, bson.M{"username": name}, bson.M{"$set": bson.M{"piid": piID}})
+// SetRecoveryCodes replaces the recovery code hashes of a user.
+func (db *MongoClient) SetRecoveryCodes(ctx context.Context, userID uint, hashes models.RecoveryCodes) error {
+	return db.updateUser(ctx, bson.M{"model.id": userID}, bson.M{"$set": bson.M{"recoverycodes": hashes}})
 }
 
-func (db *MongoClient) SuccessfulPayment(piID string) {
-	collection := db.users
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	collection.FindOneAndUpdate(ctx, bson.M{"piid": piID}, bson.M{"$set": bson.M{"paid": true}})
+// UseRecoveryCode pulls hash from the user's recovery codes. The filter only
+// matches while the code is unused, so concurrent uses cannot both succeed.
+func (db *MongoClient) UseRecoveryCode(ctx context.Context, userID uint, hash string) error {
+	return db.updateUser(ctx, bson.M{"model.id": userID, "recoverycodes": hash}, bson.M{"$pull": bson.M{"recoverycodes": hash}})
 }
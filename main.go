@@ -6,11 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
 	"strings"
-	"syscall"
 
 	"github.com/sonr-io/webauthn.io/config"
 	"github.com/sonr-io/webauthn.io/controller"
@@ -18,19 +17,34 @@ import (
 	"github.com/sonr-io/webauthn.io/logger"
 	log "github.com/sonr-io/webauthn.io/logger"
 	"github.com/sonr-io/webauthn.io/models"
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	"github.com/sonr-io/webauthn.io/pkg/blob"
+	"github.com/sonr-io/webauthn.io/pkg/certs"
 	"github.com/sonr-io/webauthn.io/pkg/client"
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	"github.com/sonr-io/webauthn.io/pkg/gateway"
+	"github.com/sonr-io/webauthn.io/pkg/grpcweb"
+	"github.com/sonr-io/webauthn.io/pkg/health"
+	"github.com/sonr-io/webauthn.io/pkg/interceptor"
+	"github.com/sonr-io/webauthn.io/pkg/lifecycle"
+	"github.com/sonr-io/webauthn.io/pkg/multiplex"
+	"github.com/sonr-io/webauthn.io/pkg/pubsub"
+	credpb "github.com/sonr-io/webauthn.io/proto/credentials/v1"
+	docpb "github.com/sonr-io/webauthn.io/proto/documents/v1"
+	"github.com/sonr-io/webauthn.io/reflection"
 	"github.com/sonr-io/webauthn.io/server"
+	"github.com/sonr-io/webauthn.io/session"
 	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
-	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"google.golang.org/grpc"
 )
 
 const (
-	// PEM_CERT_FILE is the path to the certificate file.
+	// PEM_CERT_FILE is the certificate file used when TLS_CERT_FILE is unset.
 	PEM_CERT_FILE = "cert.pem"
 
-	// PEM_KEY_FILE is the file containing the private key.
+	// PEM_KEY_FILE is the private key file used when TLS_KEY_FILE is unset.
 	PEM_KEY_FILE = "key.pem"
 )
 
@@ -45,14 +59,16 @@ var (
 )
 
 func main() {
-
-	var ctx context.Context
-
 	highwayConfig, err := config.Load()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	sessionKeys, err := session.LoadKeys(highwayConfig.SessionKeys, highwayConfig.SessionKeyFile)
+	if err != nil {
+		log.Fatalf("loading session keys: %s", err)
+	}
+
 	authConfig := &config.Config{
 		HostAddress:  highwayConfig.HighwayAddress,
 		HostPort:     highwayConfig.HttpPort,
@@ -60,6 +76,7 @@ func main() {
 		DBPath:       highwayConfig.SqlPath,
 		RelyingParty: highwayConfig.RelyingParty,
 		RPOrigin:     highwayConfig.RPOrigin + highwayConfig.RPPort,
+		SessionKeys:  sessionKeys.Flatten(),
 	}
 
 	err = log.Setup(authConfig)
@@ -67,7 +84,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Create the cosmos listener.
+	// Create the RPC listener. In single-port mode it carries HTTP as well.
 	l, err := net.Listen(verifyAddress(highwayConfig))
 	if err != nil {
 		log.Fatal(err)
@@ -77,61 +94,260 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	// Get TLS config if TLS is enabled
-	var stub *models.HighwayStub
-	credentials, err := loadTLSCredentials()
 
+	// Every RPC is authenticated by mTLS or a session token issued by the
+	// HTTP server, logged, and recovered from panics. The REST gateway runs
+	// the same chain. Session tokens are refused once their login session
+	// ends; the controller checking them is created below, before any call
+	// is served.
+	var ctrl *controller.Controller
+	authn := auth.NewAuthenticator([]byte(highwayConfig.SecretKey),
+		auth.WithPublicMethods(append(models.PublicMethods,
+			"/grpc.health.v1.Health/",
+			"/grpc.reflection.v1alpha.ServerReflection/",
+		)...),
+		auth.WithSessionCheck(func(ctx context.Context, session string, did string) error {
+			return ctrl.CheckSession(ctx, session, did)
+		}),
+	)
+	grpcOpts := interceptor.ServerOptions(authn)
+
+	// Get TLS config if TLS is enabled. A node configured for TLS refuses to
+	// serve plaintext when the certificates cannot be loaded, since it would
+	// serve without authenticating callers.
+	tlsCerts, err := loadTLS(highwayConfig)
 	if err != nil {
-		//logger.Infof("Error loading TLS credentials: ", err)
-
-		// If TLS is not enabled, create a new listener.
-		// Create the RPC Service
-		stub = &models.HighwayStub{
-			Host:   nil,
-			Ctx:    ctx,
-			Grpc:   grpc.NewServer(),
-			Cosmos: cosmos.Client,
+		if tlsRequired(highwayConfig) {
+			log.Fatalf("TLS is configured but could not be loaded: %s", err)
 		}
-		hw.RegisterHighwayServer(stub.Grpc, stub)
-		//reflection.RegisterReflection(stub.grpc)
-		//logger.Infof("Starting RPC Service on %s", l.Addr().String())
+		logger.Warnf("Error loading TLS credentials, serving RPC without TLS: %s", err)
 	} else {
-		// Create the RPC Service
-		stub = &models.HighwayStub{
-			Host:   nil,
-			Ctx:    ctx,
-			Grpc:   grpc.NewServer(grpc.Creds(credentials)),
-			Cosmos: cosmos.Client,
-		}
-		hw.RegisterHighwayServer(stub.Grpc, stub)
-		//reflection.RegisterReflection(stub.grpc)
-		//logger.Infof("Starting RPC Service on %s", l.Addr().String())
+		grpcOpts = append(grpcOpts, grpc.Creds(tlsCerts.Credentials()))
+	}
+
+	blobs, err := blob.New(highwayConfig)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	DB, err := db.Connect(highwayConfig.MongoUri, highwayConfig.MongoCollectionName, highwayConfig.MongoDbName)
+	documents, err := docstore.Open(highwayConfig.CacheFolder().JoinPath("documents"))
 	if err != nil {
-		logger.Errorf("database connection failed")
+		log.Fatal(err)
 	}
 
-	ctrl, err := controller.New(DB, highwayConfig, stub)
+	// Blob transfers read and write callers' paths, so they get a directory
+	// of their own, apart from the blob index and document store.
+	workspace, err := highwayConfig.CacheFolder().CreateFolder("workspace")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	server, err := server.NewServer(ctrl, authConfig)
+	// Create the RPC Service
+	stub := &models.HighwayStub{
+		Host:      nil,
+		Ctx:       context.Background(),
+		Grpc:      grpc.NewServer(grpcOpts...),
+		Cosmos:    cosmos.Client,
+		Channels:  pubsub.NewBroker(),
+		Blobs:     blobs,
+		Documents: documents,
+		Workspace: workspace,
+	}
+	hw.RegisterHighwayServer(stub.Grpc, stub)
+	docpb.RegisterDocumentsServer(stub.Grpc, &models.DocumentService{Documents: documents})
+
+	// Health reflects the state of the node's dependencies; reflection is
+	// opt-in since it exposes the full API surface.
+	monitor := health.NewMonitor(health.WithServices(
+		hw.Highway_ServiceDesc.ServiceName,
+		credpb.Credentials_ServiceDesc.ServiceName,
+		docpb.Documents_ServiceDesc.ServiceName,
+	))
+	healthpb.RegisterHealthServer(stub.Grpc, monitor.Server)
+	if highwayConfig.GrpcReflection {
+		reflection.RegisterReflection(stub.Grpc)
+	}
+
+	DB, err := db.Open(highwayConfig)
+	if err != nil {
+		log.Fatalf("database connection failed: %s", err)
+	}
+
+	ctrl, err = controller.New(DB, highwayConfig, stub)
 	if err != nil {
 		log.Fatal(err)
 	}
-	go server.Start()
+	stub.Names = ctrl
+	stub.Dids = ctrl
+	credpb.RegisterCredentialsServer(stub.Grpc, &models.CredentialService{Credentials: ctrl})
 
-	// Handle graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGTERM)
-	signal.Notify(c, syscall.SIGINT)
+	monitor.Add("database", DB.Ping)
+	monitor.Add("blob", blobs.Ping)
+	// Highway keeps serving its own data while the chain is unreachable, so
+	// cosmos is reported on its own without taking the node out of rotation.
+	monitor.AddOptional("cosmos", cosmos.Ping)
 
-	<-c
-	log.Info("Shutting down...")
-	server.Shutdown()
+	// The REST gateway dispatches to the stub in-process and shares the HTTP
+	// server's listener and certificates.
+	gw, err := stub.Gateway(
+		gateway.WithUnaryInterceptor(interceptor.Unary(authn)),
+		gateway.WithStreamInterceptor(interceptor.Stream(authn)),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Browsers reach the gRPC service through gRPC-Web on the HTTP server.
+	// Streams such as ListenChannel outlive any write timeout.
+	web := grpcweb.New(stub.Grpc, grpcweb.WithAllowedOrigins(highwayConfig.GrpcWebOrigins))
+	serverOpts := []server.Option{
+		server.WithHandler(models.GatewayPrefix, gw),
+		server.WithMatchedHandler(web.Match, web),
+		server.WithWriteTimeout(0),
+	}
+	// Browsers have no client certificate, so the HTTP listener only
+	// verifies one when it is given. Client authentication stays required
+	// for native gRPC, on its own listener or routed off the shared one.
+	var tlsConfig *tls.Config
+	var muxOpts []multiplex.Option
+	if tlsCerts != nil {
+		tlsConfig = tlsCerts.HTTPConfig()
+		if highwayConfig.MuxPort == "" {
+			serverOpts = append(serverOpts, server.WithTLS(tlsConfig))
+		} else if tlsCerts.ClientAuth() {
+			muxOpts = append(muxOpts, multiplex.RequireClientCert())
+		}
+	}
+	server, err := server.NewServer(ctrl, authConfig, serverOpts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Hooks are started in order and drained in reverse: health checks report
+	// NOT_SERVING first so load balancers stop routing to the node, then the
+	// RPC service stops accepting calls, then the HTTP server, then the
+	// database.
+	lc := lifecycle.New()
+	lc.Append(lifecycle.Hook{
+		Name: "database",
+		OnStart: func(ctx context.Context) error {
+			if err := DB.Ping(ctx); err != nil {
+				return err
+			}
+			if highwayConfig.DbMigrate {
+				return DB.Migrate(ctx)
+			}
+			return nil
+		},
+		OnStop: DB.Disconnect,
+	})
+	if highwayConfig.MuxPort != "" {
+		mux, err := multiplex.New(stub.Grpc, server.Handler(), tlsConfig, muxOpts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		lc.Append(lifecycle.Hook{
+			Name: "mux",
+			OnStart: func(ctx context.Context) error {
+				logger.Infof("Serving RPC and HTTP on %s", l.Addr().String())
+				lc.Go("mux", func() error {
+					if err := mux.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+						return err
+					}
+					return nil
+				})
+				return nil
+			},
+			OnStop: mux.Shutdown,
+		})
+	} else {
+		lc.Append(lifecycle.Hook{
+			Name: "http",
+			OnStart: func(ctx context.Context) error {
+				hl, err := net.Listen("tcp", server.Addr())
+				if err != nil {
+					return err
+				}
+				lc.Go("http", func() error {
+					if err := server.Serve(hl); !errors.Is(err, http.ErrServerClosed) {
+						return err
+					}
+					return nil
+				})
+				return nil
+			},
+			OnStop: server.Shutdown,
+		})
+		lc.Append(lifecycle.Hook{
+			Name: "grpc",
+			OnStart: func(ctx context.Context) error {
+				logger.Infof("Starting RPC Service on %s", l.Addr().String())
+				lc.Go("grpc", func() error {
+					return stub.Grpc.Serve(l)
+				})
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return gracefulStop(ctx, stub.Grpc)
+			},
+		})
+	}
+
+	if tlsCerts != nil {
+		stopWatch := func() {}
+		lc.Append(lifecycle.Hook{
+			Name: "tls",
+			OnStart: func(ctx context.Context) error {
+				wctx, cancel := context.WithCancel(context.Background())
+				stopWatch = cancel
+				go tlsCerts.Watch(wctx)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				stopWatch()
+				return nil
+			},
+		})
+	}
+
+	stopHealth := func() {}
+	lc.Append(lifecycle.Hook{
+		Name: "health",
+		OnStart: func(ctx context.Context) error {
+			hctx, cancel := context.WithCancel(context.Background())
+			stopHealth = cancel
+			lc.Go("health", func() error {
+				monitor.Run(hctx)
+				return nil
+			})
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			monitor.Shutdown()
+			stopHealth()
+			return nil
+		},
+	})
+
+	if err := lc.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// gracefulStop waits for pending RPCs to finish, forcing the server closed if
+// ctx expires first. Open ListenChannel streams would otherwise block forever.
+func gracefulStop(ctx context.Context, s *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
 }
 
 // verifyAddress verifies the address is valid.
@@ -148,8 +364,12 @@ func verifyAddress(cnfg *config.SonrConfig) (string, string) {
 	}
 	logger.Infof("Network: %s", network)
 
-	//set port
-	port, err = strconv.Atoi(cnfg.GrpcPort)
+	//set port, the gRPC port is shared with HTTP in single-port mode
+	portStr := cnfg.GrpcPort
+	if cnfg.MuxPort != "" {
+		portStr = cnfg.MuxPort
+	}
+	port, err = strconv.Atoi(portStr)
 	if err != nil {
 		return "", err.Error()
 	}
@@ -173,18 +393,27 @@ func fileExists(filename string) bool {
 	return !info.IsDir()
 }
 
-func loadTLSCredentials() (credentials.TransportCredentials, error) {
-	// Load server's certificate and private key
-	serverCert, err := tls.LoadX509KeyPair(PEM_CERT_FILE, PEM_KEY_FILE)
-	if err != nil {
-		return nil, err
-	}
+// tlsRequired reports whether the node must not fall back to plaintext:
+// in strict mode, with client authentication, or when certificate files are
+// configured explicitly.
+func tlsRequired(cnfg *config.SonrConfig) bool {
+	return cnfg.TLSStrict || cnfg.TLSClientAuth || cnfg.TLSCertFile != "" || cnfg.TLSKeyFile != "" || cnfg.TLSCAFile != ""
+}
 
-	// Create the credentials and return it
-	config := &tls.Config{
-		Certificates: []tls.Certificate{serverCert},
-		ClientAuth:   tls.NoClientCert,
+// loadTLS loads the node's certificates from the configured paths, falling
+// back to cert.pem and key.pem in the working directory.
+func loadTLS(cnfg *config.SonrConfig) (*certs.Reloader, error) {
+	certFile, keyFile := cnfg.TLSCertFile, cnfg.TLSKeyFile
+	if certFile == "" {
+		certFile = PEM_CERT_FILE
 	}
-
-	return credentials.NewTLS(config), nil
+	if keyFile == "" {
+		keyFile = PEM_KEY_FILE
+	}
+	return certs.NewReloader(certs.Config{
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		CAFile:     cnfg.TLSCAFile,
+		ClientAuth: cnfg.TLSClientAuth,
+	})
 }
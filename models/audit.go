@@ -0,0 +1,36 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// Kinds of AuditEvent.
+const (
+	// AuditSignCountRegression is recorded when an authenticator reports a
+	// sign count no higher than the stored one, which suggests it was cloned.
+	AuditSignCountRegression = "sign_count_regression"
+
+	// AuditRecoveryCodesIssued is recorded when a new set of recovery codes
+	// replaces the user's previous ones.
+	AuditRecoveryCodesIssued = "recovery_codes_issued"
+
+	// AuditRecoveryCodeUsed is recorded when a recovery code opens a
+	// recovery session.
+	AuditRecoveryCodeUsed = "recovery_code_used"
+
+	// AuditRecoveryFailed is recorded when a recovery attempt gives a wrong
+	// code for an existing account.
+	AuditRecoveryFailed = "recovery_failed"
+
+	// AuditRecoveryCredentialAdded is recorded when a recovery session
+	// enrolls a new credential.
+	AuditRecoveryCredentialAdded = "recovery_credential_added"
+)
+
+// AuditEvent is a security relevant event on a user's account, kept so the
+// user and operators can review it later.
+type AuditEvent struct {
+	gorm.Model
+	UserID       uint   `json:"user_id" gorm:"index"`
+	Kind         string `json:"kind"`
+	CredentialID string `json:"credential_id,omitempty"`
+	Detail       string `json:"detail,omitempty"`
+}
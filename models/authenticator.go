@@ -11,4 +11,9 @@ import (
 type Authenticator struct {
 	gorm.Model
 	webauthn.Authenticator
+
+	// AttestationType is how the authenticator proved its model, named as
+	// in FIDO metadata statements, such as "basic_full" or "none". The model
+	// is the embedded AAGUID.
+	AttestationType string `json:"attestation_type"`
 }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/jinzhu/gorm"
+)
+
+// ErrChallengeNotFound is returned for a challenge ID that is unknown, was
+// already used or has expired.
+var ErrChallengeNotFound = errors.New("challenge not found or expired")
+
+// ErrTooManyChallenges is returned when a challenge is opened while the limit
+// of unexpired ones is held.
+var ErrTooManyChallenges = errors.New("too many open challenges, try again later")
+
+// Challenge is the WebAuthn session data of a ceremony that outlives the
+// session cookie's slots, such as an autofill login. The browser holds a
+// random ID; as with a Session, only its hash is stored. It is kept in the
+// database so that any replica can finish a ceremony another one began.
+type Challenge struct {
+	gorm.Model
+	IDHash string `json:"-" gorm:"unique_index"`
+	// Data is the session data encoded as JSON.
+	Data    string    `json:"-"`
+	Expires time.Time `json:"expires" gorm:"index"`
+}
+
+// Active reports whether the challenge has not timed out.
+func (c Challenge) Active(now time.Time) bool {
+	return now.Before(c.Expires)
+}
+
+// NewChallenge returns a challenge holding data and expiring at expires,
+// with the ID to hand the browser.
+func NewChallenge(data *webauthn.SessionData, expires time.Time) (string, *Challenge, error) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return "", nil, err
+	}
+	id, hash, err := NewSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return id, &Challenge{IDHash: hash, Data: string(buf), Expires: expires}, nil
+}
+
+// SessionData decodes the session data the challenge holds.
+func (c Challenge) SessionData() (webauthn.SessionData, error) {
+	var data webauthn.SessionData
+	err := json.Unmarshal([]byte(c.Data), &data)
+	return data, err
+}
@@ -17,6 +17,9 @@ import (
 	log "github.com/sonr-io/webauthn.io/logger"
 )
 
+// MaxNicknameLength is the longest nickname a credential can be given.
+const MaxNicknameLength = 64
+
 // Credential is the stored credential for Auth
 type Credential struct {
 	gorm.Model
@@ -30,6 +33,14 @@ type Credential struct {
 	AuthenticatorID uint          `json:"authenticator_id"`
 
 	PublicKey []byte `json:"public_key,omitempty"`
+
+	// Nickname is the name the user gave the credential, such as the device
+	// holding it.
+	Nickname string `json:"nickname,omitempty"`
+
+	// Disabled credentials can no longer be used to log in, such as after
+	// their authenticator appeared to be cloned.
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 // WebauthnAuthenticator returns the underlying authenticator used to generate
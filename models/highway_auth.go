@@ -0,0 +1,30 @@
+package models
+
+import (
+	"context"
+
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PublicMethods are the Highway RPCs callers may use without authenticating.
+// They only read public records.
+var PublicMethods = []string{
+	"/" + hw.Highway_ServiceDesc.ServiceName + "/AccessName",
+	"/" + hw.Highway_ServiceDesc.ServiceName + "/CheckName",
+	"/" + hw.Highway_ServiceDesc.ServiceName + "/GenerateCreds",
+	"/" + hw.Highway_ServiceDesc.ServiceName + "/ParseDid",
+	"/" + hw.Highway_ServiceDesc.ServiceName + "/ResolveDid",
+}
+
+// callerDid returns the authenticated caller's DID. Handlers use it as the
+// owner of the records they touch; Creator fields in requests are not trusted.
+func callerDid(ctx context.Context) (string, error) {
+	did, ok := auth.Caller(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "caller is not authenticated")
+	}
+	return did, nil
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/pkg/blob"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrPathOutsideWorkspace is returned when a blob path resolves outside the
+// caller's workspace.
+var ErrPathOutsideWorkspace = errors.New("path is outside the workspace")
+
+// UploadBlob stores the file at req.Path and returns its CID. The blob is
+// owned by the authenticated caller. Paths of the blob RPCs are resolved in
+// the caller's own workspace directory.
+func (s *HighwayStub) UploadBlob(ctx context.Context, req *hw.MsgUploadBlob) (*hw.MsgUploadBlobResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path, err := s.workspacePath(owner, req.GetPath())
+	if err != nil {
+		return nil, blobError(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, blobError(err)
+	}
+	defer f.Close()
+
+	b, err := s.Blobs.Put(ctx, f, blob.PutOptions{
+		Owner:  owner,
+		Label:  req.GetLabel(),
+		RefDid: req.GetRefDid(),
+	})
+	if err != nil {
+		return nil, blobError(err)
+	}
+	return &hw.MsgUploadBlobResponse{
+		Code:    http.StatusOK,
+		Message: "blob uploaded",
+		Did:     b.CID,
+		Pinned:  b.Pinned,
+	}, nil
+}
+
+// DownloadBlob writes the blob identified by req.Did to req.OutPath.
+func (s *HighwayStub) DownloadBlob(ctx context.Context, req *hw.MsgDownloadBlob) (*hw.MsgDownloadBlobResponse, error) {
+	caller, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path, err := s.workspacePath(caller, req.GetOutPath())
+	if err != nil {
+		return nil, blobError(err)
+	}
+	if err := s.fetchBlob(ctx, req.GetDid(), path); err != nil {
+		return nil, blobError(err)
+	}
+	return &hw.MsgDownloadBlobResponse{
+		Code:    http.StatusOK,
+		Message: "blob downloaded",
+		Did:     req.GetDid(),
+		Path:    path,
+	}, nil
+}
+
+// SyncBlob makes the file at req.Path match the blob identified by req.Did,
+// downloading it only when the local copy is missing or differs. The call is
+// abandoned after req.Timeout seconds when set.
+func (s *HighwayStub) SyncBlob(ctx context.Context, req *hw.MsgSyncBlob) (*hw.MsgSyncBlobResponse, error) {
+	caller, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetTimeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.GetTimeout())*time.Second)
+		defer cancel()
+	}
+	if !blob.ValidCID(req.GetDid()) {
+		return nil, blobError(blob.ErrInvalidCID)
+	}
+	path, err := s.workspacePath(caller, req.GetPath())
+	if err != nil {
+		return nil, blobError(err)
+	}
+
+	if f, err := os.Open(path); err == nil {
+		cid, err := blob.ComputeCID(f)
+		f.Close()
+		if err == nil && cid == req.GetDid() {
+			return &hw.MsgSyncBlobResponse{
+				Code:    http.StatusOK,
+				Message: "blob already in sync",
+				Did:     cid,
+			}, nil
+		}
+	}
+
+	if err := s.fetchBlob(ctx, req.GetDid(), path); err != nil {
+		return nil, blobError(err)
+	}
+	return &hw.MsgSyncBlobResponse{
+		Code:    http.StatusOK,
+		Message: "blob synced",
+		Did:     req.GetDid(),
+	}, nil
+}
+
+// DeleteBlob removes the authenticated caller as an owner of the blob. Only
+// callers that uploaded the blob may delete it; req.PublicKey is not trusted.
+func (s *HighwayStub) DeleteBlob(ctx context.Context, req *hw.MsgDeleteBlob) (*hw.MsgDeleteBlobResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Blobs.Delete(ctx, req.GetDid(), owner); err != nil {
+		return nil, blobError(err)
+	}
+	return &hw.MsgDeleteBlobResponse{
+		Code:    http.StatusOK,
+		Message: "blob deleted",
+		Did:     req.GetDid(),
+	}, nil
+}
+
+// fetchBlob copies a blob to path through a temporary file so that a failed
+// or cancelled transfer never leaves a partial file behind. The copy stops as
+// soon as ctx is done, whichever store the blob is read from.
+func (s *HighwayStub) fetchBlob(ctx context.Context, cid string, path string) error {
+	r, err := s.Blobs.Get(ctx, cid)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, blob.ContextReader(ctx, r))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// workspacePath resolves p against caller's directory of the node's
+// workspace, rejecting paths that would escape it. Callers never share a
+// directory, so one cannot read or replace the files of another.
+func (s *HighwayStub) workspacePath(caller string, p string) (string, error) {
+	if p == "" {
+		return "", status.Error(codes.InvalidArgument, "path is required")
+	}
+	root, err := filepath.Abs(s.Workspace.Path())
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(root, url.PathEscape(caller))
+	if filepath.Dir(dir) != root {
+		return "", ErrPathOutsideWorkspace
+	}
+	path := filepath.Join(dir, filepath.Clean("/"+p))
+	if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", ErrPathOutsideWorkspace
+	}
+	return path, nil
+}
+
+// blobError maps blob store errors to gRPC status errors.
+func blobError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, blob.ErrNotFound), os.IsNotExist(err):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, blob.ErrInvalidCID), errors.Is(err, ErrPathOutsideWorkspace):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, blob.ErrNotOwner):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, blob.ErrMissingOwner):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	"github.com/sonr-io/webauthn.io/pkg/blob"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newBlobStub(t *testing.T) *HighwayStub {
+	t.Helper()
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &HighwayStub{Blobs: blobs, Workspace: config.Folder(t.TempDir())}
+}
+
+func TestWorkspaceIsPerCaller(t *testing.T) {
+	s := newBlobStub(t)
+	alice := auth.WithCaller(context.Background(), "did:sonr:alice")
+	bob := auth.WithCaller(context.Background(), "did:sonr:bob")
+
+	alicePath, err := s.workspacePath("did:sonr:alice", "notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Folder(filepath.Dir(alicePath)).WriteFile("notes.txt", []byte("alice's notes")); err != nil {
+		t.Fatal(err)
+	}
+	up, err := s.UploadBlob(alice, &hw.MsgUploadBlob{Path: "notes.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bob's paths resolve in his own directory, so he can neither publish
+	// alice's file nor replace it.
+	if _, err := s.UploadBlob(bob, &hw.MsgUploadBlob{Path: "notes.txt"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("UploadBlob of another caller's file: %v", err)
+	}
+	down, err := s.DownloadBlob(bob, &hw.MsgDownloadBlob{Did: up.Did, OutPath: "notes.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if down.Path == alicePath {
+		t.Fatalf("DownloadBlob wrote to %s, alice's file", down.Path)
+	}
+	for _, p := range []string{"../did:sonr:alice/notes.txt", "/../../did:sonr:alice/notes.txt"} {
+		if got, err := s.workspacePath("did:sonr:bob", p); err == nil && !strings.Contains(got, "did:sonr:bob") {
+			t.Errorf("workspacePath(%q) = %s", p, got)
+		}
+	}
+
+	// A caller whose escaped DID is a relative name gets no directory.
+	for _, caller := range []string{"..", ".", ""} {
+		if _, err := s.workspacePath(caller, "notes.txt"); err != ErrPathOutsideWorkspace {
+			t.Errorf("workspacePath for caller %q: %v", caller, err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(alicePath)
+	if err != nil || string(data) != "alice's notes" {
+		t.Fatalf("alice's file = %q, %v", data, err)
+	}
+}
+
+// endlessBlobs serves every blob as an endless stream, calling cancel once
+// the first bytes have been read.
+type endlessBlobs struct {
+	blob.BlobStore
+	cancel context.CancelFunc
+}
+
+func (e endlessBlobs) Get(ctx context.Context, cid string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(endless{e.cancel}), nil
+}
+
+type endless struct {
+	cancel context.CancelFunc
+}
+
+func (e endless) Read(p []byte) (int, error) {
+	e.cancel()
+	return len(p), nil
+}
+
+func TestFetchBlobStopsWhenCancelled(t *testing.T) {
+	s := newBlobStub(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Blobs = endlessBlobs{cancel: cancel}
+
+	path := filepath.Join(t.TempDir(), "out")
+	if err := s.fetchBlob(ctx, "cid", path); err != context.Canceled {
+		t.Fatalf("fetchBlob = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("cancelled fetch left %s: %v", path, err)
+	}
+}
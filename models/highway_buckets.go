@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	bt "go.buf.build/grpc/go/sonr-io/sonr/bucket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateBucket creates a bucket owned by the caller with the given object
+// schemas attached.
+func (s *HighwayStub) CreateBucket(ctx context.Context, req *bt.MsgCreateBucket) (*bt.MsgCreateBucketResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Documents.CreateBucket(owner, docstore.Bucket{
+		Label:       req.GetLabel(),
+		Description: req.GetDescription(),
+		Kind:        strings.ToLower(req.GetKind()),
+		ObjectDids:  req.GetInitialObjectDids(),
+	})
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	return &bt.MsgCreateBucketResponse{
+		Code:    http.StatusOK,
+		Message: "bucket created",
+		WhichIs: bucketDoc(b),
+	}, nil
+}
+
+// ReadBucket returns one of the caller's buckets.
+func (s *HighwayStub) ReadBucket(ctx context.Context, req *bt.MsgReadBucket) (*bt.MsgReadBucketResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Documents.Bucket(owner, req.GetDid())
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	return &bt.MsgReadBucketResponse{
+		Code:   http.StatusOK,
+		Bucket: bucketDoc(b),
+	}, nil
+}
+
+// UpdateBucket updates the label or description of a bucket and attaches or
+// detaches object schemas.
+func (s *HighwayStub) UpdateBucket(ctx context.Context, req *bt.MsgUpdateBucket) (*bt.MsgUpdateBucketResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Documents.UpdateBucket(owner, req.GetDid(), func(b *docstore.Bucket) error {
+		if req.GetLabel() != "" {
+			b.Label = req.GetLabel()
+		}
+		if req.GetDescription() != "" {
+			b.Description = req.GetDescription()
+		}
+		removed := make(map[string]bool, len(req.GetRemovedObjectDids()))
+		for _, id := range req.GetRemovedObjectDids() {
+			removed[id] = true
+		}
+		kept := b.ObjectDids[:0]
+		for _, id := range b.ObjectDids {
+			if !removed[id] {
+				kept = append(kept, id)
+			}
+		}
+		b.ObjectDids = append(kept, req.GetAddedObjectDids()...)
+		return nil
+	})
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	return &bt.MsgUpdateBucketResponse{
+		Code:   http.StatusOK,
+		Bucket: bucketDoc(b),
+	}, nil
+}
+
+// DeleteBucket deletes a bucket and every document stored in it.
+func (s *HighwayStub) DeleteBucket(ctx context.Context, req *bt.MsgDeleteBucket) (*bt.MsgDeleteBucketResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Documents.DeleteBucket(owner, req.GetDid()); err != nil {
+		return nil, docstoreError(err)
+	}
+	return &bt.MsgDeleteBucketResponse{
+		Code:    http.StatusOK,
+		Message: "bucket deleted",
+	}, nil
+}
+
+// bucketDoc converts a stored bucket to its protobuf representation.
+func bucketDoc(b docstore.Bucket) *bt.BucketDoc {
+	doc := &bt.BucketDoc{
+		Label:       b.Label,
+		Description: b.Description,
+		Did:         b.Did,
+		ObjectDids:  b.ObjectDids,
+	}
+	switch b.Kind {
+	case "app":
+		doc.Type = bt.BucketType_BUCKET_TYPE_APP
+	case "user":
+		doc.Type = bt.BucketType_BUCKET_TYPE_USER
+	}
+	return doc
+}
+
+// docstoreError maps document store errors to gRPC status errors.
+func docstoreError(err error) error {
+	switch {
+	case errors.Is(err, docstore.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, docstore.ErrMissingOwner):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, docstore.ErrMissingLabel),
+		errors.Is(err, docstore.ErrInvalidSchema),
+		errors.Is(err, docstore.ErrInvalidDocument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, docstore.ErrObjectInUse):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
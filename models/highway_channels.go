@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/sonr-io/webauthn.io/pkg/did"
+	"github.com/sonr-io/webauthn.io/pkg/pubsub"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	ct "go.buf.build/grpc/go/sonr-io/sonr/channel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotChannelOwner is returned when a caller modifies a channel it did not
+// create.
+var ErrNotChannelOwner = errors.New("caller does not own the channel")
+
+// CreateChannel creates a new publish/subscribe channel owned by the caller.
+func (s *HighwayStub) CreateChannel(ctx context.Context, req *ct.MsgCreateChannel) (*ct.MsgCreateChannelResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetLabel() == "" {
+		return nil, status.Error(codes.InvalidArgument, "label is required")
+	}
+
+	channelDid, err := newChannelDid()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	t, err := s.Channels.CreateTopic(pubsub.Topic{
+		ID:          channelDid,
+		Label:       req.GetLabel(),
+		Description: req.GetDescription(),
+		Owner:       owner,
+	})
+	if err != nil {
+		return nil, channelError(err)
+	}
+	return &ct.MsgCreateChannelResponse{
+		Code:    http.StatusOK,
+		Message: "channel created",
+		WhichIs: channelDoc(t),
+	}, nil
+}
+
+// ReadChannel returns the configuration of a channel.
+func (s *HighwayStub) ReadChannel(ctx context.Context, req *ct.MsgReadChannel) (*ct.MsgReadChannelResponse, error) {
+	t, _, err := s.Channels.Topic(req.GetDid())
+	if err != nil {
+		return nil, channelError(err)
+	}
+	return &ct.MsgReadChannelResponse{
+		Code:    http.StatusOK,
+		Channel: channelDoc(t),
+	}, nil
+}
+
+// UpdateChannel updates the label, description or metadata of a channel and
+// notifies its listeners of the change.
+func (s *HighwayStub) UpdateChannel(ctx context.Context, req *ct.MsgUpdateChannel) (*ct.MsgUpdateChannelResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Channels.UpdateTopic(req.GetDid(), func(t *pubsub.Topic) error {
+		if t.Owner != owner {
+			return ErrNotChannelOwner
+		}
+		if req.GetLabel() != "" {
+			t.Label = req.GetLabel()
+		}
+		if req.GetDescription() != "" {
+			t.Description = req.GetDescription()
+		}
+		if len(req.GetMetadata()) > 0 {
+			t.Metadata = req.GetMetadata()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, channelError(err)
+	}
+
+	if _, err := s.Publish(t.ID, &ct.ChannelMessage{
+		PeerDid:  owner,
+		Did:      t.ID,
+		Metadata: t.Metadata,
+	}); err != nil {
+		return nil, channelError(err)
+	}
+	return &ct.MsgUpdateChannelResponse{
+		Code:    http.StatusOK,
+		Channel: channelDoc(t),
+	}, nil
+}
+
+// DeleteChannel deletes a channel and ends every ListenChannel stream on it.
+func (s *HighwayStub) DeleteChannel(ctx context.Context, req *ct.MsgDeleteChannel) (*ct.MsgDeleteChannelResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, _, err := s.Channels.Topic(req.GetDid())
+	if err != nil {
+		return nil, channelError(err)
+	}
+	if t.Owner != owner {
+		return nil, channelError(ErrNotChannelOwner)
+	}
+	if err := s.Channels.DeleteTopic(t.ID); err != nil {
+		return nil, channelError(err)
+	}
+	return &ct.MsgDeleteChannelResponse{
+		Code:    http.StatusOK,
+		Message: "channel deleted",
+	}, nil
+}
+
+// ListenChannel streams every message published to a channel until the
+// caller cancels or the channel is deleted.
+func (s *HighwayStub) ListenChannel(req *hw.MsgListenChannel, stream hw.Highway_ListenChannelServer) error {
+	sub, err := s.Channels.Subscribe(stream.Context(), req.GetDid())
+	if err != nil {
+		return channelError(err)
+	}
+	defer sub.Unsubscribe()
+
+	for msg := range sub.C {
+		cm, ok := msg.(*ct.ChannelMessage)
+		if !ok {
+			continue
+		}
+		if err := stream.Send(cm); err != nil {
+			return err
+		}
+	}
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// Publish sends msg to every listener of the channel and returns the number
+// of listeners it was delivered to.
+func (s *HighwayStub) Publish(did string, msg *ct.ChannelMessage) (int, error) {
+	return s.Channels.Publish(did, msg)
+}
+
+// channelDoc converts a broker topic to its protobuf representation.
+func channelDoc(t pubsub.Topic) *ct.ChannelDoc {
+	return &ct.ChannelDoc{
+		Label:       t.Label,
+		Did:         t.ID,
+		Description: t.Description,
+	}
+}
+
+// newChannelDid generates a random DID for a new channel.
+func newChannelDid() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return did.Sonr(hex.EncodeToString(buf)).String(), nil
+}
+
+// channelError maps broker errors to gRPC status errors.
+func channelError(err error) error {
+	switch {
+	case errors.Is(err, pubsub.ErrTopicNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, pubsub.ErrTopicExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrNotChannelOwner):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	credpb "github.com/sonr-io/webauthn.io/proto/credentials/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CredentialService serves the Credentials RPCs next to the Highway service.
+// Every call acts on the authenticated caller's own account.
+type CredentialService struct {
+	credpb.UnimplementedCredentialsServer
+
+	// Credentials manages the accounts' credentials
+	Credentials CredentialManager
+}
+
+// CredentialManager lets users manage their own WebAuthn credentials. It is
+// implemented by controller.Controller.
+type CredentialManager interface {
+	FindDid(ctx context.Context, did string) (*User, error)
+	ListCredentials(ctx context.Context, owner *User) ([]Credential, error)
+	RenameCredential(ctx context.Context, owner *User, credentialID string, nickname string) (*Credential, error)
+	RevokeCredential(ctx context.Context, owner *User, credentialID string) error
+}
+
+// ListCredentials lists the WebAuthn credentials of the calling account.
+func (s *CredentialService) ListCredentials(ctx context.Context, req *credpb.MsgListCredentials) (*credpb.MsgListCredentialsResponse, error) {
+	owner, err := s.owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := s.Credentials.ListCredentials(ctx, owner)
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	infos := make([]*credpb.CredentialInfo, len(creds))
+	for i := range creds {
+		infos[i] = credentialInfo(&creds[i])
+	}
+	return &credpb.MsgListCredentialsResponse{
+		Code:        http.StatusOK,
+		Credentials: infos,
+	}, nil
+}
+
+// RenameCredential sets the nickname of one of the calling account's
+// credentials.
+func (s *CredentialService) RenameCredential(ctx context.Context, req *credpb.MsgRenameCredential) (*credpb.MsgRenameCredentialResponse, error) {
+	owner, err := s.owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := s.Credentials.RenameCredential(ctx, owner, req.GetCredentialId(), req.GetNickname())
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	return &credpb.MsgRenameCredentialResponse{
+		Code:       http.StatusOK,
+		Credential: credentialInfo(cred),
+	}, nil
+}
+
+// RevokeCredential deletes one of the calling account's credentials.
+func (s *CredentialService) RevokeCredential(ctx context.Context, req *credpb.MsgRevokeCredential) (*credpb.MsgRevokeCredentialResponse, error) {
+	owner, err := s.owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Credentials.RevokeCredential(ctx, owner, req.GetCredentialId()); err != nil {
+		return nil, credentialError(err)
+	}
+	return &credpb.MsgRevokeCredentialResponse{
+		Code:    http.StatusOK,
+		Message: "credential revoked",
+	}, nil
+}
+
+// owner loads the account of the authenticated caller.
+func (s *CredentialService) owner(ctx context.Context) (*User, error) {
+	did, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.Credentials.FindDid(ctx, did)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return owner, nil
+}
+
+// credentialInfo describes a credential without its public key.
+func credentialInfo(c *Credential) *credpb.CredentialInfo {
+	return &credpb.CredentialInfo{
+		CredentialId:    c.CredentialID,
+		Nickname:        c.Nickname,
+		CreatedAt:       c.CreatedAt.Unix(),
+		Aaguid:          base64.URLEncoding.EncodeToString(c.Authenticator.AAGUID),
+		SignCount:       c.Authenticator.SignCount,
+		Disabled:        c.Disabled,
+		AttestationType: c.Authenticator.AttestationType,
+	}
+}
+
+// credentialError maps credential management errors to gRPC status errors.
+func credentialError(err error) error {
+	switch {
+	case errors.Is(err, ErrCredentialNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidNickname):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrLastCredential):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return internalError(err)
+	}
+}
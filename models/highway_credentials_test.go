@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	credpb "github.com/sonr-io/webauthn.io/proto/credentials/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ownedCredentials is a CredentialManager over fixed users, keyed by DID,
+// that refuses credentials the owner does not have.
+type ownedCredentials map[string]*User
+
+func (o ownedCredentials) FindDid(ctx context.Context, did string) (*User, error) {
+	if u, ok := o[did]; ok {
+		return u, nil
+	}
+	return nil, status.Error(codes.NotFound, "no such DID")
+}
+
+func (o ownedCredentials) ListCredentials(ctx context.Context, owner *User) ([]Credential, error) {
+	return owner.Credentials, nil
+}
+
+func (o ownedCredentials) RenameCredential(ctx context.Context, owner *User, credentialID string, nickname string) (*Credential, error) {
+	for i := range owner.Credentials {
+		if owner.Credentials[i].CredentialID == credentialID {
+			owner.Credentials[i].Nickname = nickname
+			return &owner.Credentials[i], nil
+		}
+	}
+	return nil, ErrCredentialNotFound
+}
+
+func (o ownedCredentials) RevokeCredential(ctx context.Context, owner *User, credentialID string) error {
+	if _, err := o.RenameCredential(ctx, owner, credentialID, ""); err != nil {
+		return err
+	}
+	if len(owner.Credentials) == 1 {
+		return ErrLastCredential
+	}
+	return nil
+}
+
+func TestCredentialService(t *testing.T) {
+	s := &CredentialService{Credentials: ownedCredentials{
+		"did:sonr:alice": {Credentials: []Credential{{CredentialID: "alice-1"}, {CredentialID: "alice-2"}}},
+		"did:sonr:bob":   {Credentials: []Credential{{CredentialID: "bob-1"}}},
+	}}
+	code := func(err error) codes.Code { return status.Code(err) }
+
+	if _, err := s.ListCredentials(context.Background(), &credpb.MsgListCredentials{}); code(err) != codes.Unauthenticated {
+		t.Fatalf("ListCredentials without a caller: %v", err)
+	}
+
+	alice := auth.WithCaller(context.Background(), "did:sonr:alice")
+	list, err := s.ListCredentials(alice, &credpb.MsgListCredentials{})
+	if err != nil || len(list.Credentials) != 2 || list.Credentials[0].CredentialId != "alice-1" {
+		t.Fatalf("ListCredentials = %v, %v", list, err)
+	}
+	renamed, err := s.RenameCredential(alice, &credpb.MsgRenameCredential{CredentialId: "alice-2", Nickname: "laptop"})
+	if err != nil || renamed.Credential.Nickname != "laptop" {
+		t.Fatalf("RenameCredential = %v, %v", renamed, err)
+	}
+
+	// The caller acts on their own account only.
+	if _, err := s.RenameCredential(alice, &credpb.MsgRenameCredential{CredentialId: "bob-1", Nickname: "mine"}); code(err) != codes.NotFound {
+		t.Fatalf("RenameCredential of another account's credential: %v", err)
+	}
+	if _, err := s.RevokeCredential(alice, &credpb.MsgRevokeCredential{CredentialId: "bob-1"}); code(err) != codes.NotFound {
+		t.Fatalf("RevokeCredential of another account's credential: %v", err)
+	}
+
+	bob := auth.WithCaller(context.Background(), "did:sonr:bob")
+	if _, err := s.RevokeCredential(bob, &credpb.MsgRevokeCredential{CredentialId: "bob-1"}); code(err) != codes.FailedPrecondition {
+		t.Fatalf("RevokeCredential of the last credential: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sonr-io/webauthn.io/pkg/did"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ParseDid parses a DID URL. The response Did field holds the parsed
+// components as JSON.
+func (s *HighwayStub) ParseDid(ctx context.Context, req *hw.MsgParseDid) (*hw.MsgParseDidResponse, error) {
+	d, err := did.Parse(req.GetDidString())
+	if err != nil {
+		return nil, didError(err)
+	}
+	buf, err := json.Marshal(d)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &hw.MsgParseDidResponse{
+		Code:    http.StatusOK,
+		Message: d.String(),
+		Did:     string(buf),
+	}, nil
+}
+
+// ResolveDid returns the W3C DID document of a did:sonr identifier as JSON.
+func (s *HighwayStub) ResolveDid(ctx context.Context, req *hw.MsgResolveDid) (*hw.MsgResolveDidResponse, error) {
+	doc, err := s.Dids.ResolveDid(ctx, req.GetDidString())
+	if err != nil {
+		return nil, didError(err)
+	}
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &hw.MsgResolveDidResponse{
+		Code:        http.StatusOK,
+		Message:     doc.ID,
+		DidDocument: string(buf),
+	}, nil
+}
+
+// didError maps DID parsing and resolution errors to gRPC status errors.
+func didError(err error) error {
+	switch {
+	case errors.Is(err, did.ErrInvalidDid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, did.ErrUnsupportedMethod):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, did.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return internalError(err)
+	}
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	docpb "github.com/sonr-io/webauthn.io/proto/documents/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DocumentService serves the Documents RPCs next to the Highway service.
+// Like the bucket and object RPCs, every call acts on the authenticated
+// caller's own namespace.
+type DocumentService struct {
+	docpb.UnimplementedDocumentsServer
+
+	// Documents is the store shared with the bucket and object RPCs
+	Documents *docstore.Store
+}
+
+// PutDocument validates a document against its object schema and stores it
+// in one of the caller's buckets.
+func (s *DocumentService) PutDocument(ctx context.Context, req *docpb.MsgPutDocument) (*docpb.MsgPutDocumentResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if req.GetValues() != "" {
+		if err := json.Unmarshal([]byte(req.GetValues()), &values); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "values must be a JSON object: %s", err)
+		}
+	}
+	d, err := s.Documents.PutDocument(owner, docstore.Document{
+		ID:        req.GetId(),
+		BucketDid: req.GetBucketDid(),
+		ObjectDid: req.GetObjectDid(),
+		Values:    values,
+	})
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	info, err := documentInfo(d)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return &docpb.MsgPutDocumentResponse{
+		Code:     http.StatusOK,
+		Message:  "document stored",
+		Document: info,
+	}, nil
+}
+
+// GetDocument returns a document from one of the caller's buckets.
+func (s *DocumentService) GetDocument(ctx context.Context, req *docpb.MsgGetDocument) (*docpb.MsgGetDocumentResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.Documents.Document(owner, req.GetBucketDid(), req.GetId())
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	info, err := documentInfo(d)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return &docpb.MsgGetDocumentResponse{
+		Code:     http.StatusOK,
+		Document: info,
+	}, nil
+}
+
+// ListDocuments lists the documents in one of the caller's buckets.
+func (s *DocumentService) ListDocuments(ctx context.Context, req *docpb.MsgListDocuments) (*docpb.MsgListDocumentsResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.Documents.Documents(owner, req.GetBucketDid())
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	infos := make([]*docpb.DocumentInfo, len(docs))
+	for i, d := range docs {
+		if infos[i], err = documentInfo(d); err != nil {
+			return nil, internalError(err)
+		}
+	}
+	return &docpb.MsgListDocumentsResponse{
+		Code:      http.StatusOK,
+		Documents: infos,
+	}, nil
+}
+
+// documentInfo describes a document with its values encoded as JSON.
+func documentInfo(d docstore.Document) (*docpb.DocumentInfo, error) {
+	values, err := json.Marshal(d.Values)
+	if err != nil {
+		return nil, err
+	}
+	return &docpb.DocumentInfo{
+		Id:        d.ID,
+		BucketDid: d.BucketDid,
+		ObjectDid: d.ObjectDid,
+		Values:    string(values),
+		CreatedAt: d.CreatedAt.Unix(),
+		UpdatedAt: d.UpdatedAt.Unix(),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	docpb "github.com/sonr-io/webauthn.io/proto/documents/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDocumentService(t *testing.T) {
+	store, err := docstore.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := store.CreateObject("did:sonr:alice", docstore.Object{
+		Label:  "note",
+		Fields: []docstore.Field{{Name: "text", Kind: docstore.KindString}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := store.CreateBucket("did:sonr:alice", docstore.Bucket{Label: "notes", ObjectDids: []string{o.Did}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &DocumentService{Documents: store}
+	code := func(err error) codes.Code { return status.Code(err) }
+	put := &docpb.MsgPutDocument{BucketDid: b.Did, ObjectDid: o.Did, Values: `{"text": "hello"}`}
+
+	if _, err := s.PutDocument(context.Background(), put); code(err) != codes.Unauthenticated {
+		t.Fatalf("PutDocument without a caller: %v", err)
+	}
+
+	alice := auth.WithCaller(context.Background(), "did:sonr:alice")
+	stored, err := s.PutDocument(alice, put)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetDocument(alice, &docpb.MsgGetDocument{BucketDid: b.Did, Id: stored.Document.Id})
+	if err != nil || got.Document.Values != `{"text":"hello"}` {
+		t.Fatalf("GetDocument = %v, %v", got, err)
+	}
+	list, err := s.ListDocuments(alice, &docpb.MsgListDocuments{BucketDid: b.Did})
+	if err != nil || len(list.Documents) != 1 || list.Documents[0].Id != stored.Document.Id {
+		t.Fatalf("ListDocuments = %v, %v", list, err)
+	}
+
+	bad := &docpb.MsgPutDocument{BucketDid: b.Did, ObjectDid: o.Did, Values: `{"text": 1}`}
+	if _, err := s.PutDocument(alice, bad); code(err) != codes.InvalidArgument {
+		t.Fatalf("PutDocument of a document off its schema: %v", err)
+	}
+
+	// The owner is the caller, so another account cannot see alice's bucket
+	// even with its DID.
+	bob := auth.WithCaller(context.Background(), "did:sonr:bob")
+	if _, err := s.PutDocument(bob, put); code(err) != codes.NotFound {
+		t.Fatalf("PutDocument into another account's bucket: %v", err)
+	}
+	if _, err := s.GetDocument(bob, &docpb.MsgGetDocument{BucketDid: b.Did, Id: stored.Document.Id}); code(err) != codes.NotFound {
+		t.Fatalf("GetDocument from another account's bucket: %v", err)
+	}
+	if _, err := s.ListDocuments(bob, &docpb.MsgListDocuments{BucketDid: b.Did}); code(err) != codes.NotFound {
+		t.Fatalf("ListDocuments of another account's bucket: %v", err)
+	}
+}
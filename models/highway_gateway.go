@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/sonr-io/webauthn.io/pkg/gateway"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+)
+
+// GatewayPrefix is the HTTP path the REST gateway is mounted under.
+const GatewayPrefix = "/api/v1"
+
+// highwayRules route the Highway RPCs over HTTP. They are kept here rather
+// than read from the google.api.http options of the published service,
+// whose CheckName shares AccessName's path and whose POST and PUT methods
+// take no body. Path variables use the request messages' proto field names.
+var highwayRules = []gateway.Rule{
+	{Method: "AccessName", Verb: "GET", Path: "/access/name/{name}"},
+	{Method: "CheckName", Verb: "GET", Path: "/check/name/{nameToRegister}"},
+	{Method: "GenerateCreds", Verb: "GET", Path: "/register/token"},
+	{Method: "RegisterName", Verb: "POST", Path: "/register/name", Body: "*"},
+	{Method: "UpdateName", Verb: "PUT", Path: "/update/name/{did}", Body: "*"},
+	{Method: "AccessService", Verb: "GET", Path: "/access/service/{did}"},
+	{Method: "RegisterService", Verb: "POST", Path: "/register/service", Body: "*"},
+	{Method: "UpdateService", Verb: "PUT", Path: "/update/service/{did}", Body: "*"},
+	{Method: "CreateChannel", Verb: "POST", Path: "/create/channel", Body: "*"},
+	{Method: "ReadChannel", Verb: "GET", Path: "/read/channel/{did}"},
+	{Method: "UpdateChannel", Verb: "PUT", Path: "/update/channel/{did}", Body: "*"},
+	{Method: "DeleteChannel", Verb: "DELETE", Path: "/delete/channel/{did}"},
+	{Method: "ListenChannel", Verb: "POST", Path: "/listen/channel/{did}", Body: "*"},
+	{Method: "CreateBucket", Verb: "POST", Path: "/create/bucket", Body: "*"},
+	{Method: "ReadBucket", Verb: "GET", Path: "/read/bucket/{did}"},
+	{Method: "UpdateBucket", Verb: "PUT", Path: "/update/bucket/{did}", Body: "*"},
+	{Method: "DeleteBucket", Verb: "DELETE", Path: "/delete/bucket/{did}"},
+	{Method: "CreateObject", Verb: "POST", Path: "/create/object", Body: "*"},
+	{Method: "ReadObject", Verb: "GET", Path: "/read/object/{did}"},
+	{Method: "UpdateObject", Verb: "PUT", Path: "/update/object/{did}", Body: "*"},
+	{Method: "DeleteObject", Verb: "DELETE", Path: "/delete/object/{did}"},
+	{Method: "UploadBlob", Verb: "POST", Path: "/upload/blob", Body: "*"},
+	{Method: "DownloadBlob", Verb: "GET", Path: "/download/blob/{did}"},
+	{Method: "SyncBlob", Verb: "PUT", Path: "/sync/blob/{did}", Body: "*"},
+	{Method: "DeleteBlob", Verb: "DELETE", Path: "/delete/blob/{did}"},
+	{Method: "ParseDid", Verb: "GET", Path: "/did/parse/{did_string}"},
+	{Method: "ResolveDid", Verb: "POST", Path: "/resolve/did/{did_string}", Body: "*"},
+}
+
+// Gateway returns the REST gateway for the stub's RPCs. Pass the interceptors
+// installed on the gRPC server so both transports authenticate and fail alike.
+func (s *HighwayStub) Gateway(opts ...gateway.Option) (*gateway.Gateway, error) {
+	return gateway.New(&hw.Highway_ServiceDesc, s, highwayRules, opts...)
+}
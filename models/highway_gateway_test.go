@@ -0,0 +1,86 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sonr-io/webauthn.io/pkg/gateway"
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
+)
+
+// routeServer answers with the fields each request arrived with.
+type routeServer struct {
+	hw.UnimplementedHighwayServer
+}
+
+func (routeServer) AccessName(ctx context.Context, in *hw.MsgAccessName) (*hw.MsgAccessNameResponse, error) {
+	return &hw.MsgAccessNameResponse{Message: "access " + in.GetName()}, nil
+}
+
+func (routeServer) CheckName(ctx context.Context, in *hw.MsgCheckName) (*hw.MsgCheckNameResponse, error) {
+	return &hw.MsgCheckNameResponse{NameAvailable: in.GetNameToRegister() == "free"}, nil
+}
+
+func (routeServer) RegisterName(ctx context.Context, in *rt.MsgRegisterName) (*rt.MsgRegisterNameResponse, error) {
+	return &rt.MsgRegisterNameResponse{IsSuccess: in.GetCreator() == "alice", DidUrl: in.GetNameToRegister()}, nil
+}
+
+func (routeServer) UpdateName(ctx context.Context, in *rt.MsgUpdateName) (*rt.MsgUpdateNameResponse, error) {
+	metadata := map[string]string{"did": in.GetDid()}
+	for k, v := range in.GetMetadata() {
+		metadata[k] = v
+	}
+	return &rt.MsgUpdateNameResponse{Metadata: metadata}, nil
+}
+
+// call sends a request through the Highway gateway and decodes its JSON
+// response.
+func call(t *testing.T, method string, path string, body io.Reader) map[string]interface{} {
+	t.Helper()
+	g, err := gateway.New(&hw.Highway_ServiceDesc, routeServer{}, highwayRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(method, path, body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s = %d: %s", method, path, w.Code, w.Body)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestHighwayGatewayNameRoutes(t *testing.T) {
+	if resp := call(t, "GET", "/access/name/alice", nil); resp["message"] != "access alice" {
+		t.Errorf("AccessName = %v", resp)
+	}
+	if resp := call(t, "GET", "/check/name/free", nil); resp["nameAvailable"] != true {
+		t.Errorf("CheckName(free) = %v", resp)
+	}
+	if resp := call(t, "GET", "/check/name/taken", nil); resp["nameAvailable"] != false {
+		t.Errorf("CheckName(taken) = %v", resp)
+	}
+}
+
+func TestHighwayGatewayBodies(t *testing.T) {
+	resp := call(t, "POST", "/register/name", strings.NewReader(`{"creator": "alice", "nameToRegister": "alice"}`))
+	if resp["is_success"] != true || resp["did_url"] != "alice" {
+		t.Errorf("RegisterName = %v", resp)
+	}
+
+	// The path names the DID; the rest of the request is the body.
+	resp = call(t, "PUT", "/update/name/did:sonr:alice", strings.NewReader(`{"metadata": {"label": "Alice"}}`))
+	metadata, _ := resp["metadata"].(map[string]interface{})
+	if metadata["did"] != "did:sonr:alice" || metadata["label"] != "Alice" {
+		t.Errorf("UpdateName = %v", resp)
+	}
+}
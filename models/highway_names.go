@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
+	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AccessName returns the publicly available details of a registered name.
+func (s *HighwayStub) AccessName(ctx context.Context, req *hw.MsgAccessName) (*hw.MsgAccessNameResponse, error) {
+	name := TrimName(req.GetName())
+	if err := ValidateName(name); err != nil {
+		return nil, nameError(err)
+	}
+
+	user, err := s.Names.FindUserByName(ctx, name)
+	if err != nil {
+		return nil, nameError(err)
+	}
+	if user.Did == "" {
+		return nil, nameError(ErrNameNotFound)
+	}
+	return &hw.MsgAccessNameResponse{
+		Code: http.StatusOK,
+		Peer: &rt.Peer{
+			Did: user.Did,
+		},
+	}, nil
+}
+
+// CheckName reports whether a name is available to be registered.
+func (s *HighwayStub) CheckName(ctx context.Context, req *hw.MsgCheckName) (*hw.MsgCheckNameResponse, error) {
+	name := TrimName(req.GetNameToRegister())
+	if err := ValidateName(name); err != nil {
+		return nil, nameError(err)
+	}
+
+	available, err := s.Names.NameAvailable(ctx, name)
+	if err != nil {
+		return nil, nameError(err)
+	}
+	return &hw.MsgCheckNameResponse{NameAvailable: available}, nil
+}
+
+// RegisterName registers a name on chain for the user that claimed it
+// through the WebAuthn registration ceremony.
+func (s *HighwayStub) RegisterName(ctx context.Context, req *rt.MsgRegisterName) (*rt.MsgRegisterNameResponse, error) {
+	name := TrimName(req.GetNameToRegister())
+	if err := ValidateName(name); err != nil {
+		return nil, nameError(err)
+	}
+	if IsReservedName(name) {
+		return nil, nameError(ErrNameReserved)
+	}
+
+	caller, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.Names.FindUserByName(ctx, name)
+	if err != nil {
+		return nil, nameError(err)
+	}
+	if user.Did == "" {
+		return nil, nameError(ErrNameNotFound)
+	}
+	if user.Did != caller {
+		return nil, nameError(ErrNotNameOwner)
+	}
+
+	resp, err := s.Names.RegisterName(ctx, &rt.MsgRegisterName{
+		Creator:        req.GetCreator(),
+		NameToRegister: name,
+	}, user.Did, nil)
+	if err != nil {
+		return nil, nameError(err)
+	}
+	return resp, nil
+}
+
+// UpdateName updates the public details attached to a registered DID.
+func (s *HighwayStub) UpdateName(ctx context.Context, req *rt.MsgUpdateName) (*rt.MsgUpdateNameResponse, error) {
+	if req.GetDid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "did is required")
+	}
+	caller, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDid() != caller {
+		return nil, nameError(ErrNotNameOwner)
+	}
+
+	resp, err := s.Names.UpdateName(ctx, req)
+	if err != nil {
+		return nil, nameError(err)
+	}
+	return resp, nil
+}
+
+// nameError maps name service errors to gRPC status errors.
+func nameError(err error) error {
+	switch {
+	case errors.Is(err, ErrNameTooShort), errors.Is(err, ErrNameNotAlphanumeric):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrNameReserved), errors.Is(err, ErrNotNameOwner):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrNameNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrUsernameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return internalError(err)
+	}
+}
+
+// internalError reports err as codes.Internal unless it, or an error it
+// wraps, carries its own gRPC status. Store errors do, so a missing record
+// or an unreachable database keeps its meaning.
+func internalError(err error) error {
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return status.Error(se.GRPCStatus().Code(), err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	ot "go.buf.build/grpc/go/sonr-io/sonr/object"
+)
+
+// fieldKinds maps protobuf field types to document store kinds.
+var fieldKinds = map[ot.ObjectFieldType]docstore.Kind{
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_STRING:    docstore.KindString,
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_NUMBER:    docstore.KindNumber,
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_BOOL:      docstore.KindBool,
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_ARRAY:     docstore.KindArray,
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_TIMESTAMP: docstore.KindTimestamp,
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_GEOPOINT:  docstore.KindGeopoint,
+	ot.ObjectFieldType_OBJECT_FIELD_TYPE_BLOB:      docstore.KindBlob,
+}
+
+// CreateObject creates an object schema owned by the caller.
+func (s *HighwayStub) CreateObject(ctx context.Context, req *ot.MsgCreateObject) (*ot.MsgCreateObjectResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := schemaFields(req.GetInitialFields())
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	o, err := s.Documents.CreateObject(owner, docstore.Object{
+		Label:       req.GetLabel(),
+		Description: req.GetDescription(),
+		Fields:      fields,
+	})
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	return &ot.MsgCreateObjectResponse{
+		Code:    http.StatusOK,
+		Message: "object created",
+		WhichIs: objectDoc(o),
+	}, nil
+}
+
+// ReadObject returns one of the caller's object schemas.
+func (s *HighwayStub) ReadObject(ctx context.Context, req *ot.MsgReadObject) (*ot.MsgReadObjectResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o, err := s.Documents.Object(owner, req.GetDid())
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	return &ot.MsgReadObjectResponse{
+		Code:   http.StatusOK,
+		Object: objectDoc(o),
+	}, nil
+}
+
+// UpdateObject relabels an object schema and adds or removes fields. Removed
+// fields are matched by name.
+func (s *HighwayStub) UpdateObject(ctx context.Context, req *ot.MsgUpdateObject) (*ot.MsgUpdateObjectResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	added, err := schemaFields(req.GetAddedFields())
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	o, err := s.Documents.UpdateObject(owner, req.GetDid(), func(o *docstore.Object) error {
+		if req.GetLabel() != "" {
+			o.Label = req.GetLabel()
+		}
+		removed := make(map[string]bool, len(req.GetRemovedFields()))
+		for _, f := range req.GetRemovedFields() {
+			removed[f.GetName()] = true
+		}
+		kept := o.Fields[:0]
+		for _, f := range o.Fields {
+			if !removed[f.Name] {
+				kept = append(kept, f)
+			}
+		}
+		o.Fields = append(kept, added...)
+		return nil
+	})
+	if err != nil {
+		return nil, docstoreError(err)
+	}
+	return &ot.MsgUpdateObjectResponse{
+		Code:   http.StatusOK,
+		Object: objectDoc(o),
+	}, nil
+}
+
+// DeleteObject deletes an object schema that is not attached to any bucket.
+func (s *HighwayStub) DeleteObject(ctx context.Context, req *ot.MsgDeleteObject) (*ot.MsgDeleteObjectResponse, error) {
+	owner, err := callerDid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Documents.DeleteObject(owner, req.GetDid()); err != nil {
+		return nil, docstoreError(err)
+	}
+	return &ot.MsgDeleteObjectResponse{
+		Code:    http.StatusOK,
+		Message: "object deleted",
+	}, nil
+}
+
+// schemaFields converts protobuf type fields to document store fields.
+func schemaFields(fields []*ot.TypeField) ([]docstore.Field, error) {
+	out := make([]docstore.Field, 0, len(fields))
+	for _, f := range fields {
+		kind, ok := fieldKinds[f.GetKind()]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %q has unsupported type %d", docstore.ErrInvalidSchema, f.GetName(), f.GetKind())
+		}
+		out = append(out, docstore.Field{Name: f.GetName(), Kind: kind})
+	}
+	return out, nil
+}
+
+// objectDoc converts a stored object schema to its protobuf representation.
+func objectDoc(o docstore.Object) *ot.ObjectDoc {
+	doc := &ot.ObjectDoc{
+		Label:       o.Label,
+		Description: o.Description,
+		Did:         o.Did,
+		Fields:      make(map[string]*ot.ObjectField, len(o.Fields)),
+	}
+	for _, f := range o.Fields {
+		field := &ot.ObjectField{Label: f.Name}
+		for t, k := range fieldKinds {
+			if k == f.Kind {
+				field.Type = t
+			}
+		}
+		doc.Fields[f.Name] = field
+	}
+	return doc
+}
@@ -5,9 +5,14 @@ import (
 	"net/http"
 
 	"github.com/sonr-io/sonr/pkg/p2p"
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/pkg/blob"
+	"github.com/sonr-io/webauthn.io/pkg/did"
+	"github.com/sonr-io/webauthn.io/pkg/docstore"
+	"github.com/sonr-io/webauthn.io/pkg/pubsub"
 	"github.com/tendermint/starport/starport/pkg/cosmosclient"
 	hw "go.buf.build/grpc/go/sonr-io/highway/v1"
-	"go.buf.build/grpc/go/sonr-io/sonr/channel"
+	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
 
 	"google.golang.org/grpc"
 )
@@ -24,9 +29,38 @@ type HighwayStub struct {
 	Http *http.Server
 
 	// Configuration
+	Names NameService
+	Dids  DidResolver
 
-	// List of Entries
-	Channels map[string]channel.Channel
+	// Channels is the broker behind the channel RPCs
+	Channels *pubsub.Broker
+
+	// Blobs is the store behind the blob RPCs
+	Blobs blob.BlobStore
+
+	// Documents is the store behind the bucket and object RPCs
+	Documents *docstore.Store
+
+	// Workspace holds a directory per caller that the caller's blob upload
+	// and download paths are resolved against. No store may keep its own
+	// files beneath it.
+	Workspace config.Folder
+}
+
+// NameService is the name registry behind the name RPCs. It is implemented by
+// controller.Controller so that Motor nodes get the same name semantics over
+// gRPC as the browser gets over HTTP.
+type NameService interface {
+	NameAvailable(ctx context.Context, name string) (bool, error)
+	FindUserByName(ctx context.Context, name string) (*User, error)
+	RegisterName(ctx context.Context, req *rt.MsgRegisterName, did string, cred *Credential) (*rt.MsgRegisterNameResponse, error)
+	UpdateName(ctx context.Context, req *rt.MsgUpdateName) (*rt.MsgUpdateNameResponse, error)
+}
+
+// DidResolver resolves DIDs to their DID documents. It is implemented by
+// controller.Controller.
+type DidResolver interface {
+	ResolveDid(ctx context.Context, id string) (*did.Document, error)
 }
 
 //get
@@ -16,6 +16,26 @@ var err error
 // ErrUsernameTaken is thrown when a user attempts to register a username that is taken.
 var ErrUsernameTaken = errors.New("username already taken")
 
+// ErrCredentialCloned occurs when an authenticator provides a sign count
+// during assertion that is lower than the previously recorded sign count, as
+// this would indicate that the authenticator may have been cloned.
+var ErrCredentialCloned = errors.New("credential appears to have been cloned")
+
+// ErrCredentialDisabled is returned when a login uses a credential that was
+// disabled. The user has to register a new one.
+var ErrCredentialDisabled = errors.New("credential has been disabled, register a new one")
+
+// ErrCredentialNotFound is returned when a user manages a credential they do
+// not own. Credentials of other users are reported the same as missing ones.
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// ErrInvalidNickname is returned when a credential nickname is too long.
+var ErrInvalidNickname = fmt.Errorf("nickname must be at most %d characters", MaxNicknameLength)
+
+// ErrLastCredential is returned when revoking a user's only usable credential
+// would lock them out of their account.
+var ErrLastCredential = errors.New("cannot revoke the last credential without a recovery method")
+
 // Copy of auth.GenerateSecureKey to prevent cyclic import with auth library
 func generateSecureKey() string {
 	k := make([]byte, 32)
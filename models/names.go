@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// NameSuffix is the top level domain appended to every registered name.
+const NameSuffix = ".snr"
+
+// Name validation errors shared by the HTTP handlers and the gRPC service.
+var (
+	ErrNameTooShort        = errors.New("name too short")
+	ErrNameNotAlphanumeric = errors.New("name not alphanumeric")
+	ErrNameReserved        = errors.New("name is reserved")
+	ErrNameNotFound        = errors.New("name is not registered")
+	ErrNotNameOwner        = errors.New("caller does not own the name")
+)
+
+var nameRegexp = regexp.MustCompile("^[a-zA-Z0-9_]*$")
+
+// reservedNames cannot be registered by anyone. Comparison is case-insensitive.
+var reservedNames = []string{"api", "tx", "app", "arianagrande", "azamsharp", "barrybonds", "barrysanders", "billgates", "britneyspears", "cdixon", "cristiano", "drake", "elon", "eminem", "flotus", "iamsrk", "imap", "index", "jack", "jbbernstein", "jeffbezos", "jimmyfallon", "joynerlucas", "jtimberlake", "justinbieber", "katyperry", "kimkardashian", "kingjames", "ladygaga", "larrypage", "launchhouse", "logic", "mail", "main", "markzuckerburg", "meekmill", "naval", "neymarjr", "oprah", "patrickbetdavid", "pop", "potus", "prad", "rihanna", "root", "satyanadella", "sc", "selenagomez", "sergeibrin", "shakira", "shl", "smartrick", "srbachchan", "stephencurry", "sundarpichai", "taylorswift", "tombrady", "vitalik", "michael", "prad2", "papa", "ikj", "ian", "shadowysupercoder", "ianperez", "perez", "0x0", "zac", "smartrick", "holwerda", "zholwerda", "NFT", "classof.o7", "goat", "nsfw", "nick", "ntindle", "nicktindle", "cloud", "devops", "engineer", "ntt", "grace", "get", "gtindle", "0xDEADBEEF", "static", "d0x", "null", "exposure", "zach", "joshLong145", "beanPole", "undefined", "Peyton", "gopher", "cosmic", "lauren", "sonr", "prad", "letsgobrandon", "snr", "erin", "jamey", "monica", "Space", "timmy", "creaton", "Warriors", "BestButt", "Mfers", "Beast", "mary", "david", "RX", "NT", "0X", "OK", "NO", "SN", "GB", "GT", "IP", "AH", "PT", "JL", "AF", "0F", "0p", "00", "C0", "80", "snr", "sonr", "xxxtentacion", "yasht", "teksupport", "luffy", "yeah"}
+
+// TrimName strips the ".snr" suffix from a name if present.
+func TrimName(name string) string {
+	return strings.TrimSuffix(name, NameSuffix)
+}
+
+// IsReservedName reports whether name is on the reserved list.
+func IsReservedName(name string) bool {
+	for _, x := range reservedNames {
+		if strings.EqualFold(x, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateName checks the length and character restrictions for a name. The
+// name must already be trimmed.
+func ValidateName(name string) error {
+	if len(name) < 2 {
+		return ErrNameTooShort
+	}
+	if !nameRegexp.MatchString(name) {
+		return ErrNameNotAlphanumeric
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// RecoveryCodeCount is the number of recovery codes issued at a time.
+	RecoveryCodeCount = 10
+
+	// recoveryCodeLength is the number of base32 characters in a code, 60
+	// random bits. Codes are too strong to guess offline, so a plain SHA-256
+	// is enough to store them.
+	recoveryCodeLength = 12
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidRecoveryCode is returned when a recovery code is unknown, already
+// used, or given for an account that does not exist.
+var ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+
+// ErrRecoveryLocked is returned when an account saw too many failed recovery
+// attempts recently.
+var ErrRecoveryLocked = errors.New("too many failed recovery attempts, try again later")
+
+// ErrRecoveryExpired is returned for a recovery grant that is unknown, was
+// already used to enroll a credential, or has timed out.
+var ErrRecoveryExpired = errors.New("recovery session expired, start again with another recovery code")
+
+// RecoveryGrant lets the browser holding its token enroll one credential for
+// a user who gave a valid recovery code. It does not log the user in. As
+// with a Session, the browser holds the token in a cookie and only its hash
+// is stored; the grant is deleted when the credential is enrolled.
+type RecoveryGrant struct {
+	gorm.Model
+	TokenHash string    `json:"-" gorm:"unique_index"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Expires   time.Time `json:"expires"`
+}
+
+// Active reports whether the grant has not timed out.
+func (g RecoveryGrant) Active(now time.Time) bool {
+	return now.Before(g.Expires)
+}
+
+// RecoveryCodes holds the SHA-256 hashes of a user's unused recovery codes.
+// SQL backends store it as a JSON column.
+type RecoveryCodes []string
+
+// Value implements driver.Valuer.
+func (c RecoveryCodes) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return "", nil
+	}
+	buf, err := json.Marshal([]string(c))
+	return string(buf), err
+}
+
+// Scan implements sql.Scanner.
+func (c *RecoveryCodes) Scan(src interface{}) error {
+	var buf []byte
+	switch v := src.(type) {
+	case nil:
+	case string:
+		buf = []byte(v)
+	case []byte:
+		buf = v
+	default:
+		return fmt.Errorf("cannot scan %T into RecoveryCodes", src)
+	}
+	*c = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	return json.Unmarshal(buf, (*[]string)(c))
+}
+
+// Without returns the hashes other than hash.
+func (c RecoveryCodes) Without(hash string) RecoveryCodes {
+	rest := RecoveryCodes{}
+	for _, h := range c {
+		if h != hash {
+			rest = append(rest, h)
+		}
+	}
+	return rest
+}
+
+// NewRecoveryCodes generates n recovery codes. The codes are shown to the
+// user once; only their hashes are kept.
+func NewRecoveryCodes(n int) ([]string, RecoveryCodes, error) {
+	codes := make([]string, n)
+	hashes := make(RecoveryCodes, n)
+	for i := range codes {
+		buf := make([]byte, recoveryEncoding.DecodedLen(recoveryCodeLength)+1)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(buf)[:recoveryCodeLength])
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		hashes[i] = HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the stored form of code. Case, spaces and dashes
+// are ignored, so a code can be typed back however it was written down.
+func HashRecoveryCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
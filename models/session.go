@@ -0,0 +1,102 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// sessionTokenLength is the number of random bytes in a session token.
+const sessionTokenLength = 32
+
+// ErrSessionExpired is returned for a session token that is unknown, was
+// signed out, or has passed its idle or absolute timeout.
+var ErrSessionExpired = errors.New("session expired, sign in again")
+
+// ErrSessionNotFound is returned when a user ends a session that is not
+// theirs. Sessions of other users are reported the same as missing ones.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is a login of a user in one browser. The browser holds a random
+// token in a cookie; only the token's hash is stored, so a copy of the
+// database cannot be used to take over sessions.
+type Session struct {
+	gorm.Model
+	TokenHash string `json:"-" gorm:"unique_index"`
+	UserID    uint   `json:"user_id" gorm:"index"`
+	// CredentialID is the credential the user logged in with.
+	CredentialID string    `json:"credential_id,omitempty"`
+	UserAgent    string    `json:"user_agent"`
+	Device       string    `json:"device"`
+	IP           string    `json:"ip"`
+	LastSeen     time.Time `json:"last_seen"`
+	// IdleExpires moves forward while the session is used; Expires is
+	// fixed when the session starts.
+	IdleExpires time.Time `json:"idle_expires"`
+	Expires     time.Time `json:"expires"`
+}
+
+// Active reports whether the session has passed neither of its timeouts.
+func (s Session) Active(now time.Time) bool {
+	return now.Before(s.IdleExpires) && now.Before(s.Expires)
+}
+
+// NewSessionToken returns a random session token and its hash.
+func NewSessionToken() (string, string, error) {
+	buf := make([]byte, sessionTokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashSessionToken(token), nil
+}
+
+// HashSessionToken returns the stored form of a session token.
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// browsers and platforms are matched against a user agent in order; the
+// first match names it. Edge and Opera mention Chrome, and Chrome mentions
+// Safari, so they come first.
+var (
+	browsers = [][2]string{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"}, {"Chrome/", "Chrome"}, {"Safari/", "Safari"},
+	}
+	platforms = [][2]string{
+		{"iPhone", "iPhone"}, {"iPad", "iPad"}, {"Android", "Android"}, {"CrOS", "ChromeOS"},
+		{"Windows", "Windows"}, {"Mac OS X", "macOS"}, {"Linux", "Linux"},
+	}
+)
+
+// DescribeUserAgent names the browser and platform of a user agent, such as
+// "Firefox on Windows", for listing sessions.
+func DescribeUserAgent(userAgent string) string {
+	match := func(table [][2]string) string {
+		for _, m := range table {
+			if strings.Contains(userAgent, m[0]) {
+				return m[1]
+			}
+		}
+		return ""
+	}
+	browser, platform := match(browsers), match(platforms)
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
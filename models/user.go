@@ -1,10 +1,12 @@
 package models
 
 import (
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/binary"
 	"time"
 
+	"github.com/duo-labs/webauthn/protocol"
 	"github.com/duo-labs/webauthn/webauthn"
 	"github.com/jinzhu/gorm"
 )
@@ -19,8 +21,8 @@ const PlaceholderUserIcon = "example.icon.duo.com/123/avatar.png"
 type User struct {
 	gorm.Model
 	Did         string
-	Jwt         Jwt
-	Names       []string
+	Jwt         Jwt          `gorm:"embedded;embedded_prefix:jwt_"`
+	Names       []string     `gorm:"-"`
 	Username    string       `json:"name" sql:"not null;"`
 	DisplayName string       `json:"display_name"`
 	Icon        string       `json:"icon,omitempty"`
@@ -28,6 +30,26 @@ type User struct {
 	Paid        bool         `json:"paid"`
 	PiID        string       `json:"piid"`
 	Created     time.Time    `json:"created"`
+	// RecoveryCodes are the hashes of the user's unused recovery codes.
+	// They are changed only through the store's recovery code methods, so a
+	// used code cannot be written back.
+	RecoveryCodes RecoveryCodes `json:"-" gorm:"type:text"`
+}
+
+// NewUserID returns a random ID for a user whose registration has begun.
+// The ID is chosen before the user is stored, since it is the credential's
+// user handle, so it is drawn from 63 random bits rather than a counter to
+// keep it from colliding with a stored user. It fits a signed 64-bit column.
+func NewUserID() (uint, error) {
+	buf := make([]byte, 8)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return 0, err
+		}
+		if id := uint(binary.BigEndian.Uint64(buf) >> 1); id != 0 {
+			return id, nil
+		}
+	}
 }
 
 // WebAuthnID returns the user ID as a byte slice
@@ -52,18 +74,41 @@ func (u User) WebAuthnIcon() string {
 	return u.Icon
 }
 
+// CredentialExcludeList returns descriptors of the user's credentials, to
+// stop an authenticator that already holds one from registering again.
+func (u User) CredentialExcludeList() []protocol.CredentialDescriptor {
+	credentials := u.WebAuthnCredentials()
+	excludeList := make([]protocol.CredentialDescriptor, len(credentials))
+	for i, cred := range credentials {
+		excludeList[i] = protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: cred.ID,
+		}
+	}
+	return excludeList
+}
+
+// HasRecovery reports whether the user can regain access to their account
+// without a credential, which takes an unused recovery code.
+func (u User) HasRecovery() bool {
+	return len(u.RecoveryCodes) > 0
+}
+
 // WebAuthnCredentials helps implement the webauthn.User interface by loading
-// the user's credentials from the underlying database.
+// the user's credentials from the underlying database. Disabled credentials
+// are left out, so they cannot complete a login.
 func (u User) WebAuthnCredentials() []webauthn.Credential {
-	credentials := u.Credentials
-	wcs := make([]webauthn.Credential, len(credentials))
-	for i, cred := range credentials {
+	wcs := make([]webauthn.Credential, 0, len(u.Credentials))
+	for _, cred := range u.Credentials {
+		if cred.Disabled {
+			continue
+		}
 		credentialID, _ := base64.URLEncoding.DecodeString(cred.CredentialID)
-		wcs[i] = webauthn.Credential{
+		wcs = append(wcs, webauthn.Credential{
 			ID:            credentialID,
 			PublicKey:     cred.PublicKey,
 			Authenticator: cred.WebauthnAuthenticator(),
-		}
+		})
 	}
 	return wcs
 }
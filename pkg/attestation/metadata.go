@@ -0,0 +1,316 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status report values of the FIDO Metadata Service that change how an
+// authenticator model is trusted.
+const (
+	StatusFidoCertified             = "FIDO_CERTIFIED"
+	StatusFidoCertifiedL1           = "FIDO_CERTIFIED_L1"
+	StatusFidoCertifiedL1Plus       = "FIDO_CERTIFIED_L1plus"
+	StatusFidoCertifiedL2           = "FIDO_CERTIFIED_L2"
+	StatusFidoCertifiedL2Plus       = "FIDO_CERTIFIED_L2plus"
+	StatusFidoCertifiedL3           = "FIDO_CERTIFIED_L3"
+	StatusFidoCertifiedL3Plus       = "FIDO_CERTIFIED_L3plus"
+	StatusRevoked                   = "REVOKED"
+	StatusAttestationKeyCompromise  = "ATTESTATION_KEY_COMPROMISE"
+	StatusUserVerificationBypass    = "USER_VERIFICATION_BYPASS"
+	StatusUserKeyRemoteCompromise   = "USER_KEY_REMOTE_COMPROMISE"
+	StatusUserKeyPhysicalCompromise = "USER_KEY_PHYSICAL_COMPROMISE"
+)
+
+var (
+	// ErrInvalidBlob is returned when a metadata blob is not a well formed
+	// JWS or its payload cannot be read.
+	ErrInvalidBlob = errors.New("invalid metadata blob")
+
+	// ErrUntrustedBlob is returned when a metadata blob is not signed by a
+	// certificate chaining to the configured root.
+	ErrUntrustedBlob = errors.New("metadata blob is not signed by a trusted root")
+)
+
+// Metadata is a verified FIDO MDS3 blob, indexed by authenticator model.
+type Metadata struct {
+	// Number is the serial number of the blob.
+	Number int
+
+	// NextUpdate is the date the service promised a newer blob by.
+	NextUpdate time.Time
+
+	entries map[string]*Entry
+}
+
+// Entry describes one authenticator model of the metadata blob.
+type Entry struct {
+	AAGUID            string            `json:"aaguid"`
+	MetadataStatement MetadataStatement `json:"metadataStatement"`
+	StatusReports     []StatusReport    `json:"statusReports"`
+
+	roots *x509.CertPool
+}
+
+// MetadataStatement is the part of a metadata statement the policy uses.
+type MetadataStatement struct {
+	Description                 string   `json:"description"`
+	AttestationTypes            []string `json:"attestationTypes"`
+	AttestationRootCertificates []string `json:"attestationRootCertificates"`
+}
+
+// StatusReport is a certification or security event of an authenticator
+// model.
+type StatusReport struct {
+	Status        string `json:"status"`
+	EffectiveDate string `json:"effectiveDate"`
+}
+
+// blobPayload is the payload of an MDS3 blob.
+type blobPayload struct {
+	Number     int      `json:"no"`
+	NextUpdate string   `json:"nextUpdate"`
+	Entries    []*Entry `json:"entries"`
+}
+
+// blobHeader is the JWS header of an MDS3 blob.
+type blobHeader struct {
+	Alg string   `json:"alg"`
+	X5C []string `json:"x5c"`
+}
+
+// LoadMetadata reads and verifies the MDS3 blob at path against the root
+// certificates in rootFile, a PEM file. Blobs are downloaded from the FIDO
+// Alliance ahead of time; nothing is fetched at runtime.
+func LoadMetadata(path string, rootFile string, now time.Time) (*Metadata, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pem, err := ioutil.ReadFile(rootFile)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", rootFile)
+	}
+	md, err := ParseMetadata(blob, roots, now)
+	if err != nil {
+		return nil, err
+	}
+	if now.After(md.NextUpdate) {
+		logger.Warnf("Metadata blob %d was due to be replaced on %s", md.Number, md.NextUpdate.Format("2006-01-02"))
+	}
+	logger.Infof("Loaded metadata for %d authenticator models from blob %d", len(md.entries), md.Number)
+	return md, nil
+}
+
+// ParseMetadata verifies the signature of an MDS3 blob against roots and
+// returns its entries. The signing certificate's validity is checked at now.
+func ParseMetadata(blob []byte, roots *x509.CertPool, now time.Time) (*Metadata, error) {
+	parts := strings.Split(string(bytes.TrimSpace(blob)), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWS", ErrInvalidBlob)
+	}
+	var header blobHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signer, err := verifyChain(header.X5C, base64.StdEncoding, roots, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUntrustedBlob, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlob, err)
+	}
+	if err := verifySignature(header.Alg, signer, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUntrustedBlob, err)
+	}
+
+	var payload blobPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, err
+	}
+	md := &Metadata{Number: payload.Number, entries: make(map[string]*Entry)}
+	if payload.NextUpdate != "" {
+		if md.NextUpdate, err = time.Parse("2006-01-02", payload.NextUpdate); err != nil {
+			return nil, fmt.Errorf("%w: nextUpdate: %v", ErrInvalidBlob, err)
+		}
+	}
+	for _, e := range payload.Entries {
+		// U2F authenticators are listed by key identifier and have no AAGUID.
+		if e.AAGUID == "" {
+			continue
+		}
+		e.AAGUID = strings.ToLower(e.AAGUID)
+		e.roots = x509.NewCertPool()
+		for _, c := range e.MetadataStatement.AttestationRootCertificates {
+			der, err := base64.StdEncoding.DecodeString(c)
+			if err != nil {
+				return nil, fmt.Errorf("%w: root certificate of %s: %v", ErrInvalidBlob, e.AAGUID, err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("%w: root certificate of %s: %v", ErrInvalidBlob, e.AAGUID, err)
+			}
+			e.roots.AddCert(cert)
+		}
+		sort.SliceStable(e.StatusReports, func(i, j int) bool {
+			return e.StatusReports[i].EffectiveDate < e.StatusReports[j].EffectiveDate
+		})
+		md.entries[e.AAGUID] = e
+	}
+	return md, nil
+}
+
+// Entry returns the entry of the authenticator model with the given AAGUID.
+func (md *Metadata) Entry(aaguid string) (*Entry, bool) {
+	e, ok := md.entries[strings.ToLower(aaguid)]
+	return e, ok
+}
+
+// Level is the highest FIDO certification level the model was granted.
+func (e *Entry) Level() Level {
+	level := Uncertified
+	for _, r := range e.StatusReports {
+		if l, ok := statusLevels[r.Status]; ok && l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// Compromised reports whether the model was revoked or any of its keys or
+// its user verification were found to be compromised.
+func (e *Entry) Compromised() bool {
+	for _, r := range e.StatusReports {
+		switch r.Status {
+		case StatusRevoked, StatusAttestationKeyCompromise, StatusUserVerificationBypass,
+			StatusUserKeyRemoteCompromise, StatusUserKeyPhysicalCompromise:
+			return true
+		}
+	}
+	return false
+}
+
+// supportsType reports whether the metadata statement lists attestationType.
+// Statements that list none are taken to allow any.
+func (e *Entry) supportsType(attestationType string) bool {
+	if len(e.MetadataStatement.AttestationTypes) == 0 {
+		return true
+	}
+	for _, t := range e.MetadataStatement.AttestationTypes {
+		if t == attestationType {
+			return true
+		}
+	}
+	return false
+}
+
+var statusLevels = map[string]Level{
+	StatusFidoCertified:       L1,
+	StatusFidoCertifiedL1:     L1,
+	StatusFidoCertifiedL1Plus: L1Plus,
+	StatusFidoCertifiedL2:     L2,
+	StatusFidoCertifiedL2Plus: L2Plus,
+	StatusFidoCertifiedL3:     L3,
+	StatusFidoCertifiedL3Plus: L3Plus,
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	buf, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBlob, err)
+	}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBlob, err)
+	}
+	return nil
+}
+
+// verifyChain parses an x5c certificate chain, leaf first, and verifies it
+// against roots. It returns the leaf.
+func verifyChain(x5c []string, enc *base64.Encoding, roots *x509.CertPool, now time.Time) (*x509.Certificate, error) {
+	ders := make([][]byte, len(x5c))
+	for i, c := range x5c {
+		der, err := enc.DecodeString(c)
+		if err != nil {
+			return nil, err
+		}
+		ders[i] = der
+	}
+	return verifyDERChain(ders, roots, now)
+}
+
+// verifyDERChain verifies a DER certificate chain, leaf first, against
+// roots. Attestation certificates rarely carry extended key usages, so any
+// usage is accepted.
+func verifyDERChain(chain [][]byte, roots *x509.CertPool, now time.Time) (*x509.Certificate, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("no certificate chain")
+	}
+	certs := make([]*x509.Certificate, len(chain))
+	for i, der := range chain {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// verifySignature checks a JWS signature made with the RS256 or ES256
+// algorithm.
+func verifySignature(alg string, cert *x509.Certificate, signed []byte, sig []byte) error {
+	digest := sha256.Sum256(signed)
+	switch alg {
+	case "RS256":
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("RS256 blob signed by a non-RSA key")
+		}
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig)
+	case "ES256":
+		key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return errors.New("malformed ES256 signature")
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return errors.New("signature does not match")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
@@ -0,0 +1,285 @@
+// Package attestation decides which authenticators may register, using the
+// attestation statement of the registration and an offline copy of the FIDO
+// Metadata Service (MDS3).
+package attestation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/kataras/golog"
+)
+
+var logger = golog.Default.Child("pkg/attestation")
+
+// Attestation types, named as in FIDO metadata statements.
+const (
+	TypeNone           = "none"
+	TypeBasicFull      = "basic_full"
+	TypeBasicSurrogate = "basic_surrogate"
+	TypeAttCA          = "attca"
+	TypeAnonCA         = "anonca"
+)
+
+// Level is a FIDO authenticator certification level.
+type Level int
+
+// Certification levels, lowest first.
+const (
+	Uncertified Level = iota
+	L1
+	L1Plus
+	L2
+	L2Plus
+	L3
+	L3Plus
+)
+
+var levelNames = []string{"", "L1", "L1+", "L2", "L2+", "L3", "L3+"}
+
+// ParseLevel parses a level written as "L1" to "L3+". The empty string is
+// Uncertified.
+func ParseLevel(s string) (Level, error) {
+	s = strings.Replace(strings.ToUpper(strings.TrimSpace(s)), "PLUS", "+", 1)
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return Uncertified, fmt.Errorf("unknown certification level %q", s)
+}
+
+func (l Level) String() string {
+	if l == Uncertified {
+		return "uncertified"
+	}
+	return levelNames[l]
+}
+
+var (
+	// ErrRejected is wrapped by every error refusing a registration.
+	ErrRejected = errors.New("authenticator not allowed")
+
+	// ErrMetadataRequired is returned by New when the policy restricts
+	// authenticator models but no metadata blob is configured to identify
+	// them with.
+	ErrMetadataRequired = errors.New("an AAGUID allow list or minimum certification level requires a metadata blob")
+)
+
+// Config describes an attestation policy.
+type Config struct {
+	// MetadataFile is an MDS3 blob downloaded from the FIDO Alliance, and
+	// RootFile the PEM root certificate it is signed under.
+	MetadataFile string
+	RootFile     string
+
+	// MinLevel is the lowest certification level allowed to register.
+	MinLevel Level
+
+	// Allow, when not empty, lists the only AAGUIDs allowed to register.
+	// Deny lists AAGUIDs refused even when they are certified.
+	Allow []string
+	Deny  []string
+}
+
+// Policy verifies registrations against a Config. The zero Policy accepts
+// every authenticator. It is safe for concurrent use.
+type Policy struct {
+	metadata *Metadata
+	minLevel Level
+	allow    map[string]bool
+	deny     map[string]bool
+	now      func() time.Time
+}
+
+// New loads the metadata blob of cfg and returns its policy.
+func New(cfg Config) (*Policy, error) {
+	p := &Policy{
+		minLevel: cfg.MinLevel,
+		allow:    aaguidSet(cfg.Allow),
+		deny:     aaguidSet(cfg.Deny),
+	}
+	if cfg.MetadataFile != "" {
+		md, err := LoadMetadata(cfg.MetadataFile, cfg.RootFile, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		p.metadata = md
+	}
+	if p.restricted() && p.metadata == nil {
+		return nil, ErrMetadataRequired
+	}
+	return p, nil
+}
+
+// restricted reports whether only known authenticator models may register.
+func (p *Policy) restricted() bool {
+	return len(p.allow) > 0 || p.minLevel > Uncertified
+}
+
+// Conveyance is the attestation the policy needs registrations to request.
+// It is empty when the client's preference can be used.
+func (p *Policy) Conveyance() protocol.ConveyancePreference {
+	if p.restricted() {
+		return protocol.PreferDirectAttestation
+	}
+	return ""
+}
+
+// Statement is the attestation of a registration, already checked by the
+// webauthn library to be signed by the leaf of its certificate chain.
+type Statement struct {
+	Format string
+	AAGUID []byte
+	// Chain is the attestation certificate chain, leaf first. It is empty
+	// for self and no attestation.
+	Chain [][]byte
+}
+
+// FromCreation extracts the attestation statement of a registration.
+func FromCreation(c *protocol.ParsedCredentialCreationData) Statement {
+	obj := c.Response.AttestationObject
+	st := Statement{Format: obj.Format, AAGUID: obj.AuthData.AttData.AAGUID}
+	if x5c, ok := obj.AttStatement["x5c"].([]interface{}); ok {
+		for _, c := range x5c {
+			if der, ok := c.([]byte); ok {
+				st.Chain = append(st.Chain, der)
+			}
+		}
+	} else if response, ok := obj.AttStatement["response"].([]byte); ok {
+		// android-safetynet carries its chain in the header of a JWS.
+		st.Chain = safetyNetChain(response)
+	}
+	return st
+}
+
+// Type is the attestation type of the statement.
+func (s Statement) Type() string {
+	switch {
+	case s.Format == "none":
+		return TypeNone
+	case len(s.Chain) == 0:
+		return TypeBasicSurrogate
+	case s.Format == "tpm":
+		return TypeAttCA
+	case s.Format == "apple":
+		return TypeAnonCA
+	default:
+		return TypeBasicFull
+	}
+}
+
+// Result describes an accepted registration.
+type Result struct {
+	Type   string
+	AAGUID string
+	Level  Level
+	// Trusted is set when the attestation chained to the roots the metadata
+	// lists for the AAGUID, so the authenticator model is proven.
+	Trusted bool
+}
+
+// Verify decides whether the authenticator of a registration may register.
+// Refusals wrap ErrRejected.
+func (p *Policy) Verify(st Statement) (*Result, error) {
+	res := &Result{Type: st.Type(), AAGUID: FormatAAGUID(st.AAGUID)}
+	if p.deny[res.AAGUID] {
+		return nil, fmt.Errorf("%w: authenticator %s is denied", ErrRejected, res.AAGUID)
+	}
+
+	var entry *Entry
+	if p.metadata != nil {
+		entry, _ = p.metadata.Entry(res.AAGUID)
+	}
+	if entry == nil {
+		if p.restricted() {
+			return nil, fmt.Errorf("%w: authenticator %s has no metadata", ErrRejected, res.AAGUID)
+		}
+		return res, nil
+	}
+	if entry.Compromised() {
+		return nil, fmt.Errorf("%w: %s is revoked or compromised", ErrRejected, entry.MetadataStatement.Description)
+	}
+	res.Level = entry.Level()
+
+	// An attestation claiming a known model must be signed under that
+	// model's roots; otherwise the AAGUID cannot be believed.
+	if len(st.Chain) > 0 {
+		if !entry.supportsType(res.Type) {
+			return nil, fmt.Errorf("%w: %s does not use %s attestation", ErrRejected, entry.MetadataStatement.Description, res.Type)
+		}
+		if _, err := verifyDERChain(st.Chain, entry.roots, p.time()); err != nil {
+			return nil, fmt.Errorf("%w: attestation of %s does not chain to its roots: %v", ErrRejected, res.AAGUID, err)
+		}
+		res.Trusted = true
+	}
+
+	if p.restricted() {
+		if !res.Trusted {
+			return nil, fmt.Errorf("%w: %s attestation cannot prove the authenticator model", ErrRejected, res.Type)
+		}
+		if len(p.allow) > 0 && !p.allow[res.AAGUID] {
+			return nil, fmt.Errorf("%w: authenticator %s is not on the allow list", ErrRejected, res.AAGUID)
+		}
+		if res.Level < p.minLevel {
+			return nil, fmt.Errorf("%w: %s is certified %s, %s required", ErrRejected, entry.MetadataStatement.Description, res.Level, p.minLevel)
+		}
+	}
+	return res, nil
+}
+
+func (p *Policy) time() time.Time {
+	if p.now == nil {
+		return time.Now()
+	}
+	return p.now()
+}
+
+// FormatAAGUID formats an AAGUID the way metadata statements do.
+func FormatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 {
+		return fmt.Sprintf("%x", aaguid)
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", aaguid[0:4], aaguid[4:6], aaguid[6:8], aaguid[8:10], aaguid[10:16])
+}
+
+func aaguidSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, a := range list {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			set[a] = true
+		}
+	}
+	return set
+}
+
+// safetyNetChain returns the certificate chain in the header of an
+// android-safetynet attestation response.
+func safetyNetChain(jws []byte) [][]byte {
+	parts := strings.Split(string(jws), ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil
+	}
+	var header blobHeader
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return nil
+	}
+	var chain [][]byte
+	for _, c := range header.X5C {
+		der, err := base64.StdEncoding.DecodeString(c)
+		if err != nil {
+			return nil
+		}
+		chain = append(chain, der)
+	}
+	return chain
+}
@@ -0,0 +1,246 @@
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	now       = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	certified = "fa2b99dc-9e39-4257-8f92-4a30d23c4118"
+	uncertain = "ee882879-721c-4913-9775-3dfcce97072a"
+	revoked   = "2fc0579f-8113-47ea-b116-bb5a8db9202a"
+)
+
+// issue creates a certificate for key signed by parent, or self-signed when
+// parent is nil.
+func issue(t *testing.T, name string, key *ecdsa.PrivateKey, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signBlob returns an ES256 JWS of payload signed by key, with cert as x5c.
+func signBlob(t *testing.T, payload interface{}, cert *x509.Certificate, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	header, _ := json.Marshal(blobHeader{Alg: "ES256", X5C: []string{base64.StdEncoding.EncodeToString(cert.Raw)}})
+	body, _ := json.Marshal(payload)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return []byte(signed + "." + base64.RawURLEncoding.EncodeToString(sig))
+}
+
+type fixture struct {
+	mdsRoots *x509.CertPool
+	blob     []byte
+	// leaf is an attestation certificate under the roots of every entry.
+	leaf []byte
+	// rogue is an attestation certificate under no listed root.
+	rogue []byte
+}
+
+func newFixture(t *testing.T) fixture {
+	mdsKey := newKey(t)
+	mdsRoot := issue(t, "MDS root", mdsKey, nil, nil)
+	signerKey := newKey(t)
+	signer := issue(t, "MDS signer", signerKey, mdsRoot, mdsKey)
+
+	attKey := newKey(t)
+	attRoot := issue(t, "Vendor attestation root", attKey, nil, nil)
+	leaf := issue(t, "Vendor attestation", newKey(t), attRoot, attKey)
+	rogueKey := newKey(t)
+	rogue := issue(t, "Rogue attestation", newKey(t), issue(t, "Rogue root", rogueKey, nil, nil), rogueKey)
+
+	statement := MetadataStatement{
+		Description:                 "Vendor key",
+		AttestationTypes:            []string{TypeBasicFull},
+		AttestationRootCertificates: []string{base64.StdEncoding.EncodeToString(attRoot.Raw)},
+	}
+	payload := blobPayload{
+		Number:     42,
+		NextUpdate: "2022-07-01",
+		Entries: []*Entry{
+			{AAGUID: certified, MetadataStatement: statement, StatusReports: []StatusReport{
+				{Status: StatusFidoCertifiedL1, EffectiveDate: "2020-01-01"},
+				{Status: StatusFidoCertifiedL2, EffectiveDate: "2021-01-01"},
+			}},
+			{AAGUID: uncertain, MetadataStatement: statement},
+			{AAGUID: revoked, MetadataStatement: statement, StatusReports: []StatusReport{
+				{Status: StatusFidoCertifiedL1, EffectiveDate: "2020-01-01"},
+				{Status: StatusAttestationKeyCompromise, EffectiveDate: "2021-01-01"},
+			}},
+		},
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(mdsRoot)
+	return fixture{
+		mdsRoots: roots,
+		blob:     signBlob(t, payload, signer, signerKey),
+		leaf:     leaf.Raw,
+		rogue:    rogue.Raw,
+	}
+}
+
+func aaguidBytes(s string) []byte {
+	b, _ := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	return b
+}
+
+func TestParseMetadata(t *testing.T) {
+	f := newFixture(t)
+	md, err := ParseMetadata(f.blob, f.mdsRoots, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Number != 42 || md.NextUpdate.Format("2006-01-02") != "2022-07-01" {
+		t.Errorf("blob %d due %s", md.Number, md.NextUpdate)
+	}
+	e, ok := md.Entry(strings.ToUpper(certified))
+	if !ok {
+		t.Fatal("certified entry missing")
+	}
+	if e.Level() != L2 || e.Compromised() {
+		t.Errorf("certified entry is %s, compromised %v", e.Level(), e.Compromised())
+	}
+	if e, _ := md.Entry(revoked); !e.Compromised() {
+		t.Error("revoked entry not compromised")
+	}
+
+	tampered := []byte(strings.Replace(string(f.blob), ".", ".e30", 1))
+	if _, err := ParseMetadata(tampered, f.mdsRoots, now); !errors.Is(err, ErrUntrustedBlob) {
+		t.Errorf("tampered blob: %v", err)
+	}
+	if _, err := ParseMetadata(f.blob, x509.NewCertPool(), now); !errors.Is(err, ErrUntrustedBlob) {
+		t.Errorf("blob under another root: %v", err)
+	}
+	if _, err := ParseMetadata([]byte("not a blob"), f.mdsRoots, now); !errors.Is(err, ErrInvalidBlob) {
+		t.Errorf("malformed blob: %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	f := newFixture(t)
+	md, err := ParseMetadata(f.blob, f.mdsRoots, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := func() time.Time { return now }
+	open := &Policy{metadata: md, now: fixed}
+	level2 := &Policy{metadata: md, minLevel: L2, now: fixed}
+	level3 := &Policy{metadata: md, minLevel: L3, now: fixed}
+	allow := &Policy{metadata: md, allow: aaguidSet([]string{uncertain}), now: fixed}
+	deny := &Policy{deny: aaguidSet([]string{certified})}
+
+	packed := func(aaguid string, chain ...[]byte) Statement {
+		return Statement{Format: "packed", AAGUID: aaguidBytes(aaguid), Chain: chain}
+	}
+	none := Statement{Format: "none", AAGUID: make([]byte, 16)}
+
+	tests := []struct {
+		name    string
+		policy  *Policy
+		st      Statement
+		want    string
+		trusted bool
+	}{
+		{"zero policy takes anything", &Policy{}, none, TypeNone, false},
+		{"unlisted model without restrictions", open, packed("00000000-0000-0000-0000-000000000001"), TypeBasicSurrogate, false},
+		{"certified model", open, packed(certified, f.leaf), TypeBasicFull, true},
+		{"model spoofed with a foreign chain", open, packed(certified, f.rogue), "", false},
+		{"compromised model", open, packed(revoked, f.leaf), "", false},
+		{"denied model", deny, packed(certified, f.leaf), "", false},
+		{"level met", level2, packed(certified, f.leaf), TypeBasicFull, true},
+		{"level not met", level3, packed(certified, f.leaf), "", false},
+		{"self attestation cannot prove a level", level2, packed(certified), "", false},
+		{"none attestation cannot prove a level", level2, none, "", false},
+		{"allowed model", allow, packed(uncertain, f.leaf), TypeBasicFull, true},
+		{"model not on the allow list", allow, packed(certified, f.leaf), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.policy.Verify(tt.st)
+			if tt.want == "" {
+				if !errors.Is(err, ErrRejected) {
+					t.Fatalf("Verify = %+v, %v; want rejection", res, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Type != tt.want || res.Trusted != tt.trusted || res.AAGUID != FormatAAGUID(tt.st.AAGUID) {
+				t.Errorf("Verify = %+v", res)
+			}
+		})
+	}
+}
+
+func TestNewRequiresMetadata(t *testing.T) {
+	if _, err := New(Config{MinLevel: L1}); !errors.Is(err, ErrMetadataRequired) {
+		t.Errorf("minimum level without metadata: %v", err)
+	}
+	if _, err := New(Config{Allow: []string{certified}}); !errors.Is(err, ErrMetadataRequired) {
+		t.Errorf("allow list without metadata: %v", err)
+	}
+	if _, err := New(Config{Deny: []string{certified}}); err != nil {
+		t.Errorf("deny list without metadata: %v", err)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]Level{"": Uncertified, "L1": L1, "l2+": L2Plus, "L3plus": L3Plus} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %s, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLevel("L4"); err == nil {
+		t.Error("ParseLevel accepted L4")
+	}
+}
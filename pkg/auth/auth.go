@@ -0,0 +1,210 @@
+// Package auth identifies RPC callers. A caller is identified by the DID in
+// its mTLS client certificate or by a session token issued to a logged in
+// WebAuthn user, and the verified DID is carried in the call's context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kataras/jwt"
+	"github.com/sonr-io/webauthn.io/pkg/certs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TokenTTL is how long a session token is valid for.
+const TokenTTL = 15 * time.Minute
+
+// Issuer is the iss claim of session tokens.
+const Issuer = "sonr-highway"
+
+var (
+	ErrMissingKey   = errors.New("no signing key is configured")
+	ErrInvalidToken = errors.New("invalid session token")
+)
+
+// IssueToken returns a session token identifying did, signed with key.
+// session names the login session the token was issued to, so it can be
+// revoked along with it.
+func IssueToken(key []byte, did string, session string, ttl time.Duration) (string, time.Time, error) {
+	if len(key) == 0 {
+		return "", time.Time{}, ErrMissingKey
+	}
+	if did == "" {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	now := time.Now()
+	expires := now.Add(ttl)
+	token, err := jwt.Sign(jwt.HS256, key, jwt.Claims{
+		Issuer:   Issuer,
+		Subject:  did,
+		ID:       session,
+		IssuedAt: now.Unix(),
+		Expiry:   expires.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return string(token), expires, nil
+}
+
+// VerifyToken checks a session token and returns the DID it identifies and
+// the login session it was issued to.
+func VerifyToken(key []byte, token string) (string, string, error) {
+	if len(key) == 0 {
+		return "", "", ErrMissingKey
+	}
+	verified, err := jwt.Verify(jwt.HS256, key, []byte(token))
+	if err != nil {
+		return "", "", ErrInvalidToken
+	}
+	c := verified.StandardClaims
+	if c.Issuer != Issuer || c.Subject == "" || c.Expiry == 0 {
+		return "", "", ErrInvalidToken
+	}
+	return c.Subject, c.ID, nil
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying the verified DID of the caller.
+func WithCaller(ctx context.Context, did string) context.Context {
+	return context.WithValue(ctx, callerKey{}, did)
+}
+
+// Caller returns the verified DID of the caller, if the call is authenticated.
+func Caller(ctx context.Context) (string, bool) {
+	did, ok := ctx.Value(callerKey{}).(string)
+	return did, ok && did != ""
+}
+
+// Option configures an Authenticator.
+type Option func(*Authenticator)
+
+// WithPublicMethods lets unauthenticated callers reach methods. Entries are
+// full method names, or service names ending in "/" to match every method of
+// the service.
+func WithPublicMethods(methods ...string) Option {
+	return func(a *Authenticator) {
+		a.public = append(a.public, methods...)
+	}
+}
+
+// SessionCheck returns an error unless the login session a token was issued
+// to is still active and belongs to did.
+type SessionCheck func(ctx context.Context, session string, did string) error
+
+// WithSessionCheck checks every bearer token's login session, so tokens stop
+// working when their session is signed out instead of when they expire.
+// Tokens without a session are refused.
+func WithSessionCheck(check SessionCheck) Option {
+	return func(a *Authenticator) {
+		a.session = check
+	}
+}
+
+// Authenticator authenticates RPC callers.
+type Authenticator struct {
+	key     []byte
+	public  []string
+	session SessionCheck
+}
+
+// NewAuthenticator returns an Authenticator verifying session tokens with
+// key. Without a key only mTLS callers are authenticated.
+func NewAuthenticator(key []byte, opts ...Option) *Authenticator {
+	a := &Authenticator{key: key}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Authenticate returns ctx with the caller's DID attached. A client
+// certificate takes precedence over a bearer token. Calls to non-public
+// methods without either fail with codes.Unauthenticated, as do calls
+// presenting an invalid token.
+func (a *Authenticator) Authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if did, err := certs.CallerDid(ctx); err == nil {
+		return WithCaller(ctx, did), nil
+	}
+	if token := bearerToken(ctx); token != "" {
+		did, session, err := VerifyToken(a.key, token)
+		if err != nil {
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if a.session != nil {
+			if session == "" {
+				return ctx, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+			}
+			if err := a.session(ctx, session, did); err != nil {
+				return ctx, status.Error(codes.Unauthenticated, err.Error())
+			}
+		}
+		return WithCaller(ctx, did), nil
+	}
+	if a.isPublic(fullMethod) {
+		return ctx, nil
+	}
+	return ctx, status.Error(codes.Unauthenticated, "missing credentials")
+}
+
+// Unary returns a unary interceptor authenticating every call.
+func (a *Authenticator) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.Authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns a stream interceptor authenticating every call.
+func (a *Authenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.Authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) isPublic(fullMethod string) bool {
+	for _, m := range a.public {
+		if m == fullMethod || (strings.HasSuffix(m, "/") && strings.HasPrefix(fullMethod, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token of an "authorization: Bearer" header.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, v := range md.Get("authorization") {
+		if len(v) > 7 && strings.EqualFold(v[:7], "bearer ") {
+			return strings.TrimSpace(v[7:])
+		}
+	}
+	return ""
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestToken(t *testing.T) {
+	token, expires, err := IssueToken(testKey, "did:sonr:alice", "1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(expires) > time.Minute {
+		t.Fatalf("unexpected expiry %s", expires)
+	}
+	did, session, err := VerifyToken(testKey, token)
+	if err != nil || did != "did:sonr:alice" || session != "1" {
+		t.Fatalf("VerifyToken = %q, %q, %v", did, session, err)
+	}
+
+	if _, _, err := VerifyToken([]byte("another key"), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("wrong key: %v", err)
+	}
+	expired, _, err := IssueToken(testKey, "did:sonr:alice", "1", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyToken(testKey, expired); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expired token: %v", err)
+	}
+	if _, _, err := IssueToken(nil, "did:sonr:alice", "1", time.Minute); !errors.Is(err, ErrMissingKey) {
+		t.Fatalf("missing key: %v", err)
+	}
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := NewAuthenticator(testKey, WithPublicMethods("/svc.Highway/CheckName", "/grpc.health.v1.Health/"))
+	token, _, err := IssueToken(testKey, "did:sonr:alice", "1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := a.Authenticate(withToken(token), "/svc.Highway/CreateBucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if did, ok := Caller(ctx); !ok || did != "did:sonr:alice" {
+		t.Fatalf("Caller = %q, %v", did, ok)
+	}
+
+	for _, m := range []string{"/svc.Highway/CheckName", "/grpc.health.v1.Health/Check"} {
+		ctx, err := a.Authenticate(context.Background(), m)
+		if err != nil {
+			t.Fatalf("%s: %v", m, err)
+		}
+		if _, ok := Caller(ctx); ok {
+			t.Fatalf("%s: anonymous call has a caller", m)
+		}
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"anonymous": context.Background(),
+		"bad token": withToken("not-a-token"),
+	} {
+		if _, err := a.Authenticate(ctx, "/svc.Highway/CreateBucket"); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: want Unauthenticated, got %v", name, err)
+		}
+	}
+	// A bad token is rejected even on public methods.
+	if _, err := a.Authenticate(withToken("not-a-token"), "/svc.Highway/CheckName"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("public method with bad token: %v", err)
+	}
+}
+
+func TestSessionCheck(t *testing.T) {
+	ended := errors.New("session ended")
+	active := map[string]string{"1": "did:sonr:alice"}
+	a := NewAuthenticator(testKey, WithSessionCheck(func(ctx context.Context, session string, did string) error {
+		if active[session] != did {
+			return ended
+		}
+		return nil
+	}))
+	token, _, err := IssueToken(testKey, "did:sonr:alice", "1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Authenticate(withToken(token), "/svc.Highway/CreateBucket"); err != nil {
+		t.Fatalf("active session: %v", err)
+	}
+
+	delete(active, "1")
+	if _, err := a.Authenticate(withToken(token), "/svc.Highway/CreateBucket"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("signed out session: %v", err)
+	}
+	sessionless, _, err := IssueToken(testKey, "did:sonr:alice", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Authenticate(withToken(sessionless), "/svc.Highway/CreateBucket"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("token without a session: %v", err)
+	}
+}
+
+func TestUnary(t *testing.T) {
+	a := NewAuthenticator(testKey)
+	token, _, err := IssueToken(testKey, "did:sonr:bob", "2", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc.Highway/CreateBucket"}
+	resp, err := a.Unary()(withToken(token), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		did, _ := Caller(ctx)
+		return did, nil
+	})
+	if err != nil || resp != "did:sonr:bob" {
+		t.Fatalf("handler saw %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,122 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/sonr-io/webauthn.io/config"
+)
+
+// Store kinds accepted by New.
+const (
+	KindLocal = "local"
+	KindIPFS  = "ipfs"
+)
+
+var (
+	logger = golog.Default.Child("pkg/blob")
+
+	// ErrNotFound is returned when no blob exists for a CID.
+	ErrNotFound = errors.New("blob not found")
+
+	// ErrNotOwner is returned when a caller deletes a blob it did not upload.
+	ErrNotOwner = errors.New("caller does not own the blob")
+
+	// ErrInvalidCID is returned when a CID is malformed.
+	ErrInvalidCID = errors.New("invalid cid")
+
+	// ErrMissingOwner is returned when a blob is stored without an owner.
+	ErrMissingOwner = errors.New("blob owner is required")
+)
+
+// Blob describes a stored blob.
+type Blob struct {
+	CID       string    `json:"cid"`
+	Label     string    `json:"label,omitempty"`
+	RefDid    string    `json:"ref_did,omitempty"`
+	Size      int64     `json:"size"`
+	Owners    []string  `json:"owners"`
+	Pinned    bool      `json:"pinned"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsOwner reports whether owner uploaded the blob.
+func (b Blob) IsOwner(owner string) bool {
+	for _, o := range b.Owners {
+		if o == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// PutOptions describes the blob being stored.
+type PutOptions struct {
+	// Owner is the DID of the uploader. It is required to delete the blob
+	// later.
+	Owner string
+
+	// Label is a human readable name for the blob.
+	Label string
+
+	// RefDid is the bucket or object the blob belongs to.
+	RefDid string
+}
+
+// BlobStore persists blobs addressed by their CID. Uploading the same
+// content twice yields the same CID; each uploader is recorded as an owner
+// and the content is only removed once every owner has deleted it.
+type BlobStore interface {
+	// Put stores the contents of r and returns the resulting blob.
+	Put(ctx context.Context, r io.Reader, opts PutOptions) (Blob, error)
+
+	// Get returns the contents of the blob. The caller must close the reader.
+	Get(ctx context.Context, cid string) (io.ReadCloser, error)
+
+	// Stat returns the blob's metadata without reading its contents.
+	Stat(ctx context.Context, cid string) (Blob, error)
+
+	// Delete removes owner from the blob, removing the content once no owners
+	// remain.
+	Delete(ctx context.Context, cid string, owner string) error
+
+	// Ping reports whether the store can currently accept writes.
+	Ping(ctx context.Context) error
+}
+
+// New returns the BlobStore selected by cnfg.BlobStore. The local store is
+// used when none is configured.
+func New(cnfg *config.SonrConfig) (BlobStore, error) {
+	root := config.Folder(cnfg.IPFSPath)
+	if root == "" {
+		root = cnfg.CacheFolder()
+	}
+	dir := root.JoinPath("blobs")
+	switch cnfg.BlobStore {
+	case "", KindLocal:
+		logger.Infof("Storing blobs in %s", dir)
+		return NewLocalStore(dir)
+	case KindIPFS:
+		api := ipfsAPIURL(cnfg)
+		logger.Infof("Storing blobs on IPFS node %s", api)
+		return NewIPFSStore(api, dir)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", cnfg.BlobStore)
+	}
+}
+
+func ipfsAPIURL(cnfg *config.SonrConfig) string {
+	host := cnfg.IPFSAddress
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	port := cnfg.IPFSPort
+	if port == 0 {
+		port = 5001
+	}
+	return fmt.Sprintf("http://%s:%d/api/v0", host, port)
+}
@@ -0,0 +1,53 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"hash"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	cidVersion1  = 0x01
+	codecRaw     = 0x55
+	hashSha2_256 = 0x12
+)
+
+var (
+	cidEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+	cidRegexp   = regexp.MustCompile("^(Qm[1-9A-HJ-NP-Za-km-z]{44}|b[a-z2-7]{8,})$")
+)
+
+// newHasher returns the hash used to address blob contents.
+func newHasher() hash.Hash {
+	return sha256.New()
+}
+
+// cidFromDigest encodes a sha2-256 digest as a base32 CIDv1 with the raw
+// codec, the same identifier `ipfs add --cid-version=1 --raw-leaves` reports
+// for content that fits in a single block.
+func cidFromDigest(digest []byte) string {
+	buf := make([]byte, 0, 4+len(digest))
+	buf = append(buf, cidVersion1, codecRaw, hashSha2_256, byte(len(digest)))
+	buf = append(buf, digest...)
+	return "b" + strings.ToLower(cidEncoding.EncodeToString(buf))
+}
+
+// ValidCID reports whether s looks like a CIDv0 or base32 CIDv1. It is used to
+// reject identifiers that could escape the store's directory.
+func ValidCID(s string) bool {
+	return cidRegexp.MatchString(s)
+}
+
+// ComputeCID returns the CID the local store would assign to the contents of
+// r. Content larger than one IPFS block is chunked by IPFS and receives a
+// different CID there.
+func ComputeCID(r io.Reader) (string, error) {
+	h := newHasher()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return cidFromDigest(h.Sum(nil)), nil
+}
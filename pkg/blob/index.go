@@ -0,0 +1,108 @@
+package blob
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/config"
+)
+
+// index stores blob metadata as one JSON document per CID. It is not safe for
+// concurrent use; stores serialize access with their own lock.
+type index struct {
+	dir config.Folder
+}
+
+func newIndex(dir config.Folder) (*index, error) {
+	if err := dir.MkdirAll(); err != nil {
+		return nil, err
+	}
+	return &index{dir: dir}, nil
+}
+
+func (i *index) get(cid string) (Blob, error) {
+	b := Blob{}
+	buf, err := i.dir.ReadFile(cid + ".json")
+	if os.IsNotExist(err) {
+		return b, ErrNotFound
+	} else if err != nil {
+		return b, err
+	}
+	err = json.Unmarshal(buf, &b)
+	return b, err
+}
+
+func (i *index) put(b Blob) error {
+	buf, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return i.dir.WriteFile(b.CID+".json", buf)
+}
+
+// addOwner records opts.Owner as an owner of the blob, creating its metadata
+// if this is the first upload of the content.
+func (i *index) addOwner(cid string, size int64, pinned bool, opts PutOptions) (Blob, error) {
+	b, err := i.get(cid)
+	if err == ErrNotFound {
+		b = Blob{
+			CID:       cid,
+			Label:     opts.Label,
+			RefDid:    opts.RefDid,
+			Size:      size,
+			Pinned:    pinned,
+			CreatedAt: time.Now(),
+		}
+	} else if err != nil {
+		return b, err
+	}
+	if !b.IsOwner(opts.Owner) {
+		b.Owners = append(b.Owners, opts.Owner)
+	}
+	return b, i.put(b)
+}
+
+// removeOwner removes owner from the blob and returns how many owners remain.
+// The metadata is deleted once the last owner is removed.
+func (i *index) removeOwner(cid string, owner string) (int, error) {
+	b, err := i.get(cid)
+	if err != nil {
+		return 0, err
+	}
+	if !b.IsOwner(owner) {
+		return 0, ErrNotOwner
+	}
+	owners := b.Owners[:0]
+	for _, o := range b.Owners {
+		if o != owner {
+			owners = append(owners, o)
+		}
+	}
+	b.Owners = owners
+	if len(owners) == 0 {
+		return 0, i.dir.Delete(cid + ".json")
+	}
+	return len(owners), i.put(b)
+}
+
+// ContextReader returns a reader of r that stops reading once ctx is done,
+// so that copies out of a blob end with the call they serve.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return ctxReader{ctx: ctx, r: r}
+}
+
+// ctxReader stops reading once its context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
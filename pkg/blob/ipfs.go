@@ -0,0 +1,174 @@
+package blob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/sonr-io/webauthn.io/config"
+)
+
+// IPFSStore is a BlobStore backed by an IPFS node's HTTP API. Contents are
+// added and pinned on the node; ownership metadata is kept in a local index
+// since IPFS has no notion of it.
+type IPFSStore struct {
+	mu     sync.Mutex
+	api    string
+	client *http.Client
+	index  *index
+}
+
+// NewIPFSStore returns a store that talks to the IPFS HTTP API at api, for
+// example "http://127.0.0.1:5001/api/v0", and keeps its metadata in dir.
+func NewIPFSStore(api string, dir string) (*IPFSStore, error) {
+	idx, err := newIndex(config.Folder(config.Folder(dir).JoinPath("meta")))
+	if err != nil {
+		return nil, err
+	}
+	return &IPFSStore{
+		api:    strings.TrimSuffix(api, "/"),
+		client: http.DefaultClient,
+		index:  idx,
+	}, nil
+}
+
+// Put adds the contents of r to the IPFS node and pins it.
+func (s *IPFSStore) Put(ctx context.Context, r io.Reader, opts PutOptions) (Blob, error) {
+	if opts.Owner == "" {
+		return Blob{}, ErrMissingOwner
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", "blob")
+		if err == nil {
+			_, err = io.Copy(part, ctxReader{ctx: ctx, r: r})
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	q := url.Values{}
+	q.Set("cid-version", "1")
+	q.Set("raw-leaves", "true")
+	q.Set("pin", "true")
+	resp, err := s.call(ctx, "add", q, pr, mw.FormDataContentType())
+	if err != nil {
+		pr.CloseWithError(err)
+		return Blob{}, err
+	}
+	defer resp.Body.Close()
+
+	var added struct {
+		Hash string
+		Size string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return Blob{}, fmt.Errorf("decode ipfs add response: %w", err)
+	}
+	var size int64
+	fmt.Sscan(added.Size, &size)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index.addOwner(added.Hash, size, true, opts)
+}
+
+// Get streams the blob from the IPFS node. Any CID reachable by the node can
+// be read, not only those added through this store.
+func (s *IPFSStore) Get(ctx context.Context, cid string) (io.ReadCloser, error) {
+	if !ValidCID(cid) {
+		return nil, ErrInvalidCID
+	}
+	resp, err := s.call(ctx, "cat", url.Values{"arg": {cid}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat returns the blob's metadata.
+func (s *IPFSStore) Stat(ctx context.Context, cid string) (Blob, error) {
+	if !ValidCID(cid) {
+		return Blob{}, ErrInvalidCID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index.get(cid)
+}
+
+// Delete removes owner from the blob and unpins it once no owners remain.
+// The node's garbage collector reclaims the content.
+func (s *IPFSStore) Delete(ctx context.Context, cid string, owner string) error {
+	if !ValidCID(cid) {
+		return ErrInvalidCID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.index.get(cid)
+	if err != nil {
+		return err
+	}
+	if !b.IsOwner(owner) {
+		return ErrNotOwner
+	}
+	if len(b.Owners) == 1 {
+		resp, err := s.call(ctx, "pin/rm", url.Values{"arg": {cid}}, nil, "")
+		if err != nil && !strings.Contains(err.Error(), "not pinned") {
+			return err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+	_, err = s.index.removeOwner(cid, owner)
+	return err
+}
+
+// Ping checks that the IPFS node is reachable.
+func (s *IPFSStore) Ping(ctx context.Context) error {
+	resp, err := s.call(ctx, "id", nil, nil, "")
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// call POSTs to an IPFS API command and returns the response when the node
+// reports success.
+func (s *IPFSStore) call(ctx context.Context, cmd string, q url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := s.api + "/" + cmd
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct{ Message string }
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return nil, fmt.Errorf("ipfs %s: %s", cmd, apiErr.Message)
+	}
+	return resp, nil
+}
@@ -0,0 +1,124 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/sonr-io/webauthn.io/config"
+)
+
+// LocalStore is a content-addressed BlobStore on the local filesystem.
+// Contents live in <root>/data/<cid> and metadata in <root>/meta/<cid>.json.
+type LocalStore struct {
+	mu    sync.Mutex
+	data  config.Folder
+	tmp   config.Folder
+	index *index
+}
+
+// NewLocalStore returns a LocalStore rooted at root, creating it if needed.
+func NewLocalStore(root string) (*LocalStore, error) {
+	dir := config.Folder(root)
+	data, err := dir.CreateFolder("data")
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := dir.CreateFolder("tmp")
+	if err != nil {
+		return nil, err
+	}
+	idx, err := newIndex(config.Folder(dir.JoinPath("meta")))
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStore{
+		data:  data,
+		tmp:   tmp,
+		index: idx,
+	}, nil
+}
+
+// Put streams r to disk while hashing it and files it under its CID.
+func (s *LocalStore) Put(ctx context.Context, r io.Reader, opts PutOptions) (Blob, error) {
+	if opts.Owner == "" {
+		return Blob{}, ErrMissingOwner
+	}
+
+	f, err := ioutil.TempFile(s.tmp.Path(), "upload-*")
+	if err != nil {
+		return Blob{}, err
+	}
+	defer os.Remove(f.Name())
+
+	h := newHasher()
+	size, err := io.Copy(io.MultiWriter(f, h), ctxReader{ctx: ctx, r: r})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return Blob{}, err
+	}
+	cid := cidFromDigest(h.Sum(nil))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.data.Exists(cid) {
+		if err := os.Rename(f.Name(), s.data.JoinPath(cid)); err != nil {
+			return Blob{}, err
+		}
+	}
+	return s.index.addOwner(cid, size, false, opts)
+}
+
+// Get opens the blob for reading.
+func (s *LocalStore) Get(ctx context.Context, cid string) (io.ReadCloser, error) {
+	if !ValidCID(cid) {
+		return nil, ErrInvalidCID
+	}
+	f, err := os.Open(s.data.JoinPath(cid))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Stat returns the blob's metadata.
+func (s *LocalStore) Stat(ctx context.Context, cid string) (Blob, error) {
+	if !ValidCID(cid) {
+		return Blob{}, ErrInvalidCID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index.get(cid)
+}
+
+// Delete removes owner from the blob and deletes the contents once no
+// owners remain.
+func (s *LocalStore) Delete(ctx context.Context, cid string, owner string) error {
+	if !ValidCID(cid) {
+		return ErrInvalidCID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remaining, err := s.index.removeOwner(cid, owner)
+	if err != nil || remaining > 0 {
+		return err
+	}
+	if err := s.data.Delete(cid); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Ping checks that the store's directory is writable.
+func (s *LocalStore) Ping(ctx context.Context) error {
+	f, err := ioutil.TempFile(s.tmp.Path(), "ping-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,94 @@
+package blob
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreCID(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Matches `ipfs add --cid-version=1 --raw-leaves` for an empty file.
+	b, err := s.Put(context.Background(), strings.NewReader(""), PutOptions{Owner: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"; b.CID != want {
+		t.Errorf("CID = %s, want %s", b.CID, want)
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Put(ctx, strings.NewReader("hello"), PutOptions{Owner: "alice", Label: "greeting"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Size != 5 || b.Label != "greeting" || !b.IsOwner("alice") {
+		t.Errorf("Put() = %+v", b)
+	}
+
+	r, err := s.Get(ctx, b.CID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	buf, _ := ioutil.ReadAll(r)
+	if string(buf) != "hello" {
+		t.Errorf("Get() = %q", buf)
+	}
+
+	if _, err := s.Get(ctx, "../../etc/passwd"); err != ErrInvalidCID {
+		t.Errorf("Get(escape) err = %v, want ErrInvalidCID", err)
+	}
+}
+
+func TestLocalStoreDeleteOwners(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.Put(ctx, strings.NewReader("shared"), PutOptions{Owner: "alice"})
+	b, _ := s.Put(ctx, strings.NewReader("shared"), PutOptions{Owner: "bob"})
+	if a.CID != b.CID || len(b.Owners) != 2 {
+		t.Fatalf("duplicate content not deduplicated: %+v %+v", a, b)
+	}
+
+	if err := s.Delete(ctx, a.CID, "mallory"); err != ErrNotOwner {
+		t.Errorf("Delete(mallory) err = %v, want ErrNotOwner", err)
+	}
+	if err := s.Delete(ctx, a.CID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, a.CID); err != nil {
+		t.Errorf("content removed while bob still owns it: %v", err)
+	}
+	if err := s.Delete(ctx, a.CID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, a.CID); err != ErrNotFound {
+		t.Errorf("Get() after last delete err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Stat(ctx, a.CID); err != ErrNotFound {
+		t.Errorf("Stat() after last delete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPutRequiresOwner(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Put(context.Background(), strings.NewReader("x"), PutOptions{}); err != ErrMissingOwner {
+		t.Errorf("Put() err = %v, want ErrMissingOwner", err)
+	}
+}
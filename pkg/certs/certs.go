@@ -0,0 +1,263 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/sonr-io/webauthn.io/pkg/did"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// DefaultReloadInterval is how often the certificate files are checked for
+// changes.
+const DefaultReloadInterval = 5 * time.Second
+
+var (
+	logger = golog.Default.Child("pkg/certs")
+
+	// ErrMissingCA is returned when client authentication is enabled without
+	// a CA to verify client certificates against.
+	ErrMissingCA = errors.New("client authentication requires a CA file")
+
+	// ErrNoCallerDid is returned when a client certificate does not carry a
+	// DID identity.
+	ErrNoCallerDid = errors.New("client certificate has no did identity")
+)
+
+// Config describes where the TLS material lives and how clients are
+// authenticated.
+type Config struct {
+	// CertFile and KeyFile hold the server certificate chain and private key
+	// in PEM format.
+	CertFile string
+	KeyFile  string
+
+	// CAFile holds the PEM certificates client certificates are verified
+	// against. It is required when ClientAuth is set.
+	CAFile string
+
+	// ClientAuth requires every client to present a certificate signed by
+	// CAFile that identifies a DID.
+	ClientAuth bool
+
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// Reloader serves TLS configurations built from files on disk and reloads
+// them when the files change, so certificates can be rotated without a
+// restart. It is safe for concurrent use.
+type Reloader struct {
+	cfg Config
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+}
+
+// NewReloader loads the files described by cfg.
+func NewReloader(cfg Config) (*Reloader, error) {
+	if cfg.ClientAuth && cfg.CAFile == "" {
+		return nil, ErrMissingCA
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = DefaultReloadInterval
+	}
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate, key and CA files. The previous material is
+// kept if any of them fails to load.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("load ca: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("load ca: no certificates found in %s", r.cfg.CAFile)
+		}
+	}
+
+	modTime, err := r.stat()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.pool = pool
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the files whenever one of them changes until ctx is done.
+// Failed reloads are logged and the previous material stays in use.
+func (r *Reloader) Watch(ctx context.Context) {
+	t := time.NewTicker(r.cfg.ReloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if !r.changed() {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			logger.Errorf("Failed to reload certificates: %s", err)
+			continue
+		}
+		logger.Info("Reloaded TLS certificates")
+	}
+}
+
+// ServerConfig returns a tls.Config that always presents the most recently
+// loaded certificate and, when client authentication is enabled, requires
+// clients to present a certificate verified against the most recently loaded
+// CA. Both HTTP/2 and HTTP/1.1 are offered through ALPN. It is meant for the
+// gRPC listener; browsers have no client certificate, so HTTP listeners use
+// HTTPConfig.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return r.config(tls.RequireAndVerifyClientCert)
+}
+
+// HTTPConfig is ServerConfig for listeners serving browsers. A client
+// certificate is verified when one is presented but not required, so callers
+// of the gRPC-Web and REST endpoints can still identify themselves with one.
+func (r *Reloader) HTTPConfig() *tls.Config {
+	return r.config(tls.VerifyClientCertIfGiven)
+}
+
+// ClientAuth reports whether client certificates are verified.
+func (r *Reloader) ClientAuth() bool {
+	return r.cfg.ClientAuth
+}
+
+func (r *Reloader) config(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			c := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   tls.NoClientCert,
+			}
+			if r.cfg.ClientAuth {
+				c.ClientAuth = clientAuth
+				c.ClientCAs = r.pool
+				c.VerifyPeerCertificate = requireDid
+			}
+			return c, nil
+		},
+	}
+}
+
+// Credentials returns gRPC transport credentials backed by ServerConfig.
+func (r *Reloader) Credentials() credentials.TransportCredentials {
+	return credentials.NewTLS(r.ServerConfig())
+}
+
+// changed reports whether any of the files was modified since the last load.
+func (r *Reloader) changed() bool {
+	modTime, err := r.stat()
+	if err != nil {
+		// A file is being replaced; try again on the next tick.
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, t := range modTime {
+		if !t.Equal(r.modTime[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTime := make(map[string]time.Time, 3)
+	for _, name := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.CAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		modTime[name] = info.ModTime()
+	}
+	return modTime, nil
+}
+
+// requireDid rejects verified client certificates without a DID identity.
+// It is also called when no certificate was presented, which only the client
+// auth type decides on.
+func requireDid(raw [][]byte, chains [][]*x509.Certificate) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return ErrNoCallerDid
+	}
+	_, err := CertDid(chains[0][0])
+	return err
+}
+
+// CertDid returns the DID a client certificate identifies. The first URI
+// subject alternative name with the did scheme is used, falling back to the
+// subject common name.
+func CertDid(cert *x509.Certificate) (string, error) {
+	for _, u := range cert.URIs {
+		if u.Scheme != "did" {
+			continue
+		}
+		if d, err := did.Parse(u.String()); err == nil {
+			return d.Base().String(), nil
+		}
+	}
+	if d, err := did.Parse(cert.Subject.CommonName); err == nil {
+		return d.Base().String(), nil
+	}
+	return "", ErrNoCallerDid
+}
+
+// CallerDid returns the DID of the client certificate presented on the
+// connection that carried ctx.
+func CallerDid(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", ErrNoCallerDid
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", ErrNoCallerDid
+	}
+	return CertDid(info.State.VerifiedChains[0][0])
+}
@@ -0,0 +1,233 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func issue(t *testing.T, tmpl *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	signer, signerCert := key, tmpl
+	if parent != nil {
+		signer, signerCert = parent.key, parent.cert
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signerCert, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func writePEM(t *testing.T, dir string, name string, c *testCert) (string, string) {
+	t.Helper()
+	certPath := filepath.Join(dir, name+".pem")
+	keyPath := filepath.Join(dir, name+"-key.pem")
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func newCA(t *testing.T) *testCert {
+	return issue(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newServer(t *testing.T, ca *testCert, serial int64) *testCert {
+	return issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+}
+
+func newClient(t *testing.T, ca *testCert, uri string) *testCert {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(100),
+		Subject:      pkix.Name{CommonName: "client"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if uri != "" {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.URIs = []*url.URL{u}
+	}
+	return issue(t, tmpl, ca)
+}
+
+// handshake dials a TLS server using config and returns the server
+// certificate serial the client saw.
+func handshake(t *testing.T, config *tls.Config, ca *testCert, client *testCert) (*big.Int, error) {
+	t.Helper()
+	l, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		c.(*tls.Conn).Handshake()
+		c.Close()
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	cfg := &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+	if client != nil {
+		cfg.Certificates = []tls.Certificate{{Certificate: [][]byte{client.der}, PrivateKey: client.key}}
+	}
+	conn, err := tls.Dial("tcp", l.Addr().String(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// Client certificate failures surface on the first read under TLS 1.3.
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !isEOF(err) {
+		return nil, err
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber, nil
+}
+
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF)
+}
+
+func TestMutualTLSRequiresDid(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t)
+	caPath, _ := writePEM(t, dir, "ca", ca)
+	certPath, keyPath := writePEM(t, dir, "server", newServer(t, ca, 2))
+
+	r, err := NewReloader(Config{CertFile: certPath, KeyFile: keyPath, CAFile: caPath, ClientAuth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := handshake(t, r.ServerConfig(), ca, newClient(t, ca, "did:sonr:alice")); err != nil {
+		t.Errorf("client with did: %v", err)
+	}
+	if _, err := handshake(t, r.ServerConfig(), ca, newClient(t, ca, "")); err == nil {
+		t.Error("client without did was accepted")
+	}
+	if _, err := handshake(t, r.ServerConfig(), ca, nil); err == nil {
+		t.Error("client without certificate was accepted")
+	}
+}
+
+func TestHTTPConfigAllowsBrowsers(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t)
+	caPath, _ := writePEM(t, dir, "ca", ca)
+	certPath, keyPath := writePEM(t, dir, "server", newServer(t, ca, 2))
+
+	r, err := NewReloader(Config{CertFile: certPath, KeyFile: keyPath, CAFile: caPath, ClientAuth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := handshake(t, r.HTTPConfig(), ca, nil); err != nil {
+		t.Errorf("client without certificate: %v", err)
+	}
+	if _, err := handshake(t, r.HTTPConfig(), ca, newClient(t, ca, "did:sonr:alice")); err != nil {
+		t.Errorf("client with did: %v", err)
+	}
+	if _, err := handshake(t, r.HTTPConfig(), ca, newClient(t, ca, "")); err == nil {
+		t.Error("client certificate without did was accepted")
+	}
+}
+
+func TestReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCA(t)
+	certPath, keyPath := writePEM(t, dir, "server", newServer(t, ca, 2))
+
+	r, err := NewReloader(Config{CertFile: certPath, KeyFile: keyPath, ReloadInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx)
+
+	writePEM(t, dir, "server", newServer(t, ca, 3))
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certPath, future, future)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		serial, err := handshake(t, r.ServerConfig(), ca, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if serial.Int64() == 3 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("rotated certificate was not picked up")
+}
+
+func TestCertDid(t *testing.T) {
+	ca := newCA(t)
+	got, err := CertDid(newClient(t, ca, "did:sonr:alice#key-1").cert)
+	if err != nil || got != "did:sonr:alice" {
+		t.Errorf("CertDid() = %q, %v", got, err)
+	}
+	if _, err := CertDid(newClient(t, ca, "https://example.com").cert); err != ErrNoCallerDid {
+		t.Errorf("CertDid(no did) err = %v", err)
+	}
+	if _, err := NewReloader(Config{ClientAuth: true}); err != ErrMissingCA {
+		t.Errorf("NewReloader(no ca) err = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 
 	// "log"
 
@@ -50,3 +51,12 @@ func NewClient(ctx context.Context, addr string, sname string, passphrase string
 func (c *Client) Keyring() keyring.Keyring {
 	return c.Client.Context.Keyring
 }
+
+// Ping checks that the Tendermint RPC endpoint of the chain is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.Client.RPC == nil {
+		return errors.New("cosmos rpc client is not configured")
+	}
+	_, err := c.Client.RPC.Status(ctx)
+	return err
+}
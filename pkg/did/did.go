@@ -0,0 +1,166 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MethodSonr is the DID method for identifiers issued by the Sonr network.
+const MethodSonr = "sonr"
+
+var (
+	// ErrInvalidDid is returned when a string is not a valid DID URL.
+	ErrInvalidDid = errors.New("invalid did")
+
+	// ErrUnsupportedMethod is returned when resolving a DID whose method this
+	// node cannot resolve.
+	ErrUnsupportedMethod = errors.New("unsupported did method")
+
+	// ErrNotFound is returned when no DID document exists for a DID.
+	ErrNotFound = errors.New("did not found")
+
+	methodRegexp = regexp.MustCompile(`^[a-z0-9]+$`)
+	idRegexp     = regexp.MustCompile(`^([A-Za-z0-9._-]|%[0-9A-Fa-f]{2})*(:([A-Za-z0-9._-]|%[0-9A-Fa-f]{2})*)*$`)
+)
+
+// DID is a parsed DID URL as defined by W3C DID Core:
+//
+//	did:<method>:<id>[/path][?query][#fragment]
+type DID struct {
+	Method   string `json:"method"`
+	ID       string `json:"id"`
+	Path     string `json:"path,omitempty"`
+	Query    string `json:"query,omitempty"`
+	Fragment string `json:"fragment,omitempty"`
+}
+
+// New returns the DID for id under method. Characters that are not allowed in
+// a method-specific id are percent-encoded.
+func New(method string, id string) DID {
+	return DID{Method: method, ID: escapeID(id)}
+}
+
+// Sonr returns the did:sonr identifier for id.
+func Sonr(id string) DID {
+	return New(MethodSonr, id)
+}
+
+// ParseLegacy parses a DID as Parse does, but also accepts a base DID whose
+// method-specific id was issued unescaped, returning it escaped.
+func ParseLegacy(s string) (DID, error) {
+	d, err := Parse(s)
+	if err == nil {
+		return d, nil
+	}
+	rest := strings.TrimPrefix(s, "did:")
+	i := strings.IndexByte(rest, ':')
+	if rest == s || i < 0 || strings.ContainsAny(rest, "/?#") {
+		return DID{}, err
+	}
+	legacy := New(rest[:i], rest[i+1:])
+	if _, perr := Parse(legacy.String()); perr != nil {
+		return DID{}, err
+	}
+	return legacy, nil
+}
+
+// Parse parses a DID or DID URL.
+func Parse(s string) (DID, error) {
+	d := DID{}
+	if !strings.HasPrefix(s, "did:") {
+		return d, fmt.Errorf("%w: missing did scheme", ErrInvalidDid)
+	}
+	rest := s[len("did:"):]
+
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		d.Fragment = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		d.Query = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		d.Path = rest[i:]
+		rest = rest[:i]
+	}
+
+	i := strings.IndexByte(rest, ':')
+	if i < 0 {
+		return DID{}, fmt.Errorf("%w: missing method-specific id", ErrInvalidDid)
+	}
+	d.Method, d.ID = rest[:i], rest[i+1:]
+	if !methodRegexp.MatchString(d.Method) {
+		return DID{}, fmt.Errorf("%w: bad method %q", ErrInvalidDid, d.Method)
+	}
+	if d.ID == "" || strings.HasSuffix(d.ID, ":") || !idRegexp.MatchString(d.ID) {
+		return DID{}, fmt.Errorf("%w: bad method-specific id %q", ErrInvalidDid, d.ID)
+	}
+	return d, nil
+}
+
+// Base returns the DID without its path, query or fragment.
+func (d DID) Base() DID {
+	return DID{Method: d.Method, ID: d.ID}
+}
+
+// Unescaped returns the base DID with its method-specific id unescaped.
+// Before New escaped ids it used them as given, so DIDs issued then, such as
+// those of credential IDs with base64 padding, are stored in this form.
+func (d DID) Unescaped() string {
+	id, err := url.PathUnescape(d.ID)
+	if err != nil {
+		id = d.ID
+	}
+	return "did:" + d.Method + ":" + id
+}
+
+// IsURL reports whether d carries a path, query or fragment.
+func (d DID) IsURL() bool {
+	return d.Path != "" || d.Query != "" || d.Fragment != ""
+}
+
+// WithFragment returns a copy of d pointing at fragment.
+func (d DID) WithFragment(fragment string) DID {
+	d.Fragment = fragment
+	return d
+}
+
+// String returns the DID URL.
+func (d DID) String() string {
+	var b strings.Builder
+	b.WriteString("did:")
+	b.WriteString(d.Method)
+	b.WriteByte(':')
+	b.WriteString(d.ID)
+	b.WriteString(d.Path)
+	if d.Query != "" {
+		b.WriteByte('?')
+		b.WriteString(d.Query)
+	}
+	if d.Fragment != "" {
+		b.WriteByte('#')
+		b.WriteString(d.Fragment)
+	}
+	return b.String()
+}
+
+// escapeID percent-encodes the characters of id that may not appear in a
+// method-specific id.
+func escapeID(id string) string {
+	var b strings.Builder
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '.', c == '-', c == '_', c == ':':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package did
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want DID
+	}{
+		{"did:sonr:abc", DID{Method: "sonr", ID: "abc"}},
+		{"did:web:example.com:user:alice", DID{Method: "web", ID: "example.com:user:alice"}},
+		{"did:sonr:abc/blobs/1?versionId=2#key-1", DID{Method: "sonr", ID: "abc", Path: "/blobs/1", Query: "versionId=2", Fragment: "key-1"}},
+		{"did:sonr:a%3Db#frag", DID{Method: "sonr", ID: "a%3Db", Fragment: "frag"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"sonr:abc",
+		"did:sonr",
+		"did:sonr:",
+		"did:Sonr:abc",
+		"did:sonr:abc:",
+		"did:sonr:a=b",
+		"did:sonr:a%zz",
+	} {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalidDid) {
+			t.Errorf("Parse(%q) err = %v, want ErrInvalidDid", in, err)
+		}
+	}
+}
+
+func TestNewEscapesID(t *testing.T) {
+	d := Sonr("ab==")
+	if got, want := d.String(), "did:sonr:ab%3D%3D"; got != want {
+		t.Errorf("Sonr().String() = %q, want %q", got, want)
+	}
+	if _, err := Parse(d.String()); err != nil {
+		t.Errorf("Parse(Sonr()) error: %v", err)
+	}
+}
+
+func TestLegacyForm(t *testing.T) {
+	d := Sonr("ab==")
+	if got, want := d.Unescaped(), "did:sonr:ab=="; got != want {
+		t.Errorf("Unescaped() = %q, want %q", got, want)
+	}
+	if got := Sonr("alice").Unescaped(); got != "did:sonr:alice" {
+		t.Errorf("Unescaped() of an id needing no escapes = %q", got)
+	}
+
+	// A DID issued unescaped parses to its escaped form.
+	got, err := ParseLegacy("did:sonr:ab==")
+	if err != nil || got != d {
+		t.Errorf("ParseLegacy() = %+v, %v, want %+v", got, err, d)
+	}
+	if got, err := ParseLegacy("did:sonr:alice#key"); err != nil || got.Fragment != "key" {
+		t.Errorf("ParseLegacy() of a valid DID URL = %+v, %v", got, err)
+	}
+	for _, in := range []string{"not-a-did", "did:sonr", "did:sonr:ab==#key", "did:Bad:ab=="} {
+		if _, err := ParseLegacy(in); !errors.Is(err, ErrInvalidDid) {
+			t.Errorf("ParseLegacy(%q) err = %v, want ErrInvalidDid", in, err)
+		}
+	}
+}
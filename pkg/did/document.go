@@ -0,0 +1,125 @@
+package did
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/duo-labs/webauthn/protocol/webauthncbor"
+	"github.com/duo-labs/webauthn/protocol/webauthncose"
+)
+
+// Contexts every document produced by this package declares.
+const (
+	ContextDIDv1   = "https://www.w3.org/ns/did/v1"
+	ContextJWS2020 = "https://w3id.org/security/suites/jws-2020/v1"
+)
+
+// VerificationMethodJWK2020 is the verification method type used for
+// WebAuthn credential keys.
+const VerificationMethodJWK2020 = "JsonWebKey2020"
+
+// Document is a W3C DID document.
+type Document struct {
+	Context            []string             `json:"@context"`
+	ID                 string               `json:"id"`
+	AlsoKnownAs        []string             `json:"alsoKnownAs,omitempty"`
+	Controller         string               `json:"controller,omitempty"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod,omitempty"`
+	Authentication     []string             `json:"authentication,omitempty"`
+	AssertionMethod    []string             `json:"assertionMethod,omitempty"`
+	Service            []Service            `json:"service,omitempty"`
+}
+
+// VerificationMethod is a public key that can act on behalf of the subject.
+type VerificationMethod struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Controller   string `json:"controller"`
+	PublicKeyJwk *JWK   `json:"publicKeyJwk,omitempty"`
+}
+
+// Service is an endpoint advertised by the subject.
+type Service struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// JWK is the public part of a JSON Web Key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKFromCOSE converts a COSE encoded public key, as stored for WebAuthn
+// credentials, to a JWK.
+func JWKFromCOSE(key []byte) (*JWK, error) {
+	parsed, err := webauthncose.ParsePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	switch k := parsed.(type) {
+	case webauthncose.EC2PublicKeyData:
+		crv, err := ec2Curve(k.Curve, k.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{Kty: "EC", Crv: crv, X: enc(k.XCoord), Y: enc(k.YCoord)}, nil
+	case webauthncose.OKPPublicKeyData:
+		// webauthncose does not decode the curve of OKP keys.
+		var params coseCurve
+		if err := webauthncbor.Unmarshal(key, &params); err != nil {
+			return nil, err
+		}
+		crv, ok := okpCurves[params.Curve]
+		if !ok {
+			return nil, fmt.Errorf("unsupported OKP curve %d", params.Curve)
+		}
+		return &JWK{Kty: "OKP", Crv: crv, X: enc(k.XCoord)}, nil
+	case webauthncose.RSAPublicKeyData:
+		// Strip leading zero bytes so the exponent is minimally encoded.
+		e := new(big.Int).SetBytes(k.Exponent).Bytes()
+		return &JWK{Kty: "RSA", N: enc(k.Modulus), E: enc(e)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// coseCurve holds the crv parameter of a COSE key.
+type coseCurve struct {
+	Curve int64 `cbor:"-1,keyasint,omitempty"`
+}
+
+// JWK names of the COSE elliptic curves, by COSE identifier.
+var (
+	ec2Curves = map[int64]string{1: "P-256", 2: "P-384", 3: "P-521", 8: "secp256k1"}
+	okpCurves = map[int64]string{4: "X25519", 5: "X448", 6: "Ed25519", 7: "Ed448"}
+)
+
+// ec2Curve returns the JWK curve of an EC2 key from its COSE curve, or when
+// the key has none from its algorithm.
+func ec2Curve(crv int64, alg int64) (string, error) {
+	if crv != 0 {
+		name, ok := ec2Curves[crv]
+		if !ok {
+			return "", fmt.Errorf("unsupported EC2 curve %d", crv)
+		}
+		return name, nil
+	}
+	switch webauthncose.COSEAlgorithmIdentifier(alg) {
+	case webauthncose.AlgES256:
+		return "P-256", nil
+	case webauthncose.AlgES384:
+		return "P-384", nil
+	case webauthncose.AlgES512:
+		return "P-521", nil
+	default:
+		return "", fmt.Errorf("unsupported EC2 algorithm %d", alg)
+	}
+}
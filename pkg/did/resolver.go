@@ -0,0 +1,102 @@
+package did
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kataras/golog"
+)
+
+var logger = golog.Default.Child("pkg/did")
+
+// NameScheme is the URI scheme used to list a subject's registered names in
+// alsoKnownAs.
+const NameScheme = "sonr"
+
+// Subject is everything the resolver needs to know about the holder of a
+// did:sonr identifier.
+type Subject struct {
+	// Names are the subject's registered names, including the ".snr" suffix.
+	Names []string
+
+	// Keys are the subject's WebAuthn credentials.
+	Keys []Key
+}
+
+// Key is a WebAuthn credential public key.
+type Key struct {
+	// ID identifies the key within the document. It is used as the
+	// verification method fragment.
+	ID string
+
+	// PublicKey is the COSE encoded public key.
+	PublicKey []byte
+}
+
+// Source looks up the subject of a did:sonr identifier. It returns
+// ErrNotFound when the DID has not been issued.
+type Source interface {
+	Subject(ctx context.Context, did string) (*Subject, error)
+}
+
+// Resolver resolves did:sonr identifiers to DID documents.
+type Resolver struct {
+	source Source
+}
+
+// NewResolver returns a Resolver that reads subjects from source.
+func NewResolver(source Source) *Resolver {
+	return &Resolver{source: source}
+}
+
+// Resolve parses s and returns the DID document of its base DID. The path,
+// query and fragment of a DID URL are ignored. DIDs issued with an unescaped
+// id resolve to the document of their escaped form.
+func (r *Resolver) Resolve(ctx context.Context, s string) (*Document, error) {
+	d, err := ParseLegacy(s)
+	if err != nil {
+		return nil, err
+	}
+	if d.Method != MethodSonr {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMethod, d.Method)
+	}
+	base := d.Base()
+	subject, err := r.source.Subject(ctx, base.String())
+	if err != nil {
+		return nil, err
+	}
+	return NewDocument(base, subject)
+}
+
+// NewDocument builds the DID document for subject. Every key becomes a
+// JsonWebKey2020 verification method usable for authentication and
+// assertions. A key that cannot be converted is left out and logged, so one
+// unusual credential does not hide the subject's others.
+func NewDocument(d DID, subject *Subject) (*Document, error) {
+	id := d.Base().String()
+	doc := &Document{
+		Context: []string{ContextDIDv1, ContextJWS2020},
+		ID:      id,
+	}
+	for _, name := range subject.Names {
+		doc.AlsoKnownAs = append(doc.AlsoKnownAs, NameScheme+"://"+name)
+	}
+	for _, k := range subject.Keys {
+		jwk, err := JWKFromCOSE(k.PublicKey)
+		if err != nil {
+			logger.Warnf("Left key %s out of the document of %s: %s", k.ID, id, err)
+			continue
+		}
+		vmID := d.Base().WithFragment(strings.TrimRight(k.ID, "=")).String()
+		doc.VerificationMethod = append(doc.VerificationMethod, VerificationMethod{
+			ID:           vmID,
+			Type:         VerificationMethodJWK2020,
+			Controller:   id,
+			PublicKeyJwk: jwk,
+		})
+		doc.Authentication = append(doc.Authentication, vmID)
+		doc.AssertionMethod = append(doc.AssertionMethod, vmID)
+	}
+	return doc, nil
+}
@@ -0,0 +1,131 @@
+package did
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeSource map[string]*Subject
+
+func (f fakeSource) Subject(ctx context.Context, did string) (*Subject, error) {
+	s, ok := f[did]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s, nil
+}
+
+// es256Key returns a COSE encoded EC2 P-256 public key.
+func es256Key(x, y byte) []byte {
+	buf := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20}
+	buf = append(buf, bytes.Repeat([]byte{x}, 32)...)
+	buf = append(buf, 0x22, 0x58, 0x20)
+	return append(buf, bytes.Repeat([]byte{y}, 32)...)
+}
+
+func TestResolve(t *testing.T) {
+	r := NewResolver(fakeSource{
+		"did:sonr:alice": {
+			Names: []string{"alice.snr"},
+			Keys:  []Key{{ID: "cred1==", PublicKey: es256Key(1, 2)}},
+		},
+	})
+
+	doc, err := r.Resolve(context.Background(), "did:sonr:alice#cred1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != "did:sonr:alice" {
+		t.Errorf("ID = %q", doc.ID)
+	}
+	if len(doc.AlsoKnownAs) != 1 || doc.AlsoKnownAs[0] != "sonr://alice.snr" {
+		t.Errorf("AlsoKnownAs = %v", doc.AlsoKnownAs)
+	}
+	if len(doc.VerificationMethod) != 1 {
+		t.Fatalf("VerificationMethod = %+v", doc.VerificationMethod)
+	}
+	vm := doc.VerificationMethod[0]
+	if vm.ID != "did:sonr:alice#cred1" || vm.Controller != doc.ID || vm.Type != VerificationMethodJWK2020 {
+		t.Errorf("VerificationMethod = %+v", vm)
+	}
+	if vm.PublicKeyJwk.Kty != "EC" || vm.PublicKeyJwk.Crv != "P-256" || vm.PublicKeyJwk.X == "" {
+		t.Errorf("PublicKeyJwk = %+v", vm.PublicKeyJwk)
+	}
+	if len(doc.Authentication) != 1 || doc.Authentication[0] != vm.ID {
+		t.Errorf("Authentication = %v", doc.Authentication)
+	}
+}
+
+func TestResolveLegacy(t *testing.T) {
+	r := NewResolver(fakeSource{
+		"did:sonr:ab%3D%3D": {Keys: []Key{{ID: "cred1", PublicKey: es256Key(1, 2)}}},
+	})
+	doc, err := r.Resolve(context.Background(), "did:sonr:ab==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != "did:sonr:ab%3D%3D" {
+		t.Errorf("ID = %q", doc.ID)
+	}
+}
+
+// okpKey returns a COSE encoded OKP public key on the COSE curve crv.
+func okpKey(crv byte) []byte {
+	buf := []byte{0xa4, 0x01, 0x01, 0x03, 0x27, 0x20, crv, 0x21, 0x58, 0x20}
+	return append(buf, bytes.Repeat([]byte{1}, 32)...)
+}
+
+func TestJWKCurves(t *testing.T) {
+	for _, tt := range []struct {
+		key      []byte
+		kty, crv string
+	}{
+		{es256Key(1, 2), "EC", "P-256"},
+		{okpKey(6), "OKP", "Ed25519"},
+		{okpKey(7), "OKP", "Ed448"},
+	} {
+		jwk, err := JWKFromCOSE(tt.key)
+		if err != nil {
+			t.Errorf("JWKFromCOSE(%s) error: %v", tt.crv, err)
+			continue
+		}
+		if jwk.Kty != tt.kty || jwk.Crv != tt.crv || jwk.X == "" {
+			t.Errorf("JWKFromCOSE(%s) = %+v", tt.crv, jwk)
+		}
+	}
+	if _, err := JWKFromCOSE(okpKey(9)); err == nil {
+		t.Error("JWKFromCOSE accepted an unknown OKP curve")
+	}
+}
+
+func TestNewDocumentSkipsBadKeys(t *testing.T) {
+	doc, err := NewDocument(Sonr("alice"), &Subject{Keys: []Key{
+		{ID: "bad", PublicKey: []byte{0xff}},
+		{ID: "good", PublicKey: es256Key(1, 2)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].ID != "did:sonr:alice#good" {
+		t.Errorf("VerificationMethod = %+v", doc.VerificationMethod)
+	}
+	if len(doc.Authentication) != 1 || len(doc.AssertionMethod) != 1 {
+		t.Errorf("Authentication = %v, AssertionMethod = %v", doc.Authentication, doc.AssertionMethod)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	r := NewResolver(fakeSource{})
+	ctx := context.Background()
+	if _, err := r.Resolve(ctx, "did:sonr:bob"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown did err = %v, want ErrNotFound", err)
+	}
+	if _, err := r.Resolve(ctx, "did:web:example.com"); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("did:web err = %v, want ErrUnsupportedMethod", err)
+	}
+	if _, err := r.Resolve(ctx, "not-a-did"); !errors.Is(err, ErrInvalidDid) {
+		t.Errorf("invalid err = %v, want ErrInvalidDid", err)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package docstore
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until it holds an exclusive flock on f.
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package docstore
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sonr-io/webauthn.io/pkg/blob"
+)
+
+// Kind is the type of a field declared by an object schema.
+type Kind string
+
+// Field kinds accepted by Validate.
+const (
+	KindString    Kind = "string"
+	KindNumber    Kind = "number"
+	KindBool      Kind = "bool"
+	KindArray     Kind = "array"
+	KindTimestamp Kind = "timestamp"
+	KindGeopoint  Kind = "geopoint"
+	KindBlob      Kind = "blob"
+)
+
+// Field is a named, typed field of an object schema.
+type Field struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+}
+
+// Valid reports whether k is a known kind.
+func (k Kind) Valid() bool {
+	switch k {
+	case KindString, KindNumber, KindBool, KindArray, KindTimestamp, KindGeopoint, KindBlob:
+		return true
+	}
+	return false
+}
+
+// validateFields checks that every field has a name, a known kind and that
+// no name is declared twice.
+func validateFields(fields []Field) error {
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f.Name == "" {
+			return fmt.Errorf("%w: field name is required", ErrInvalidSchema)
+		}
+		if !f.Kind.Valid() {
+			return fmt.Errorf("%w: field %q has unknown kind %q", ErrInvalidSchema, f.Name, f.Kind)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("%w: field %q declared twice", ErrInvalidSchema, f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// Validate checks values against fields. Every value must belong to a
+// declared field and match its kind; declared fields may be omitted. Values
+// are expected in the form produced by encoding/json.
+func Validate(fields []Field, values map[string]interface{}) error {
+	kinds := make(map[string]Kind, len(fields))
+	for _, f := range fields {
+		kinds[f.Name] = f.Kind
+	}
+	for name, v := range values {
+		kind, ok := kinds[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidDocument, name)
+		}
+		if v == nil {
+			continue
+		}
+		if !matches(kind, v) {
+			return fmt.Errorf("%w: field %q must be a %s", ErrInvalidDocument, name, kind)
+		}
+	}
+	return nil
+}
+
+func matches(kind Kind, v interface{}) bool {
+	switch kind {
+	case KindString:
+		_, ok := v.(string)
+		return ok
+	case KindNumber:
+		return isNumber(v)
+	case KindBool:
+		_, ok := v.(bool)
+		return ok
+	case KindArray:
+		_, ok := v.([]interface{})
+		return ok
+	case KindTimestamp:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case KindGeopoint:
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 2 {
+			return false
+		}
+		lat, ok1 := m["lat"].(float64)
+		lng, ok2 := m["lng"].(float64)
+		return ok1 && ok2 && math.Abs(lat) <= 90 && math.Abs(lng) <= 180
+	case KindBlob:
+		s, ok := v.(string)
+		return ok && blob.ValidCID(s)
+	}
+	return false
+}
+
+func isNumber(v interface{}) bool {
+	switch n := v.(type) {
+	case float64:
+		return !math.IsNaN(n) && !math.IsInf(n, 0)
+	case float32, int, int32, int64, uint, uint32, uint64:
+		return true
+	}
+	return false
+}
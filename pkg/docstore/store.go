@@ -0,0 +1,605 @@
+package docstore
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/pkg/did"
+)
+
+var (
+	logger = golog.Default.Child("pkg/docstore")
+
+	// ErrNotFound is returned when a bucket, object or document does not
+	// exist in the owner's namespace.
+	ErrNotFound = errors.New("not found")
+
+	// ErrMissingOwner is returned when no owner DID is given.
+	ErrMissingOwner = errors.New("owner did is required")
+
+	// ErrMissingLabel is returned when a bucket or object has no label.
+	ErrMissingLabel = errors.New("label is required")
+
+	// ErrInvalidSchema is returned when an object declares invalid fields.
+	ErrInvalidSchema = errors.New("invalid object schema")
+
+	// ErrInvalidDocument is returned when a document does not match the
+	// schema it is written with.
+	ErrInvalidDocument = errors.New("document does not match schema")
+
+	// ErrObjectInUse is returned when removing an object that a bucket or a
+	// document still depends on.
+	ErrObjectInUse = errors.New("object is in use")
+)
+
+// Bucket is a collection of documents. Documents written to a bucket must
+// match one of the object schemas attached to it.
+type Bucket struct {
+	Did         string    `json:"did"`
+	Owner       string    `json:"owner"`
+	Label       string    `json:"label"`
+	Description string    `json:"description,omitempty"`
+	Kind        string    `json:"kind,omitempty"`
+	ObjectDids  []string  `json:"object_dids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Object is a schema definition: a named set of typed fields.
+type Object struct {
+	Did         string    `json:"did"`
+	Owner       string    `json:"owner"`
+	Label       string    `json:"label"`
+	Description string    `json:"description,omitempty"`
+	Fields      []Field   `json:"fields"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Document is a record stored in a bucket and typed by an object schema.
+type Document struct {
+	ID        string                 `json:"id"`
+	BucketDid string                 `json:"bucket_did"`
+	ObjectDid string                 `json:"object_did"`
+	Values    map[string]interface{} `json:"values"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// namespace holds everything owned by a single DID. It is persisted as one
+// JSON file.
+type namespace struct {
+	Owner     string                          `json:"owner"`
+	Buckets   map[string]*Bucket              `json:"buckets"`
+	Objects   map[string]*Object              `json:"objects"`
+	Documents map[string]map[string]*Document `json:"documents"`
+}
+
+// Store persists buckets, objects and documents on the local filesystem,
+// keyed by owner DID. Owners can only see their own records. It is safe for
+// concurrent use. Other processes, such as the CLI next to a running node,
+// may write the same directory: a namespace is read again whenever its file
+// changed since it was cached, and updates hold a lock on it until written.
+type Store struct {
+	mu     sync.Mutex
+	dir    config.Folder
+	owners map[string]*cached
+}
+
+// cached is a namespace with the version of its file it was read from or
+// written as, nil if there was no file.
+type cached struct {
+	ns   *namespace
+	file os.FileInfo
+}
+
+// Open returns a Store that keeps its files in dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	f := config.Folder(dir)
+	if err := f.MkdirAll(); err != nil {
+		return nil, err
+	}
+	return &Store{
+		dir:    f,
+		owners: make(map[string]*cached),
+	}, nil
+}
+
+// CreateBucket creates a bucket owned by owner. Every object in
+// b.ObjectDids must already exist.
+func (s *Store) CreateBucket(owner string, b Bucket) (Bucket, error) {
+	if b.Label == "" {
+		return Bucket{}, ErrMissingLabel
+	}
+	err := s.update(owner, func(ns *namespace) error {
+		if err := ns.checkObjects(b.ObjectDids); err != nil {
+			return err
+		}
+		id, err := newDid()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		b.Did = id
+		b.Owner = owner
+		b.ObjectDids = dedupe(b.ObjectDids)
+		b.CreatedAt = now
+		b.UpdatedAt = now
+		ns.Buckets[b.Did] = cloneBucket(&b)
+		return nil
+	})
+	return b, err
+}
+
+// Bucket returns the owner's bucket with the given DID.
+func (s *Store) Bucket(owner string, id string) (Bucket, error) {
+	var b Bucket
+	err := s.view(owner, func(ns *namespace) error {
+		found, ok := ns.Buckets[id]
+		if !ok {
+			return ErrNotFound
+		}
+		b = *cloneBucket(found)
+		return nil
+	})
+	return b, err
+}
+
+// Buckets returns every bucket owned by owner, oldest first.
+func (s *Store) Buckets(owner string) ([]Bucket, error) {
+	var out []Bucket
+	err := s.view(owner, func(ns *namespace) error {
+		for _, b := range ns.Buckets {
+			out = append(out, *cloneBucket(b))
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, err
+}
+
+// UpdateBucket applies fn to the bucket and stores the result. Objects may
+// only be detached once no document in the bucket uses them.
+func (s *Store) UpdateBucket(owner string, id string, fn func(*Bucket) error) (Bucket, error) {
+	var b Bucket
+	err := s.update(owner, func(ns *namespace) error {
+		found, ok := ns.Buckets[id]
+		if !ok {
+			return ErrNotFound
+		}
+		b = *cloneBucket(found)
+		if err := fn(&b); err != nil {
+			return err
+		}
+		b.Did, b.Owner, b.CreatedAt = found.Did, found.Owner, found.CreatedAt
+		b.ObjectDids = dedupe(b.ObjectDids)
+		if err := ns.checkObjects(b.ObjectDids); err != nil {
+			return err
+		}
+		for _, d := range ns.Documents[id] {
+			if !contains(b.ObjectDids, d.ObjectDid) {
+				return fmt.Errorf("%w: documents in the bucket use %s", ErrObjectInUse, d.ObjectDid)
+			}
+		}
+		b.UpdatedAt = time.Now()
+		ns.Buckets[id] = cloneBucket(&b)
+		return nil
+	})
+	return b, err
+}
+
+// DeleteBucket deletes the bucket and every document in it.
+func (s *Store) DeleteBucket(owner string, id string) error {
+	return s.update(owner, func(ns *namespace) error {
+		if _, ok := ns.Buckets[id]; !ok {
+			return ErrNotFound
+		}
+		delete(ns.Buckets, id)
+		delete(ns.Documents, id)
+		return nil
+	})
+}
+
+// CreateObject creates an object schema owned by owner.
+func (s *Store) CreateObject(owner string, o Object) (Object, error) {
+	if o.Label == "" {
+		return Object{}, ErrMissingLabel
+	}
+	if err := validateFields(o.Fields); err != nil {
+		return Object{}, err
+	}
+	err := s.update(owner, func(ns *namespace) error {
+		id, err := newDid()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		o.Did = id
+		o.Owner = owner
+		o.CreatedAt = now
+		o.UpdatedAt = now
+		ns.Objects[o.Did] = cloneObject(&o)
+		return nil
+	})
+	return o, err
+}
+
+// Object returns the owner's object schema with the given DID.
+func (s *Store) Object(owner string, id string) (Object, error) {
+	var o Object
+	err := s.view(owner, func(ns *namespace) error {
+		found, ok := ns.Objects[id]
+		if !ok {
+			return ErrNotFound
+		}
+		o = *cloneObject(found)
+		return nil
+	})
+	return o, err
+}
+
+// Objects returns every object schema owned by owner, oldest first.
+func (s *Store) Objects(owner string) ([]Object, error) {
+	var out []Object
+	err := s.view(owner, func(ns *namespace) error {
+		for _, o := range ns.Objects {
+			out = append(out, *cloneObject(o))
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, err
+}
+
+// UpdateObject applies fn to the object schema and stores the result.
+// Existing documents are not rewritten; the new schema applies to later
+// writes.
+func (s *Store) UpdateObject(owner string, id string, fn func(*Object) error) (Object, error) {
+	var o Object
+	err := s.update(owner, func(ns *namespace) error {
+		found, ok := ns.Objects[id]
+		if !ok {
+			return ErrNotFound
+		}
+		o = *cloneObject(found)
+		if err := fn(&o); err != nil {
+			return err
+		}
+		o.Did, o.Owner, o.CreatedAt = found.Did, found.Owner, found.CreatedAt
+		if o.Label == "" {
+			return ErrMissingLabel
+		}
+		if err := validateFields(o.Fields); err != nil {
+			return err
+		}
+		o.UpdatedAt = time.Now()
+		ns.Objects[id] = cloneObject(&o)
+		return nil
+	})
+	return o, err
+}
+
+// DeleteObject deletes an object schema that no bucket uses.
+func (s *Store) DeleteObject(owner string, id string) error {
+	return s.update(owner, func(ns *namespace) error {
+		if _, ok := ns.Objects[id]; !ok {
+			return ErrNotFound
+		}
+		for _, b := range ns.Buckets {
+			if contains(b.ObjectDids, id) {
+				return fmt.Errorf("%w: attached to bucket %s", ErrObjectInUse, b.Did)
+			}
+		}
+		delete(ns.Objects, id)
+		return nil
+	})
+}
+
+// PutDocument validates d against its object schema and stores it in the
+// bucket, replacing any document with the same ID. A new ID is assigned when
+// d.ID is empty.
+func (s *Store) PutDocument(owner string, d Document) (Document, error) {
+	err := s.update(owner, func(ns *namespace) error {
+		b, ok := ns.Buckets[d.BucketDid]
+		if !ok {
+			return fmt.Errorf("bucket %w", ErrNotFound)
+		}
+		if !contains(b.ObjectDids, d.ObjectDid) {
+			return fmt.Errorf("%w: object %s is not attached to the bucket", ErrInvalidDocument, d.ObjectDid)
+		}
+		o, ok := ns.Objects[d.ObjectDid]
+		if !ok {
+			return fmt.Errorf("object %w", ErrNotFound)
+		}
+		if err := Validate(o.Fields, d.Values); err != nil {
+			return err
+		}
+
+		if d.ID == "" {
+			id, err := randomID()
+			if err != nil {
+				return err
+			}
+			d.ID = id
+		}
+		docs := ns.Documents[d.BucketDid]
+		if docs == nil {
+			docs = make(map[string]*Document)
+			ns.Documents[d.BucketDid] = docs
+		}
+		now := time.Now()
+		d.CreatedAt = now
+		if prev, ok := docs[d.ID]; ok {
+			d.CreatedAt = prev.CreatedAt
+		}
+		d.UpdatedAt = now
+		docs[d.ID] = cloneDocument(&d)
+		return nil
+	})
+	return d, err
+}
+
+// Document returns a document from the owner's bucket.
+func (s *Store) Document(owner string, bucketDid string, id string) (Document, error) {
+	var d Document
+	err := s.view(owner, func(ns *namespace) error {
+		found, ok := ns.Documents[bucketDid][id]
+		if !ok {
+			return ErrNotFound
+		}
+		d = *cloneDocument(found)
+		return nil
+	})
+	return d, err
+}
+
+// Documents returns every document in the owner's bucket, oldest first.
+func (s *Store) Documents(owner string, bucketDid string) ([]Document, error) {
+	var out []Document
+	err := s.view(owner, func(ns *namespace) error {
+		if _, ok := ns.Buckets[bucketDid]; !ok {
+			return ErrNotFound
+		}
+		for _, d := range ns.Documents[bucketDid] {
+			out = append(out, *cloneDocument(d))
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, err
+}
+
+// DeleteDocument removes a document from the owner's bucket.
+func (s *Store) DeleteDocument(owner string, bucketDid string, id string) error {
+	return s.update(owner, func(ns *namespace) error {
+		if _, ok := ns.Documents[bucketDid][id]; !ok {
+			return ErrNotFound
+		}
+		delete(ns.Documents[bucketDid], id)
+		return nil
+	})
+}
+
+// view runs fn against the owner's namespace.
+func (s *Store) view(owner string, fn func(*namespace) error) error {
+	if owner == "" {
+		return ErrMissingOwner
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ns, err := s.load(owner)
+	if err != nil {
+		return err
+	}
+	return fn(ns)
+}
+
+// update runs fn against the owner's namespace and persists the result. If
+// fn or the write fails the cached namespace is discarded so that the next
+// call reloads the last persisted state. The owner's lock file is held from
+// the read to the write, so an update by another process is never lost.
+func (s *Store) update(owner string, fn func(*namespace) error) error {
+	if owner == "" {
+		return ErrMissingOwner
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock(owner)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ns, err := s.load(owner)
+	if err != nil {
+		return err
+	}
+	if err := fn(ns); err != nil {
+		delete(s.owners, owner)
+		return err
+	}
+	if err := s.save(owner, ns); err != nil {
+		logger.Errorf("Failed to persist records of %s: %s", owner, err)
+		delete(s.owners, owner)
+		return err
+	}
+	return nil
+}
+
+// lock takes an exclusive lock on the owner's lock file, waiting for other
+// processes to release it. The namespace file itself is replaced on every
+// save, so the lock is kept in a file of its own.
+func (s *Store) lock(owner string) (func(), error) {
+	f, err := os.OpenFile(s.dir.JoinPath(fileName(owner)+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// Closing the file releases the lock.
+	return func() { f.Close() }, nil
+}
+
+// load returns the owner's namespace, from the cache unless its file was
+// replaced since.
+func (s *Store) load(owner string) (*namespace, error) {
+	f, err := os.Open(s.dir.JoinPath(fileName(owner)))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var info os.FileInfo
+	if f != nil {
+		defer f.Close()
+		// The version is taken from the open file, so it is the one read
+		// below even if the file is replaced meanwhile.
+		if info, err = f.Stat(); err != nil {
+			return nil, err
+		}
+	}
+	if c, ok := s.owners[owner]; ok && sameVersion(c.file, info) {
+		return c.ns, nil
+	}
+
+	ns := &namespace{Owner: owner}
+	if f != nil {
+		buf, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(buf, ns); err != nil {
+			return nil, err
+		}
+	}
+	if ns.Buckets == nil {
+		ns.Buckets = make(map[string]*Bucket)
+	}
+	if ns.Objects == nil {
+		ns.Objects = make(map[string]*Object)
+	}
+	if ns.Documents == nil {
+		ns.Documents = make(map[string]map[string]*Document)
+	}
+	s.owners[owner] = &cached{ns: ns, file: info}
+	return ns, nil
+}
+
+// save writes the namespace to a temporary file and renames it into place so
+// a crash never leaves a partially written file. The cache records the
+// written file as its version.
+func (s *Store) save(owner string, ns *namespace) error {
+	buf, err := json.Marshal(ns)
+	if err != nil {
+		return err
+	}
+	name := fileName(ns.Owner)
+	if err := s.dir.WriteFile(name+".tmp", buf); err != nil {
+		return err
+	}
+	// Renaming keeps the file's identity and modification time, so it is
+	// taken before another process can replace the file.
+	info, err := os.Stat(s.dir.JoinPath(name + ".tmp"))
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(s.dir.JoinPath(name+".tmp"), s.dir.JoinPath(name)); err != nil {
+		return err
+	}
+	s.owners[owner] = &cached{ns: ns, file: info}
+	return nil
+}
+
+// sameVersion reports whether a and b describe the same version of a file.
+// Every save renames a new file into place, so a write by another process
+// changes the file's identity even when its size and modification time
+// match.
+func sameVersion(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// checkObjects reports ErrNotFound if any of ids is not an object in ns.
+func (ns *namespace) checkObjects(ids []string) error {
+	for _, id := range ids {
+		if _, ok := ns.Objects[id]; !ok {
+			return fmt.Errorf("object %s %w", id, ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// fileName returns the namespace file of owner. DIDs contain characters
+// that are not portable in file names, so the owner is hashed.
+func fileName(owner string) string {
+	sum := sha256.Sum256([]byte(owner))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+func newDid() (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	return did.Sonr(id).String(), nil
+}
+
+func randomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(list []string) []string {
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if !contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func cloneBucket(b *Bucket) *Bucket {
+	c := *b
+	c.ObjectDids = append([]string(nil), b.ObjectDids...)
+	return &c
+}
+
+func cloneObject(o *Object) *Object {
+	c := *o
+	c.Fields = append([]Field(nil), o.Fields...)
+	return &c
+}
+
+func cloneDocument(d *Document) *Document {
+	c := *d
+	c.Values = make(map[string]interface{}, len(d.Values))
+	for k, v := range d.Values {
+		c.Values[k] = v
+	}
+	return &c
+}
@@ -0,0 +1,210 @@
+package docstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const (
+	alice = "did:sonr:alice"
+	bob   = "did:sonr:bob"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func createSchema(t *testing.T, s *Store) (Bucket, Object) {
+	t.Helper()
+	o, err := s.CreateObject(alice, Object{
+		Label: "profile",
+		Fields: []Field{
+			{Name: "name", Kind: KindString},
+			{Name: "age", Kind: KindNumber},
+			{Name: "avatar", Kind: KindBlob},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.CreateBucket(alice, Bucket{Label: "profiles", ObjectDids: []string{o.Did}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, o
+}
+
+func TestDocumentsAreValidated(t *testing.T) {
+	s := newTestStore(t)
+	b, o := createSchema(t, s)
+
+	d, err := s.PutDocument(alice, Document{
+		BucketDid: b.Did,
+		ObjectDid: o.Did,
+		Values:    map[string]interface{}{"name": "Alice", "age": float64(30)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ID == "" {
+		t.Error("PutDocument() did not assign an ID")
+	}
+
+	bad := []map[string]interface{}{
+		{"name": 12},
+		{"age": "thirty"},
+		{"avatar": "not-a-cid"},
+		{"nickname": "al"},
+	}
+	for _, values := range bad {
+		_, err := s.PutDocument(alice, Document{BucketDid: b.Did, ObjectDid: o.Did, Values: values})
+		if !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("PutDocument(%v) err = %v, want ErrInvalidDocument", values, err)
+		}
+	}
+}
+
+func TestOwnersAreIsolated(t *testing.T) {
+	s := newTestStore(t)
+	b, o := createSchema(t, s)
+
+	if _, err := s.Bucket(bob, b.Did); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Bucket(bob) err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteObject(bob, o.Did); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteObject(bob) err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.CreateBucket(bob, Bucket{Label: "x", ObjectDids: []string{o.Did}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CreateBucket(bob, alice's object) err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestObjectInUse(t *testing.T) {
+	s := newTestStore(t)
+	b, o := createSchema(t, s)
+	if _, err := s.PutDocument(alice, Document{BucketDid: b.Did, ObjectDid: o.Did}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteObject(alice, o.Did); !errors.Is(err, ErrObjectInUse) {
+		t.Errorf("DeleteObject() err = %v, want ErrObjectInUse", err)
+	}
+	_, err := s.UpdateBucket(alice, b.Did, func(b *Bucket) error {
+		b.ObjectDids = nil
+		return nil
+	})
+	if !errors.Is(err, ErrObjectInUse) {
+		t.Errorf("UpdateBucket(detach) err = %v, want ErrObjectInUse", err)
+	}
+}
+
+func TestInvalidSchema(t *testing.T) {
+	s := newTestStore(t)
+	for _, fields := range [][]Field{
+		{{Name: "", Kind: KindString}},
+		{{Name: "a", Kind: "date"}},
+		{{Name: "a", Kind: KindString}, {Name: "a", Kind: KindBool}},
+	} {
+		if _, err := s.CreateObject(alice, Object{Label: "x", Fields: fields}); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("CreateObject(%v) err = %v, want ErrInvalidSchema", fields, err)
+		}
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := createSchema(t, s)
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Bucket(alice, b.Did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Label != "profiles" || len(got.ObjectDids) != 1 {
+		t.Errorf("Bucket() after reopen = %+v", got)
+	}
+}
+
+func TestOtherWritersAreSeen(t *testing.T) {
+	dir := t.TempDir()
+	node, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, o := createSchema(t, node)
+
+	// A write by another store is seen by the one that cached the
+	// namespace before it.
+	if _, err := cli.CreateObject(alice, Object{Label: "note", Fields: []Field{{Name: "text", Kind: KindString}}}); err != nil {
+		t.Fatal(err)
+	}
+	if objects, err := node.Objects(alice); err != nil || len(objects) != 2 {
+		t.Fatalf("Objects() after another writer = %v, %v", objects, err)
+	}
+
+	// Writing on from the refreshed namespace keeps the other store's write.
+	if _, err := node.CreateBucket(alice, Bucket{Label: "more", ObjectDids: []string{o.Did}}); err != nil {
+		t.Fatal(err)
+	}
+	buckets, err := cli.Buckets(alice)
+	if err != nil || len(buckets) != 2 || buckets[0].Did != b.Did {
+		t.Fatalf("Buckets() = %v, %v", buckets, err)
+	}
+	if objects, err := cli.Objects(alice); err != nil || len(objects) != 2 {
+		t.Fatalf("Objects() lost a write: %v, %v", objects, err)
+	}
+}
+
+func TestConcurrentWritersKeepEveryUpdate(t *testing.T) {
+	dir := t.TempDir()
+	node, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, o := createSchema(t, node)
+
+	// Each store stands in for a process of its own; without the file lock
+	// their read-modify-write cycles overwrite each other.
+	const writes = 20
+	var wg sync.WaitGroup
+	for i, s := range []*Store{node, cli} {
+		wg.Add(1)
+		go func(i int, s *Store) {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				d := Document{ID: fmt.Sprintf("%d-%d", i, j), BucketDid: b.Did, ObjectDid: o.Did}
+				if _, err := s.PutDocument(alice, d); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	docs, err := node.Documents(alice, b.Did)
+	if err != nil || len(docs) != 2*writes {
+		t.Fatalf("Documents() = %d documents, %v; want %d", len(docs), err, 2*writes)
+	}
+}
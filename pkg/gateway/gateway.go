@@ -0,0 +1,272 @@
+// Package gateway transcodes HTTP/JSON requests into calls on a gRPC service
+// following the service's google.api.http rules. Calls are dispatched
+// in-process through the service descriptor's handlers, so the server's
+// interceptors, peer TLS state and status codes apply exactly as they do over
+// gRPC.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrUnknownMethod     = errors.New("rule names a method the service does not have")
+	ErrUnsupportedMethod = errors.New("client streaming methods cannot be transcoded")
+	ErrInvalidTemplate   = errors.New("invalid path template")
+)
+
+// Rule binds an RPC to an HTTP method and path template, mirroring a
+// google.api.http option.
+type Rule struct {
+	// Method is the RPC name, e.g. "AccessName".
+	Method string
+
+	// Verb is the HTTP method.
+	Verb string
+
+	// Path is the path template. Variables name request fields, e.g.
+	// "/access/name/{name}".
+	Path string
+
+	// Body is "*" when the request body is decoded into the request message.
+	// When empty, fields not bound by the path are read from the query.
+	Body string
+}
+
+// Option configures a Gateway.
+type Option func(*Gateway)
+
+// WithUnaryInterceptor runs i around every unary call, as the gRPC server
+// does for its own unary interceptor.
+func WithUnaryInterceptor(i grpc.UnaryServerInterceptor) Option {
+	return func(g *Gateway) {
+		g.unary = i
+	}
+}
+
+// WithStreamInterceptor runs i around every server streaming call.
+func WithStreamInterceptor(i grpc.StreamServerInterceptor) Option {
+	return func(g *Gateway) {
+		g.stream = i
+	}
+}
+
+// Gateway is an http.Handler serving a gRPC service over HTTP/JSON.
+type Gateway struct {
+	mux    *runtime.ServeMux
+	srv    interface{}
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+}
+
+// New returns a Gateway serving rules against srv, an implementation of the
+// service described by desc.
+func New(desc *grpc.ServiceDesc, srv interface{}, rules []Rule, opts ...Option) (*Gateway, error) {
+	g := &Gateway{
+		mux: runtime.NewServeMux(
+			runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{OrigName: true, EmitDefaults: true}),
+		),
+		srv: srv,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	for _, rule := range rules {
+		pattern, params, err := compile(rule.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Method, err)
+		}
+		h, err := g.handler(desc, rule, params)
+		if err != nil {
+			return nil, err
+		}
+		g.mux.Handle(rule.Verb, pattern, h)
+	}
+	return g, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// handler returns the runtime handler for the method rule names.
+func (g *Gateway) handler(desc *grpc.ServiceDesc, rule Rule, params []string) (runtime.HandlerFunc, error) {
+	fullMethod := fmt.Sprintf("/%s/%s", desc.ServiceName, rule.Method)
+	filter := pathFilter(params)
+	for i := range desc.Methods {
+		if desc.Methods[i].MethodName == rule.Method {
+			md := desc.Methods[i]
+			return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+				g.serveUnary(w, r, md, rule, pathParams, filter)
+			}, nil
+		}
+	}
+	for i := range desc.Streams {
+		if desc.Streams[i].StreamName == rule.Method {
+			sd := desc.Streams[i]
+			if sd.ClientStreams {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedMethod, rule.Method)
+			}
+			return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+				g.serveStream(w, r, sd, fullMethod, rule, pathParams, filter)
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, rule.Method)
+}
+
+// serveUnary decodes the request, calls the method through the unary
+// interceptor and writes its response or status.
+func (g *Gateway) serveUnary(w http.ResponseWriter, r *http.Request, md grpc.MethodDesc, rule Rule, pathParams map[string]string, filter *utilities.DoubleArray) {
+	inbound, outbound := runtime.MarshalerForRequest(g.mux, r)
+	ctx, err := g.context(r)
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+		return
+	}
+
+	ts := &runtime.ServerTransportStream{}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, ts)
+	dec := func(v interface{}) error {
+		msg, ok := v.(proto.Message)
+		if !ok {
+			return status.Errorf(codes.Internal, "request %T is not a protobuf message", v)
+		}
+		return decode(msg, r, inbound, rule, pathParams, filter)
+	}
+	resp, err := md.Handler(g.srv, ctx, dec, g.unary)
+	ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{
+		HeaderMD:  ts.Header(),
+		TrailerMD: ts.Trailer(),
+	})
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+		return
+	}
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		runtime.HTTPError(ctx, g.mux, outbound, w, r, status.Errorf(codes.Internal, "response %T is not a protobuf message", resp))
+		return
+	}
+	runtime.ForwardResponseMessage(ctx, g.mux, outbound, w, r, msg, g.mux.GetForwardResponseOptions()...)
+}
+
+// context carries the request's headers as incoming metadata and its
+// connection as the gRPC peer, so mTLS callers are identified the same way
+// on either transport.
+func (g *Gateway) context(r *http.Request) (context.Context, error) {
+	ctx, err := runtime.AnnotateIncomingContext(r.Context(), g.mux, r)
+	if err != nil {
+		return r.Context(), status.Error(codes.InvalidArgument, err.Error())
+	}
+	p := &peer.Peer{Addr: remoteAddr(r.RemoteAddr)}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{
+			State:          *r.TLS,
+			CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		}
+	}
+	return peer.NewContext(ctx, p), nil
+}
+
+// decode fills msg from the body, then the path, then the query string.
+// Path variables override fields set by the body.
+func decode(msg proto.Message, r *http.Request, inbound runtime.Marshaler, rule Rule, pathParams map[string]string, filter *utilities.DoubleArray) error {
+	if rule.Body == "*" {
+		if err := inbound.NewDecoder(r.Body).Decode(msg); err != nil && err != io.EOF {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+	for name, value := range pathParams {
+		if err := runtime.PopulateFieldFromPath(msg, name, value); err != nil {
+			return status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", name, err)
+		}
+	}
+	if rule.Body == "" {
+		if err := r.ParseForm(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		if err := runtime.PopulateQueryParameters(msg, r.Form, filter); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+	return nil
+}
+
+// compile turns a path template into a runtime pattern, returning the field
+// names its variables bind. Only literal and single segment variables are
+// supported. Templates have no custom verb, so a colon in the last segment,
+// as in a DID, is part of the value.
+func compile(tmpl string) (runtime.Pattern, []string, error) {
+	if !strings.HasPrefix(tmpl, "/") || len(tmpl) < 2 {
+		return runtime.Pattern{}, nil, fmt.Errorf("%w: %q", ErrInvalidTemplate, tmpl)
+	}
+	var (
+		ops    []int
+		pool   []string
+		params []string
+	)
+	index := func(s string) int {
+		for i, p := range pool {
+			if p == s {
+				return i
+			}
+		}
+		pool = append(pool, s)
+		return len(pool) - 1
+	}
+	for _, seg := range strings.Split(tmpl[1:], "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := seg[1 : len(seg)-1]
+			if name == "" || strings.ContainsAny(name, "{}=*/") {
+				return runtime.Pattern{}, nil, fmt.Errorf("%w: %q", ErrInvalidTemplate, tmpl)
+			}
+			ops = append(ops,
+				int(utilities.OpPush), 0,
+				int(utilities.OpConcatN), 1,
+				int(utilities.OpCapture), index(name),
+			)
+			params = append(params, name)
+			continue
+		}
+		if seg == "" || strings.ContainsAny(seg, "{}*:") {
+			return runtime.Pattern{}, nil, fmt.Errorf("%w: %q", ErrInvalidTemplate, tmpl)
+		}
+		ops = append(ops, int(utilities.OpLitPush), index(seg))
+	}
+	pattern, err := runtime.NewPattern(1, ops, pool, "", runtime.AssumeColonVerbOpt(false))
+	if err != nil {
+		return runtime.Pattern{}, nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return pattern, params, nil
+}
+
+// pathFilter excludes path bound fields from query parameter parsing.
+func pathFilter(params []string) *utilities.DoubleArray {
+	seqs := make([][]string, 0, len(params))
+	for _, p := range params {
+		seqs = append(seqs, strings.Split(p, "."))
+	}
+	return utilities.NewDoubleArray(seqs)
+}
+
+// remoteAddr is the HTTP client's address as a net.Addr.
+type remoteAddr string
+
+func (a remoteAddr) Network() string { return "tcp" }
+func (a remoteAddr) String() string  { return string(a) }
@@ -0,0 +1,186 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	if in.Value == "missing" {
+		return nil, status.Error(codes.NotFound, "no such value")
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		return wrapperspb.String(in.Value + ":" + md.Get("authorization")[0]), nil
+	}
+	return in, nil
+}
+
+func (echoServer) Repeat(in *wrapperspb.StringValue, stream grpc.ServerStream) error {
+	for i := 0; i < 2; i++ {
+		if err := stream.SendMsg(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var echoDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.(echoServer).Echo(ctx, req.(*wrapperspb.StringValue))
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Echo"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName: "Repeat",
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			in := new(wrapperspb.StringValue)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			return srv.(echoServer).Repeat(in, stream)
+		},
+		ServerStreams: true,
+	}},
+}
+
+func newGateway(t *testing.T, opts ...Option) *Gateway {
+	t.Helper()
+	g, err := New(&echoDesc, echoServer{}, []Rule{
+		{Method: "Echo", Verb: "GET", Path: "/echo/{value}"},
+		{Method: "Echo", Verb: "POST", Path: "/echo", Body: "*"},
+		{Method: "Repeat", Verb: "GET", Path: "/repeat/{value}"},
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestUnary(t *testing.T) {
+	g := newGateway(t)
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/echo/hello", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `"hello"` {
+		t.Fatalf("GET: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", "/echo", strings.NewReader(`"posted"`)))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `"posted"` {
+		t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/echo/did:sonr:alice", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `"did:sonr:alice"` {
+		t.Fatalf("GET with colons: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/echo/hi", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	g.ServeHTTP(w, r)
+	if strings.TrimSpace(w.Body.String()) != `"hi:Bearer token"` {
+		t.Fatalf("metadata not forwarded: %s", w.Body.String())
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	g := newGateway(t)
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/echo/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404, got %d", w.Code)
+	}
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != int(codes.NotFound) || body.Message != "no such value" {
+		t.Fatalf("unexpected error body %+v", body)
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("DELETE", "/echo/x", nil))
+	if w.Code == http.StatusOK {
+		t.Fatal("unmapped verb should not succeed")
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	denied := status.Error(codes.Unauthenticated, "denied")
+	var method string
+	g := newGateway(t, WithUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method = info.FullMethod
+		return nil, denied
+	}))
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/echo/hello", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("want 401, got %d", w.Code)
+	}
+	if method != "/test.Echo/Echo" {
+		t.Fatalf("interceptor saw %q", method)
+	}
+}
+
+func TestStream(t *testing.T) {
+	g := newGateway(t)
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", "/repeat/tick", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", w.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"result":"tick"}` {
+		t.Fatalf("unexpected chunks %q", lines)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{Method: "Nope", Verb: "GET", Path: "/nope"},
+		{Method: "Echo", Verb: "GET", Path: "echo"},
+		{Method: "Echo", Verb: "GET", Path: "/echo/{}"},
+	} {
+		if _, err := New(&echoDesc, echoServer{}, []Rule{rule}); err == nil {
+			t.Errorf("%+v: expected an error", rule)
+		}
+	}
+	if _, err := New(&echoDesc, echoServer{}, []Rule{{Method: "Nope", Verb: "GET", Path: "/nope"}}); !errors.Is(err, ErrUnknownMethod) {
+		t.Errorf("want ErrUnknownMethod, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serveStream calls a server streaming method, writing each message as a
+// delimited {"result": ...} chunk. Errors after the first message are written
+// as a final {"error": ...} chunk since the status line has been sent.
+func (g *Gateway) serveStream(w http.ResponseWriter, r *http.Request, sd grpc.StreamDesc, fullMethod string, rule Rule, pathParams map[string]string, filter *utilities.DoubleArray) {
+	inbound, outbound := runtime.MarshalerForRequest(g.mux, r)
+	ctx, err := g.context(r)
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+		return
+	}
+
+	ss := &serverStream{
+		ctx:       ctx,
+		w:         w,
+		marshaler: outbound,
+		decode: func(msg proto.Message) error {
+			return decode(msg, r, inbound, rule, pathParams, filter)
+		},
+	}
+	if g.stream != nil {
+		info := &grpc.StreamServerInfo{FullMethod: fullMethod, IsServerStream: true}
+		err = g.stream(g.srv, ss, info, sd.Handler)
+	} else {
+		err = sd.Handler(g.srv, ss)
+	}
+	if err == nil {
+		ss.writeHeader()
+		return
+	}
+	if !ss.wroteHeader {
+		ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{HeaderMD: ss.header, TrailerMD: ss.trailer})
+		runtime.HTTPError(ctx, g.mux, outbound, w, r, err)
+		return
+	}
+	ss.writeChunk("error", status.Convert(err).Proto())
+}
+
+// serverStream adapts an HTTP response to grpc.ServerStream.
+type serverStream struct {
+	ctx         context.Context
+	w           http.ResponseWriter
+	marshaler   runtime.Marshaler
+	decode      func(proto.Message) error
+	received    bool
+	wroteHeader bool
+	header      metadata.MD
+	trailer     metadata.MD
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SetHeader(md metadata.MD) error {
+	if s.wroteHeader {
+		return errors.New("headers already sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *serverStream) SendHeader(md metadata.MD) error {
+	if err := s.SetHeader(md); err != nil {
+		return err
+	}
+	s.writeHeader()
+	return nil
+}
+
+func (s *serverStream) SetTrailer(md metadata.MD) {
+	s.trailer = metadata.Join(s.trailer, md)
+}
+
+// SendMsg writes m as the next chunk of the response.
+func (s *serverStream) SendMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "response %T is not a protobuf message", m)
+	}
+	s.writeHeader()
+	return s.writeChunk("result", msg)
+}
+
+// RecvMsg decodes the request the first time it is called. Server streaming
+// methods receive exactly one message.
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if s.received {
+		return io.EOF
+	}
+	s.received = true
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "request %T is not a protobuf message", m)
+	}
+	return s.decode(msg)
+}
+
+func (s *serverStream) writeHeader() {
+	if s.wroteHeader {
+		return
+	}
+	s.wroteHeader = true
+	for k, vs := range s.header {
+		for _, v := range vs {
+			s.w.Header().Add(fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, k), v)
+		}
+	}
+	s.w.Header().Set("Content-Type", s.marshaler.ContentType())
+	s.w.WriteHeader(http.StatusOK)
+}
+
+func (s *serverStream) writeChunk(key string, msg proto.Message) error {
+	buf, err := s.marshaler.Marshal(map[string]proto.Message{key: msg})
+	if err != nil {
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	if d, ok := s.marshaler.(runtime.Delimited); ok {
+		buf = append(buf, d.Delimiter()...)
+	}
+	if _, err := s.w.Write(buf); err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+// Package grpcweb serves gRPC-Web and gRPC-Web-text requests from browsers by
+// translating them into native gRPC requests on a grpc.Server. Calls pass
+// through the server's interceptors and credentials as any other call does.
+//
+// Responses carry gRPC trailers in a final length-prefixed frame flagged 0x80
+// since browsers cannot read HTTP trailers. In text mode request and response
+// bodies are base64 encoded, which lets clients stream over HTTP/1.1 with
+// XMLHttpRequest.
+package grpcweb
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypeWeb  = "application/grpc-web"
+	contentTypeText = "application/grpc-web-text"
+	contentTypeGRPC = "application/grpc"
+
+	// trailerFlag marks the frame holding the call's trailers.
+	trailerFlag = 0x80
+
+	// preflightMaxAge is how long browsers may cache a preflight response.
+	preflightMaxAge = 10 * 60
+)
+
+// exposedHeaders are readable by browser code regardless of the response.
+var exposedHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+
+// Option configures a Wrapper.
+type Option func(*Wrapper)
+
+// WithAllowedOrigins lets browser pages from origins call the server. "*"
+// allows any origin. Same-origin requests are always allowed.
+func WithAllowedOrigins(origins []string) Option {
+	return func(w *Wrapper) {
+		for _, o := range origins {
+			if o == "*" {
+				w.anyOrigin = true
+				continue
+			}
+			w.origins[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+		}
+	}
+}
+
+// Wrapper is an http.Handler serving gRPC-Web requests with a gRPC server.
+type Wrapper struct {
+	grpc      http.Handler
+	origins   map[string]bool
+	anyOrigin bool
+}
+
+// New returns a Wrapper dispatching to grpcServer, usually a *grpc.Server.
+func New(grpcServer http.Handler, opts ...Option) *Wrapper {
+	w := &Wrapper{
+		grpc:    grpcServer,
+		origins: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// IsGRPCWeb reports whether r is a gRPC-Web request.
+func IsGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), contentTypeWeb)
+}
+
+// IsPreflight reports whether r is a CORS preflight for a gRPC-Web request.
+// gRPC-Web clients always send the X-Grpc-Web header, so browsers ask for it.
+func IsPreflight(r *http.Request) bool {
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	for _, h := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if strings.EqualFold(strings.TrimSpace(h), "x-grpc-web") {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the Wrapper should serve r.
+func (wr *Wrapper) Match(r *http.Request) bool {
+	return IsGRPCWeb(r) || IsPreflight(r)
+}
+
+// ServeHTTP implements http.Handler.
+func (wr *Wrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin != "" && !wr.allowed(origin, r.Host) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Add("Vary", "Origin")
+	}
+	if IsPreflight(r) {
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(preflightMaxAge))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !IsGRPCWeb(r) {
+		http.Error(w, "not a grpc-web request", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, contentTypeText)
+
+	req := r.Clone(r.Context())
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2"
+	req.Header.Set("Content-Type", grpcContentType(contentType))
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	if text {
+		req.Body = readCloser{base64.NewDecoder(base64.StdEncoding, r.Body), r.Body}
+	}
+
+	rw := newResponseWriter(w, contentType, text, origin != "")
+	wr.grpc.ServeHTTP(rw, req)
+	rw.finish()
+}
+
+// allowed reports whether browser pages from origin may call the server.
+func (wr *Wrapper) allowed(origin, host string) bool {
+	if wr.anyOrigin || wr.origins[strings.ToLower(origin)] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
+
+// grpcContentType maps a gRPC-Web content type to its native equivalent,
+// keeping the codec suffix, e.g. application/grpc-web-text+proto becomes
+// application/grpc+proto.
+func grpcContentType(contentType string) string {
+	if strings.HasPrefix(contentType, contentTypeText) {
+		return contentTypeGRPC + strings.TrimPrefix(contentType, contentTypeText)
+	}
+	return contentTypeGRPC + strings.TrimPrefix(contentType, contentTypeWeb)
+}
+
+// responseWriter collects the headers written by the gRPC server, forwards
+// them as response headers and, once the call ends, writes its trailers as a
+// trailer frame in the body.
+type responseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	contentType string
+	cors        bool
+	text        bool
+	enc         io.WriteCloser
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter, contentType string, text, cors bool) *responseWriter {
+	rw := &responseWriter{
+		w:           w,
+		header:      make(http.Header),
+		contentType: contentType,
+		cors:        cors,
+		text:        text,
+	}
+	if text {
+		rw.enc = base64.NewEncoder(base64.StdEncoding, w)
+	}
+	return rw
+}
+
+func (rw *responseWriter) Header() http.Header {
+	return rw.header
+}
+
+// WriteHeader sends every header that is not a declared trailer.
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+	trailers := rw.trailerKeys()
+	var exposed []string
+	for k, vs := range rw.header {
+		if k == "Trailer" || trailers[k] || strings.HasPrefix(k, http.TrailerPrefix) || len(vs) == 0 {
+			continue
+		}
+		for _, v := range vs {
+			rw.w.Header().Add(k, v)
+		}
+		exposed = append(exposed, k)
+	}
+	rw.w.Header().Set("Content-Type", rw.contentType)
+	if rw.cors {
+		sort.Strings(exposed)
+		rw.w.Header().Set("Access-Control-Expose-Headers", strings.Join(append(exposedHeaders, exposed...), ", "))
+	}
+	rw.w.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.WriteHeader(http.StatusOK)
+	if rw.text {
+		return rw.enc.Write(b)
+	}
+	return rw.w.Write(b)
+}
+
+// Flush sends buffered data. In text mode the base64 stream is closed and
+// restarted so the client can decode everything received so far.
+func (rw *responseWriter) Flush() {
+	rw.WriteHeader(http.StatusOK)
+	if rw.text {
+		rw.enc.Close()
+		rw.enc = base64.NewEncoder(base64.StdEncoding, rw.w)
+	}
+	if f, ok := rw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailer frame.
+func (rw *responseWriter) finish() {
+	rw.WriteHeader(http.StatusOK)
+	trailers := make(http.Header)
+	for k := range rw.trailerKeys() {
+		for _, v := range rw.header[k] {
+			trailers.Add(k, v)
+		}
+	}
+	for k, vs := range rw.header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			for _, v := range vs {
+				trailers.Add(strings.TrimPrefix(k, http.TrailerPrefix), v)
+			}
+		}
+	}
+	keys := make([]string, 0, len(trailers))
+	for k := range trailers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var body strings.Builder
+	for _, k := range keys {
+		for _, v := range trailers[k] {
+			body.WriteString(strings.ToLower(k))
+			body.WriteString(": ")
+			body.WriteString(v)
+			body.WriteString("\r\n")
+		}
+	}
+	frame := make([]byte, 5, 5+body.Len())
+	frame[0] = trailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(body.Len()))
+	frame = append(frame, body.String()...)
+	rw.Write(frame)
+	rw.Flush()
+}
+
+// trailerKeys returns the canonical header keys declared as trailers.
+func (rw *responseWriter) trailerKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, v := range rw.header["Trailer"] {
+		for _, k := range strings.Split(v, ",") {
+			keys[http.CanonicalHeaderKey(strings.TrimSpace(k))] = true
+		}
+	}
+	return keys
+}
+
+// readCloser reads from one source and closes another.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,184 @@
+package grpcweb
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newWrapper(opts ...Option) *Wrapper {
+	s := grpc.NewServer()
+	h := health.NewServer()
+	h.SetServingStatus("up", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, h)
+	return New(s, opts...)
+}
+
+func frame(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	buf, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, 5, 5+len(buf))
+	binary.BigEndian.PutUint32(out[1:], uint32(len(buf)))
+	return append(out, buf...)
+}
+
+// readFrame returns the flag and payload of the next frame in r.
+func readFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	t.Helper()
+	head := make([]byte, 5)
+	if _, err := io.ReadFull(r, head); err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, binary.BigEndian.Uint32(head[1:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatal(err)
+	}
+	return head[0], body
+}
+
+func TestUnary(t *testing.T) {
+	wr := newWrapper()
+	body := frame(t, &healthpb.HealthCheckRequest{Service: "up"})
+	r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+	if !wr.Match(r) {
+		t.Fatal("expected a grpc-web request")
+	}
+	w := httptest.NewRecorder()
+	wr.ServeHTTP(w, r)
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/grpc-web+proto" {
+		t.Fatalf("content type %q", ct)
+	}
+	flag, msg := readFrame(t, w.Body)
+	if flag != 0 {
+		t.Fatalf("want a message frame, got flag %x", flag)
+	}
+	var resp healthpb.HealthCheckResponse
+	if err := proto.Unmarshal(msg, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected status %v", resp.Status)
+	}
+	flag, trailer := readFrame(t, w.Body)
+	if flag != trailerFlag || !strings.Contains(string(trailer), "grpc-status: 0\r\n") {
+		t.Fatalf("unexpected trailer frame %x %q", flag, trailer)
+	}
+}
+
+func TestText(t *testing.T) {
+	wr := newWrapper()
+	body := base64.StdEncoding.EncodeToString(frame(t, &healthpb.HealthCheckRequest{Service: "missing"}))
+	r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/grpc-web-text")
+	w := httptest.NewRecorder()
+	wr.ServeHTTP(w, r)
+
+	// Each flush closes a base64 segment, so decode them one at a time.
+	var decoded []byte
+	for _, seg := range strings.SplitAfter(w.Body.String(), "=") {
+		if seg == "" {
+			continue
+		}
+		for strings.HasPrefix(seg, "=") {
+			seg = seg[1:]
+		}
+		b, err := base64.StdEncoding.DecodeString(seg + strings.Repeat("=", (4-len(seg)%4)%4))
+		if err != nil {
+			t.Fatalf("decode %q: %v", seg, err)
+		}
+		decoded = append(decoded, b...)
+	}
+	flag, trailer := readFrame(t, bytes.NewReader(decoded))
+	if flag != trailerFlag || !strings.Contains(string(trailer), "grpc-status: 5\r\n") {
+		t.Fatalf("want NOT_FOUND trailers, got %x %q", flag, trailer)
+	}
+}
+
+func TestServerStream(t *testing.T) {
+	srv := httptest.NewServer(newWrapper())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	body := frame(t, &healthpb.HealthCheckRequest{Service: "up"})
+	req, _ := http.NewRequestWithContext(ctx, "POST", srv.URL+"/grpc.health.v1.Health/Watch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Watch never ends on its own; the first update arrives while the call
+	// is still open.
+	flag, msg := readFrame(t, resp.Body)
+	if flag != 0 {
+		t.Fatalf("want a message frame, got flag %x", flag)
+	}
+	var update healthpb.HealthCheckResponse
+	if err := proto.Unmarshal(msg, &update); err != nil {
+		t.Fatal(err)
+	}
+	if update.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected status %v", update.Status)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	wr := newWrapper(WithAllowedOrigins([]string{"https://app.sonr.io"}))
+
+	r := httptest.NewRequest("OPTIONS", "/grpc.health.v1.Health/Check", nil)
+	r.Header.Set("Origin", "https://app.sonr.io")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	r.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+	if !wr.Match(r) {
+		t.Fatal("expected a preflight request")
+	}
+	w := httptest.NewRecorder()
+	wr.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://app.sonr.io" {
+		t.Fatalf("preflight: %d %v", w.Code, w.Header())
+	}
+
+	r = httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", bytes.NewReader(frame(t, &healthpb.HealthCheckRequest{})))
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+	r.Header.Set("Origin", "https://evil.example")
+	w = httptest.NewRecorder()
+	wr.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("want 403 for a foreign origin, got %d", w.Code)
+	}
+
+	// Pages served by the node itself are always allowed.
+	r = httptest.NewRequest("POST", "http://node.sonr.io/grpc.health.v1.Health/Check", bytes.NewReader(frame(t, &healthpb.HealthCheckRequest{})))
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+	r.Header.Set("Origin", "http://node.sonr.io")
+	w = httptest.NewRecorder()
+	wr.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("same origin: %d", w.Code)
+	}
+	if !strings.Contains(w.Header().Get("Access-Control-Expose-Headers"), "Grpc-Status") {
+		t.Fatalf("grpc headers not exposed: %v", w.Header())
+	}
+	if _, err := ioutil.ReadAll(w.Body); err != nil {
+		t.Fatal(err)
+	}
+}
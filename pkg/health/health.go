@@ -0,0 +1,198 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kataras/golog"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// DefaultInterval is how often the dependencies are probed.
+	DefaultInterval = 10 * time.Second
+
+	// DefaultTimeout bounds a single probe.
+	DefaultTimeout = 3 * time.Second
+)
+
+var logger = golog.Default.Child("pkg/health")
+
+// Check probes a dependency and returns an error when it is unusable.
+type Check func(ctx context.Context) error
+
+// Monitor periodically runs a set of checks and publishes their results on
+// a grpc.health.v1 server. Every check is reported under its own service
+// name. The overall status, reported under "" and every name passed to
+// WithServices, is SERVING only while every check added with Add passes;
+// checks added with AddOptional do not affect it.
+type Monitor struct {
+	Server *grpchealth.Server
+
+	interval time.Duration
+	timeout  time.Duration
+	services []string
+
+	mu       sync.Mutex
+	names    []string
+	checks   map[string]Check
+	optional map[string]bool
+	errs     map[string]error
+}
+
+// Option configures a Monitor.
+type Option func(*Monitor)
+
+// WithInterval sets how often the checks run.
+func WithInterval(d time.Duration) Option {
+	return func(m *Monitor) {
+		if d > 0 {
+			m.interval = d
+		}
+	}
+}
+
+// WithTimeout sets how long a single check may take.
+func WithTimeout(d time.Duration) Option {
+	return func(m *Monitor) {
+		if d > 0 {
+			m.timeout = d
+		}
+	}
+}
+
+// WithServices reports the overall status under the given gRPC service
+// names in addition to "".
+func WithServices(names ...string) Option {
+	return func(m *Monitor) {
+		m.services = append(m.services, names...)
+	}
+}
+
+// NewMonitor returns a Monitor backed by a new health server. Every status
+// starts as NOT_SERVING until the first round of checks completes.
+func NewMonitor(opts ...Option) *Monitor {
+	m := &Monitor{
+		Server:   grpchealth.NewServer(),
+		interval: DefaultInterval,
+		timeout:  DefaultTimeout,
+		checks:   make(map[string]Check),
+		optional: make(map[string]bool),
+		errs:     make(map[string]error),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.setOverall(healthpb.HealthCheckResponse_NOT_SERVING)
+	return m
+}
+
+// Add registers a check under name that the overall status depends on.
+func (m *Monitor) Add(name string, check Check) {
+	m.add(name, check, false)
+}
+
+// AddOptional registers a check under name that is reported on its own but
+// leaves the overall status alone, for dependencies the node can serve
+// without.
+func (m *Monitor) AddOptional(name string, check Check) {
+	m.add(name, check, true)
+}
+
+func (m *Monitor) add(name string, check Check, optional bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.checks[name]; !ok {
+		m.names = append(m.names, name)
+	}
+	m.checks[name] = check
+	m.optional[name] = optional
+	m.Server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// CheckNow runs every check once, publishes the results and returns the
+// errors of the failing checks, optional ones included, keyed by name.
+func (m *Monitor) CheckNow(ctx context.Context) map[string]error {
+	m.mu.Lock()
+	names := append([]string(nil), m.names...)
+	checks := make(map[string]Check, len(m.checks))
+	for k, v := range m.checks {
+		checks[k] = v
+	}
+	optional := make(map[string]bool, len(m.optional))
+	for k, v := range m.optional {
+		optional[k] = v
+	}
+	m.mu.Unlock()
+
+	failed := make(map[string]error)
+	serving := true
+	for _, name := range names {
+		cctx, cancel := context.WithTimeout(ctx, m.timeout)
+		err := checks[name](cctx)
+		cancel()
+		m.record(name, err)
+		if err != nil {
+			failed[name] = err
+			if !optional[name] {
+				serving = false
+			}
+		}
+	}
+
+	if serving {
+		m.setOverall(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		m.setOverall(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return failed
+}
+
+// Run runs the checks every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+	for {
+		m.CheckNow(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Shutdown marks every service NOT_SERVING and ignores later updates so
+// that load balancers stop routing to the node while it drains.
+func (m *Monitor) Shutdown() {
+	m.Server.Shutdown()
+}
+
+// record publishes the result of a single check, logging transitions.
+func (m *Monitor) record(name string, err error) {
+	m.mu.Lock()
+	prev := m.errs[name]
+	m.errs[name] = err
+	m.mu.Unlock()
+
+	switch {
+	case err != nil && prev == nil:
+		logger.Warnf("%s is unhealthy: %s", name, err)
+	case err == nil && prev != nil:
+		logger.Infof("%s recovered", name)
+	}
+	if err != nil {
+		m.Server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+		return
+	}
+	m.Server.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+}
+
+func (m *Monitor) setOverall(s healthpb.HealthCheckResponse_ServingStatus) {
+	m.Server.SetServingStatus("", s)
+	for _, name := range m.services {
+		m.Server.SetServingStatus(name, s)
+	}
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func status(t *testing.T, m *Monitor, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := m.Server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q) error = %v", service, err)
+	}
+	return resp.GetStatus()
+}
+
+func TestMonitorReflectsChecks(t *testing.T) {
+	mongoErr := errors.New("connection refused")
+	m := NewMonitor(WithServices("sonrio.highway.v1.Highway"))
+	m.Add("mongo", func(ctx context.Context) error { return mongoErr })
+	m.Add("blob", func(ctx context.Context) error { return nil })
+
+	if got := status(t, m, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status before first check = %v", got)
+	}
+
+	failed := m.CheckNow(context.Background())
+	if len(failed) != 1 || failed["mongo"] != mongoErr {
+		t.Errorf("CheckNow() = %v", failed)
+	}
+	if got := status(t, m, "mongo"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("mongo = %v, want NOT_SERVING", got)
+	}
+	if got := status(t, m, "blob"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("blob = %v, want SERVING", got)
+	}
+	if got := status(t, m, "sonrio.highway.v1.Highway"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("overall = %v, want NOT_SERVING", got)
+	}
+
+	mongoErr = nil
+	m.Add("mongo", func(ctx context.Context) error { return nil })
+	m.CheckNow(context.Background())
+	if got := status(t, m, ""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("overall after recovery = %v, want SERVING", got)
+	}
+
+	m.Shutdown()
+	if got := status(t, m, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("overall after Shutdown = %v, want NOT_SERVING", got)
+	}
+}
+
+func TestOptionalCheckLeavesOverallAlone(t *testing.T) {
+	cosmosErr := errors.New("no peers")
+	m := NewMonitor(WithServices("sonrio.highway.v1.Highway"))
+	m.Add("database", func(ctx context.Context) error { return nil })
+	m.AddOptional("cosmos", func(ctx context.Context) error { return cosmosErr })
+
+	failed := m.CheckNow(context.Background())
+	if len(failed) != 1 || failed["cosmos"] != cosmosErr {
+		t.Errorf("CheckNow() = %v", failed)
+	}
+	if got := status(t, m, "cosmos"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("cosmos = %v, want NOT_SERVING", got)
+	}
+	for _, service := range []string{"", "sonrio.highway.v1.Highway"} {
+		if got := status(t, m, service); got != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("%q = %v, want SERVING", service, got)
+		}
+	}
+
+	// A required check failing still takes the node out.
+	m.Add("database", func(ctx context.Context) error { return errors.New("closed") })
+	m.CheckNow(context.Background())
+	if got := status(t, m, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("overall = %v, want NOT_SERVING", got)
+	}
+}
@@ -0,0 +1,40 @@
+// Package interceptor provides the gRPC server interceptor chain of the
+// Highway node: request IDs, call logging, panic recovery and caller
+// authentication.
+package interceptor
+
+import (
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	"google.golang.org/grpc"
+)
+
+// Unary returns the unary interceptor chain. Request IDs are assigned first
+// so every log line carries one, and recovery runs inside logging so
+// recovered panics are logged with their codes.Internal status.
+func Unary(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return grpc_middleware.ChainUnaryServer(
+		UnaryRequestID,
+		UnaryLogger,
+		UnaryRecovery,
+		a.Unary(),
+	)
+}
+
+// Stream returns the stream interceptor chain, ordered as Unary.
+func Stream(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return grpc_middleware.ChainStreamServer(
+		StreamRequestID,
+		StreamLogger,
+		StreamRecovery,
+		a.Stream(),
+	)
+}
+
+// ServerOptions installs the interceptor chains on a gRPC server.
+func ServerOptions(a *auth.Authenticator) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(Unary(a)),
+		grpc.StreamInterceptor(Stream(a)),
+	}
+}
@@ -0,0 +1,71 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sonr-io/webauthn.io/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/svc.Highway/ParseDid"}
+
+func TestRecovery(t *testing.T) {
+	_, err := UnaryRecovery(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("want Internal, got %v", err)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = RequestID(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDKey, "abc-123"))
+	if _, err := UnaryRequestID(ctx, nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "abc-123" {
+		t.Fatalf("client request ID not kept: %q", seen)
+	}
+
+	if _, err := UnaryRequestID(context.Background(), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 32 {
+		t.Fatalf("want a generated ID, got %q", seen)
+	}
+}
+
+func TestChain(t *testing.T) {
+	a := auth.NewAuthenticator([]byte("key"), auth.WithPublicMethods(info.FullMethod))
+	chain := Unary(a)
+
+	_, err := chain(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if RequestID(ctx) == "" {
+			t.Error("handler has no request ID")
+		}
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("want Internal, got %v", err)
+	}
+
+	private := &grpc.UnaryServerInfo{FullMethod: "/svc.Highway/CreateBucket"}
+	called := false
+	_, err = chain(context.Background(), nil, private, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated || called {
+		t.Fatalf("unauthenticated call reached the handler: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	log "github.com/sonr-io/webauthn.io/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLogger logs every unary call with its method, duration and status.
+func UnaryLogger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// StreamLogger logs every streaming call once it ends.
+func StreamLogger(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := logrus.Fields{
+		"method":     method,
+		"duration":   time.Since(start).String(),
+		"code":       code.String(),
+		"request_id": RequestID(ctx),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer"] = p.Addr.String()
+	}
+	entry := log.WithFields(fields)
+	switch code {
+	case codes.OK:
+		entry.Info("rpc")
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+		entry.WithError(err).Error("rpc")
+	default:
+		entry.WithError(err).Warn("rpc")
+	}
+}
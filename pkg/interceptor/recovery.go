@@ -0,0 +1,41 @@
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+
+	log "github.com/sonr-io/webauthn.io/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errPanic is returned in place of a panicking handler's result. The panic
+// value is logged, never sent to the client.
+var errPanic = status.Error(codes.Internal, "internal error")
+
+// UnaryRecovery turns a panic in a unary handler into codes.Internal.
+func UnaryRecovery(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(ctx, info.FullMethod, r)
+			resp, err = nil, errPanic
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// StreamRecovery turns a panic in a stream handler into codes.Internal.
+func StreamRecovery(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(ss.Context(), info.FullMethod, r)
+			err = errPanic
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func logPanic(ctx context.Context, method string, r interface{}) {
+	log.Errorf("panic in %s (request %s): %v\n%s", method, RequestID(ctx), r, debug.Stack())
+}
@@ -0,0 +1,57 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDKey is the metadata key carrying the request ID. A client
+// supplied ID is kept, otherwise one is generated. The ID is echoed in the
+// response headers.
+const RequestIDKey = "x-request-id"
+
+// maxRequestIDLength bounds client supplied IDs so they cannot bloat logs.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID returns the ID of the call ctx belongs to.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// UnaryRequestID assigns a request ID to unary calls.
+func UnaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := requestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+	return handler(context.WithValue(ctx, requestIDKey{}, id), req)
+}
+
+// StreamRequestID assigns a request ID to streaming calls.
+func StreamRequestID(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := requestID(ss.Context())
+	ss.SetHeader(metadata.Pairs(RequestIDKey, id))
+	wrapped := grpc_middleware.WrapServerStream(ss)
+	wrapped.WrappedContext = context.WithValue(ss.Context(), requestIDKey{}, id)
+	return handler(srv, wrapped)
+}
+
+// requestID returns the client supplied ID or a new random one.
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDKey); len(ids) > 0 && ids[0] != "" && len(ids[0]) <= maxRequestIDLength {
+			return ids[0]
+		}
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
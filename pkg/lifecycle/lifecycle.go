@@ -0,0 +1,176 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kataras/golog"
+)
+
+// DefaultStopTimeout is the amount of time each hook is given to drain
+// before the manager moves on to the next one.
+const DefaultStopTimeout = 10 * time.Second
+
+var (
+	logger = golog.Default.Child("pkg/lifecycle")
+
+	// ErrAlreadyRunning is returned when Run is called more than once.
+	ErrAlreadyRunning = errors.New("lifecycle manager is already running")
+)
+
+// Hook is a single component managed by the Manager. OnStart must return
+// once the component is ready to accept work, and OnStop must release every
+// resource the component holds.
+type Hook struct {
+	// Name is used for logging.
+	Name string
+
+	// OnStart brings the component up. It is called in the order the hooks
+	// were appended.
+	OnStart func(ctx context.Context) error
+
+	// OnStop drains the component. It is called in the reverse order the
+	// hooks were appended.
+	OnStop func(ctx context.Context) error
+}
+
+// Manager starts a set of hooks in order, waits for a shutdown signal or a
+// fatal error from one of its background routines, and then stops every
+// started hook in reverse order.
+type Manager struct {
+	hooks       []Hook
+	errc        chan error
+	signals     []os.Signal
+	stopTimeout time.Duration
+
+	mu      sync.Mutex
+	running bool
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithStopTimeout sets the time each hook is given to stop.
+func WithStopTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		m.stopTimeout = d
+	}
+}
+
+// WithSignals overrides the OS signals that trigger a shutdown.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(m *Manager) {
+		m.signals = sigs
+	}
+}
+
+// New returns a Manager that shuts down on SIGINT and SIGTERM.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		errc:        make(chan error, 1),
+		signals:     []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		stopTimeout: DefaultStopTimeout,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Append registers a hook. Hooks are started in the order they are appended
+// and stopped in the reverse order.
+func (m *Manager) Append(h Hook) {
+	m.hooks = append(m.hooks, h)
+}
+
+// Go runs fn in the background. If fn returns a non-nil error the manager
+// begins shutting down. Blocking serve loops such as grpc.Server.Serve are
+// expected to be launched from an OnStart hook through Go.
+func (m *Manager) Go(name string, fn func() error) {
+	go func() {
+		if err := fn(); err != nil {
+			select {
+			case m.errc <- fmt.Errorf("%s: %w", name, err):
+			default:
+				logger.Errorf("%s exited: %s", name, err)
+			}
+		}
+	}()
+}
+
+// Run starts every hook and blocks until ctx is cancelled, a shutdown signal
+// is received, or a background routine fails. Every hook that was started is
+// then stopped. The returned error is the cause of the shutdown, if any,
+// joined with the first error encountered while stopping.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	ctx, cancel := signal.NotifyContext(ctx, m.signals...)
+	defer cancel()
+
+	started, err := m.start(ctx)
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			logger.Info("Shutting down...")
+		case err = <-m.errc:
+			logger.Errorf("Shutting down after fatal error: %s", err)
+		}
+	}
+
+	if stopErr := m.stop(started); stopErr != nil {
+		if err == nil {
+			return stopErr
+		}
+		return fmt.Errorf("%w (stop: %s)", err, stopErr)
+	}
+	return err
+}
+
+// start runs the OnStart hooks in order and returns how many succeeded.
+func (m *Manager) start(ctx context.Context) (int, error) {
+	for i, h := range m.hooks {
+		if h.OnStart == nil {
+			continue
+		}
+		logger.Infof("Starting %s", h.Name)
+		if err := h.OnStart(ctx); err != nil {
+			return i, fmt.Errorf("start %s: %w", h.Name, err)
+		}
+	}
+	return len(m.hooks), nil
+}
+
+// stop runs the OnStop hooks of the first n hooks in reverse order.
+func (m *Manager) stop(n int) error {
+	var first error
+	for i := n - 1; i >= 0; i-- {
+		h := m.hooks[i]
+		if h.OnStop == nil {
+			continue
+		}
+		logger.Infof("Stopping %s", h.Name)
+		ctx, cancel := context.WithTimeout(context.Background(), m.stopTimeout)
+		err := h.OnStop(ctx)
+		cancel()
+		if err != nil {
+			logger.Errorf("Failed to stop %s: %s", h.Name, err)
+			if first == nil {
+				first = fmt.Errorf("stop %s: %w", h.Name, err)
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,98 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func recordHook(name string, calls *[]string) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	var calls []string
+	m := New()
+	m.Append(recordHook("mongo", &calls))
+	m.Append(recordHook("http", &calls))
+	m.Append(recordHook("grpc", &calls))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []string{"start mongo", "start http", "start grpc", "stop grpc", "stop http", "stop mongo"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestRunStopsOnlyStartedHooks(t *testing.T) {
+	var calls []string
+	errBoom := errors.New("boom")
+	m := New()
+	m.Append(recordHook("mongo", &calls))
+	m.Append(Hook{
+		Name:    "http",
+		OnStart: func(ctx context.Context) error { return errBoom },
+		OnStop: func(ctx context.Context) error {
+			t.Error("stop called on hook that failed to start")
+			return nil
+		},
+	})
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Run() error = %v, want %v", err, errBoom)
+	}
+	want := []string{"start mongo", "stop mongo"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestGoErrorTriggersShutdown(t *testing.T) {
+	var calls []string
+	errServe := errors.New("listener closed")
+	m := New(WithStopTimeout(time.Second))
+	m.Append(Hook{
+		Name: "grpc",
+		OnStart: func(ctx context.Context) error {
+			m.Go("grpc", func() error { return errServe })
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			calls = append(calls, "stop grpc")
+			return nil
+		},
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errServe) {
+			t.Fatalf("Run() error = %v, want %v", err, errServe)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after background error")
+	}
+	if len(calls) != 1 {
+		t.Errorf("calls = %v, want [stop grpc]", calls)
+	}
+}
@@ -0,0 +1,135 @@
+package multiplex
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ReadHeaderTimeout bounds how long a client may take to send request
+// headers. There is no write timeout since gRPC streams are long lived.
+const ReadHeaderTimeout = 10 * time.Second
+
+// Server serves a gRPC server and an HTTP handler on the same listener.
+// Requests are routed by protocol: HTTP/2 requests with a gRPC content type
+// go to the gRPC server and everything else to the HTTP handler. Plaintext
+// connections accept HTTP/2 with prior knowledge so gRPC clients work without
+// TLS.
+type Server struct {
+	grpc *grpc.Server
+	http *http.Server
+	tls  *tls.Config
+
+	requireClientCert bool
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// RequireClientCert refuses gRPC requests on connections without a verified
+// client certificate. Both protocols share one TLS handshake, which has to
+// let browsers in without a certificate, so tlsConfig should only verify
+// certificates that are given and this enforces them for gRPC.
+func RequireClientCert() Option {
+	return func(s *Server) {
+		s.requireClientCert = true
+	}
+}
+
+// New returns a Server. TLS is used when tlsConfig is non-nil; its
+// certificates are shared by both protocols.
+func New(grpcServer *grpc.Server, handler http.Handler, tlsConfig *tls.Config, opts ...Option) (*Server, error) {
+	s := &Server{
+		grpc: grpcServer,
+		tls:  tlsConfig,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	var g http.Handler = grpcServer
+	if s.requireClientCert {
+		g = requireClientCert(g)
+	}
+	h := Handler(g, handler)
+	h2 := &http2.Server{}
+	if tlsConfig == nil {
+		h = h2c.NewHandler(h, h2)
+	}
+	s.http = &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+		TLSConfig:         tlsConfig,
+	}
+	if tlsConfig != nil {
+		if err := http2.ConfigureServer(s.http, h2); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Handler routes gRPC requests to grpcServer and all others to handler.
+func Handler(grpcServer http.Handler, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsGRPC(r) {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// requireClientCert answers gRPC requests without a verified client
+// certificate with a trailers-only Unauthenticated status.
+func requireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unauthenticated)))
+			w.Header().Set("Grpc-Message", "client certificate required")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsGRPC reports whether r is a native gRPC request. gRPC-Web requests are
+// left to the HTTP handler.
+func IsGRPC(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	return r.ProtoMajor == 2 && (ct == "application/grpc" || strings.HasPrefix(ct, "application/grpc+") || strings.HasPrefix(ct, "application/grpc;"))
+}
+
+// Serve accepts connections on l. It returns http.ErrServerClosed once
+// Shutdown has been called.
+func (s *Server) Serve(l net.Listener) error {
+	if s.tls != nil {
+		l = tls.NewListener(l, s.http.TLSConfig)
+	}
+	return s.http.Serve(l)
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests,
+// including gRPC calls, to finish. Connections still open when ctx expires
+// are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.http.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		s.http.Close()
+	}
+	// GracefulStop is not supported for connections served through
+	// ServeHTTP, and every such call has already finished or been closed.
+	s.grpc.Stop()
+	return err
+}
@@ -0,0 +1,121 @@
+package multiplex
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServeBothProtocols(t *testing.T) {
+	gs := grpc.NewServer()
+	healthpb.RegisterHealthServer(gs, health.NewServer())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	s, err := New(gs, mux, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(l) }()
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("GET /hello = %q", body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hr, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hr.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health = %v", hr.GetStatus())
+	}
+	conn.Close()
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Serve() = %v, want ErrServerClosed", err)
+	}
+}
+
+func TestIsGRPC(t *testing.T) {
+	for ct, want := range map[string]bool{
+		"application/grpc":           true,
+		"application/grpc+proto":     true,
+		"application/grpc-web+proto": false,
+		"application/grpc-web-text":  false,
+		"application/json":           false,
+	} {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.ProtoMajor = 2
+		r.Header.Set("Content-Type", ct)
+		if got := IsGRPC(r); got != want {
+			t.Errorf("%s: got %v, want %v", ct, got, want)
+		}
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("served"))
+	})
+	h := requireClientCert(next)
+
+	for name, state := range map[string]*tls.ConnectionState{
+		"plaintext":      nil,
+		"no certificate": {},
+	} {
+		r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", nil)
+		r.TLS = state
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Header().Get("Grpc-Status"); got != strconv.Itoa(int(codes.Unauthenticated)) {
+			t.Errorf("%s: grpc-status = %q", name, got)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("%s: request was served", name)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "served" {
+		t.Errorf("verified client was refused: %q", w.Body.String())
+	}
+}
@@ -0,0 +1,265 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kataras/golog"
+)
+
+// DefaultBufferSize is the number of messages queued for each subscriber
+// before the oldest queued message is dropped.
+const DefaultBufferSize = 64
+
+var (
+	logger = golog.Default.Child("pkg/pubsub")
+
+	// ErrTopicExists is returned when creating a topic that already exists.
+	ErrTopicExists = errors.New("topic already exists")
+
+	// ErrTopicNotFound is returned when a topic has not been created.
+	ErrTopicNotFound = errors.New("topic not found")
+)
+
+// Topic describes a channel that messages can be published to.
+type Topic struct {
+	ID          string
+	Label       string
+	Description string
+	Owner       string
+	Metadata    map[string]string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Broker is an in-process publish/subscribe broker. Every subscriber has its
+// own bounded queue, so a slow subscriber only loses its own oldest messages
+// and never blocks publishers or other subscribers. It is safe for
+// concurrent use.
+type Broker struct {
+	mu         sync.RWMutex
+	topics     map[string]*topic
+	bufferSize int
+}
+
+type topic struct {
+	info Topic
+	subs map[*Subscription]struct{}
+}
+
+// Option configures a Broker.
+type Option func(*Broker)
+
+// WithBufferSize sets the per-subscriber queue length.
+func WithBufferSize(n int) Option {
+	return func(b *Broker) {
+		if n > 0 {
+			b.bufferSize = n
+		}
+	}
+}
+
+// NewBroker returns an empty Broker.
+func NewBroker(opts ...Option) *Broker {
+	b := &Broker{
+		topics:     make(map[string]*topic),
+		bufferSize: DefaultBufferSize,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// CreateTopic registers a new topic.
+func (b *Broker) CreateTopic(t Topic) (Topic, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.topics[t.ID]; ok {
+		return Topic{}, ErrTopicExists
+	}
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	b.topics[t.ID] = &topic{
+		info: t,
+		subs: make(map[*Subscription]struct{}),
+	}
+	return t, nil
+}
+
+// Topic returns the topic with the given id along with its subscriber count.
+func (b *Broker) Topic(id string) (Topic, int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t, ok := b.topics[id]
+	if !ok {
+		return Topic{}, 0, ErrTopicNotFound
+	}
+	return t.info, len(t.subs), nil
+}
+
+// UpdateTopic applies fn to the topic with the given id and stores the result.
+// The topic ID and creation time cannot be changed.
+func (b *Broker) UpdateTopic(id string, fn func(*Topic) error) (Topic, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[id]
+	if !ok {
+		return Topic{}, ErrTopicNotFound
+	}
+	info := t.info
+	if err := fn(&info); err != nil {
+		return Topic{}, err
+	}
+	info.ID = t.info.ID
+	info.CreatedAt = t.info.CreatedAt
+	info.UpdatedAt = time.Now()
+	t.info = info
+	return info, nil
+}
+
+// DeleteTopic removes a topic and closes every subscription to it.
+func (b *Broker) DeleteTopic(id string) error {
+	b.mu.Lock()
+	t, ok := b.topics[id]
+	if ok {
+		delete(b.topics, id)
+	}
+	b.mu.Unlock()
+	if !ok {
+		return ErrTopicNotFound
+	}
+	for sub := range t.subs {
+		sub.close()
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber on the given topic. The subscription is
+// removed when ctx is cancelled or Unsubscribe is called, and its channel is
+// closed once no more messages will be delivered.
+func (b *Broker) Subscribe(ctx context.Context, id string) (*Subscription, error) {
+	ch := make(chan proto.Message, b.bufferSize)
+	sub := &Subscription{
+		C:      ch,
+		ch:     ch,
+		broker: b,
+		topic:  id,
+		done:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	t, ok := b.topics[id]
+	if !ok {
+		b.mu.Unlock()
+		return nil, ErrTopicNotFound
+	}
+	t.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+		case <-sub.done:
+		}
+	}()
+	return sub, nil
+}
+
+// Publish delivers msg to every current subscriber of the topic and returns
+// how many subscribers it was queued for.
+func (b *Broker) Publish(id string, msg proto.Message) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t, ok := b.topics[id]
+	if !ok {
+		return 0, ErrTopicNotFound
+	}
+	for sub := range t.subs {
+		sub.deliver(msg)
+	}
+	return len(t.subs), nil
+}
+
+// remove detaches sub from its topic, reporting whether it was attached.
+func (b *Broker) remove(sub *Subscription) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[sub.topic]
+	if !ok {
+		return false
+	}
+	if _, ok := t.subs[sub]; !ok {
+		return false
+	}
+	delete(t.subs, sub)
+	return true
+}
+
+// Subscription is a single listener on a topic.
+type Subscription struct {
+	// C receives published messages. It is closed when the subscription ends.
+	C <-chan proto.Message
+
+	ch      chan proto.Message
+	broker  *Broker
+	topic   string
+	mu      sync.Mutex
+	closed  bool
+	done    chan struct{}
+	dropped uint64
+}
+
+// Unsubscribe detaches the subscription from its topic and closes C.
+func (s *Subscription) Unsubscribe() {
+	if s.broker.remove(s) {
+		s.close()
+	}
+}
+
+// Dropped returns how many messages were discarded because the subscriber
+// fell behind.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// deliver queues msg, discarding the oldest queued message if the
+// subscriber's buffer is full.
+func (s *Subscription) deliver(msg proto.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for {
+		select {
+		case s.ch <- msg:
+			return
+		default:
+		}
+		select {
+		case <-s.ch:
+			if n := atomic.AddUint64(&s.dropped, 1); n == 1 || n%100 == 0 {
+				logger.Warnf("Subscriber to %s is falling behind, %d messages dropped", s.topic, n)
+			}
+		default:
+		}
+	}
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+	close(s.done)
+}
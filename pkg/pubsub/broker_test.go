@@ -0,0 +1,134 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func recv(t *testing.T, sub *Subscription) string {
+	t.Helper()
+	select {
+	case msg, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return msg.(*wrapperspb.StringValue).GetValue()
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+	return ""
+}
+
+func TestPublishFansOut(t *testing.T) {
+	b := NewBroker()
+	if _, err := b.CreateTopic(Topic{ID: "did:sonr:chan"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	a, _ := b.Subscribe(ctx, "did:sonr:chan")
+	c, _ := b.Subscribe(ctx, "did:sonr:chan")
+
+	n, err := b.Publish("did:sonr:chan", wrapperspb.String("hello"))
+	if err != nil || n != 2 {
+		t.Fatalf("Publish() = %d, %v, want 2, nil", n, err)
+	}
+	if got := recv(t, a); got != "hello" {
+		t.Errorf("a got %q", got)
+	}
+	if got := recv(t, c); got != "hello" {
+		t.Errorf("c got %q", got)
+	}
+}
+
+func TestSlowSubscriberDropsOldest(t *testing.T) {
+	b := NewBroker(WithBufferSize(2))
+	b.CreateTopic(Topic{ID: "t"})
+	sub, _ := b.Subscribe(context.Background(), "t")
+
+	for _, v := range []string{"1", "2", "3"} {
+		b.Publish("t", wrapperspb.String(v))
+	}
+	if got := recv(t, sub); got != "2" {
+		t.Errorf("first message = %q, want 2", got)
+	}
+	if got := recv(t, sub); got != "3" {
+		t.Errorf("second message = %q, want 3", got)
+	}
+	if sub.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", sub.Dropped())
+	}
+}
+
+func TestContextCancelUnsubscribes(t *testing.T) {
+	b := NewBroker()
+	b.CreateTopic(Topic{ID: "t"})
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, _ := b.Subscribe(ctx, "t")
+	cancel()
+
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Fatal("received message after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed after context cancel")
+	}
+	if _, n, _ := b.Topic("t"); n != 0 {
+		t.Errorf("subscribers = %d, want 0", n)
+	}
+}
+
+func TestDeleteTopicClosesSubscriptions(t *testing.T) {
+	b := NewBroker()
+	b.CreateTopic(Topic{ID: "t"})
+	sub, _ := b.Subscribe(context.Background(), "t")
+	if err := b.DeleteTopic("t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatal("subscription still open after delete")
+	}
+	sub.Unsubscribe()
+	if _, err := b.Publish("t", wrapperspb.String("x")); err != ErrTopicNotFound {
+		t.Errorf("Publish() error = %v, want %v", err, ErrTopicNotFound)
+	}
+}
+
+func TestConcurrentPublishers(t *testing.T) {
+	b := NewBroker(WithBufferSize(1))
+	b.CreateTopic(Topic{ID: "t"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Publish("t", wrapperspb.String("x"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			sub, err := b.Subscribe(ctx, "t")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for j := 0; j < 10; j++ {
+				select {
+				case <-sub.C:
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			sub.Unsubscribe()
+		}()
+	}
+	wg.Wait()
+}
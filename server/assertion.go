@@ -4,11 +4,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/duo-labs/webauthn/protocol"
 	"github.com/duo-labs/webauthn/webauthn"
 	"github.com/gorilla/mux"
-	"github.com/jinzhu/gorm"
+	db "github.com/sonr-io/webauthn.io/database"
 	log "github.com/sonr-io/webauthn.io/logger"
 	"github.com/sonr-io/webauthn.io/models"
 )
@@ -16,7 +18,7 @@ import (
 // ErrCredentialCloned occurs when an authenticator provides a sign count
 // during assertion that is lower than the previously recorded sign count, as
 // this would indicate that the authenticator may have been cloned.
-var ErrCredentialCloned = errors.New("credential appears to have been cloned")
+var ErrCredentialCloned = models.ErrCredentialCloned
 
 // GetAssertion - assemble the data we need to make an assertion against
 // a given user and authenticator
@@ -26,10 +28,7 @@ func (ws *Server) GetAssertion(w http.ResponseWriter, r *http.Request) {
 	txAuthExtension := vars["txAuthExtension"]
 
 	//The trimmer
-	username := vars["name"]
-	if username[len(username)-4:] == ".snr" {
-		username = username[:len(username)-4]
-	}
+	username := models.TrimName(vars["name"])
 
 	// TODO: Change these to POST's
 	//username := r.FormValue("username")
@@ -40,11 +39,15 @@ func (ws *Server) GetAssertion(w http.ResponseWriter, r *http.Request) {
 
 	testExtension := protocol.AuthenticationExtensions(map[string]interface{}{"txAuthSimple": txAuthExtension})
 
-	user, err := ws.Ctrl.GetUserByUsername(username)
-	if err == gorm.ErrRecordNotFound {
+	user, err := ws.Ctrl.GetUserByUsername(r.Context(), username)
+	if errors.Is(err, db.ErrNotFound) {
 		log.Errorf("error creating assertion: user doesn't exist: %s", username)
 		jsonResponse(w, "User doesn't exist", http.StatusBadRequest)
 		return
+	} else if err != nil {
+		log.Errorf("error creating assertion: %v", err)
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
 	}
 
 	assertion, sessionData, err := ws.webauthn.BeginLogin(user,
@@ -68,6 +71,50 @@ func (ws *Server) GetAssertion(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, assertion, http.StatusOK)
 }
 
+// GetDiscoverableAssertion begins a login without a username. The challenge
+// allows any credential, so the authenticator offers the passkeys it holds
+// for this site and MakeAssertion finds the user from the returned user
+// handle.
+func (ws *Server) GetDiscoverableAssertion(w http.ResponseWriter, r *http.Request) {
+	assertion, sessionData, err := ws.beginDiscoverableLogin()
+	if err != nil {
+		log.Errorf("error creating assertion: %v", err)
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = ws.store.SaveWebauthnSession("assertion", sessionData, r, w)
+	if err != nil {
+		log.Errorf("error creating assertion session: error marshaling session: %v", err)
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, assertion, http.StatusOK)
+}
+
+// beginDiscoverableLogin is webauthn.BeginLogin without a user: the options
+// have an empty allowCredentials list and the session data no user ID.
+func (ws *Server) beginDiscoverableLogin(opts ...webauthn.LoginOption) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	challenge, err := protocol.CreateChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+	requestOptions := protocol.PublicKeyCredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          ws.webauthn.Config.Timeout,
+		RelyingPartyID:   ws.webauthn.Config.RPID,
+		UserVerification: ws.webauthn.Config.AuthenticatorSelection.UserVerification,
+	}
+	for _, setter := range opts {
+		setter(&requestOptions)
+	}
+	sessionData := &webauthn.SessionData{
+		Challenge:        base64.RawURLEncoding.EncodeToString(challenge),
+		UserVerification: requestOptions.UserVerification,
+		Extensions:       requestOptions.Extensions,
+	}
+	return &protocol.CredentialAssertion{Response: requestOptions}, sessionData, nil
+}
+
 // MakeAssertion validates the assertion data provided by the authenticator and
 // responds whether or not it was successful alongside the relevant credential.
 func (ws *Server) MakeAssertion(w http.ResponseWriter, r *http.Request) {
@@ -76,19 +123,93 @@ func (ws *Server) MakeAssertion(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	// Get the user associated with the credential
-	user, err := ws.Ctrl.GetUser(models.BytesToID(sessionData.UserID))
+	ws.finishAssertion(w, r, sessionData)
+}
+
+// GetConditionalAssertion begins a discoverable login for the browser's
+// username autofill (mediation "conditional"). The page may hold the
+// challenge for as long as it stays open, so it is stored in the database
+// under the returned ID rather than in the session cookie's single assertion
+// slot.
+func (ws *Server) GetConditionalAssertion(w http.ResponseWriter, r *http.Request) {
+	timeout := int(ConditionalTimeout / time.Millisecond)
+	assertion, sessionData, err := ws.beginDiscoverableLogin(func(o *protocol.PublicKeyCredentialRequestOptions) {
+		o.Timeout = timeout
+	})
 	if err != nil {
+		log.Errorf("error creating assertion: %v", err)
 		jsonResponse(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	id, err := ws.Ctrl.OpenChallenge(r.Context(), sessionData, ConditionalTimeout, MaxConditionalChallenges)
+	if errors.Is(err, models.ErrTooManyChallenges) {
+		log.Warnf("refused autofill login: %d challenges already open", MaxConditionalChallenges)
+		w.Header().Set("Retry-After", strconv.Itoa(int(ConditionalTimeout/time.Second)))
+		jsonResponse(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	}
+	jsonResponse(w, conditionalAssertion{ID: id, CredentialAssertion: assertion}, http.StatusOK)
+}
+
+// conditionalAssertion is a discoverable login's options with the ID its
+// challenge is stored under.
+type conditionalAssertion struct {
+	ID string `json:"id"`
+	*protocol.CredentialAssertion
+}
+
+// MakeConditionalAssertion finishes a login begun by GetConditionalAssertion.
+func (ws *Server) MakeConditionalAssertion(w http.ResponseWriter, r *http.Request) {
+	sessionData, err := ws.Ctrl.TakeChallenge(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, models.ErrChallengeNotFound) {
+		jsonResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	}
+	ws.finishAssertion(w, r, sessionData)
+}
+
+// finishAssertion verifies the assertion in the request body against the
+// ceremony's session data and logs the user in.
+func (ws *Server) finishAssertion(w http.ResponseWriter, r *http.Request, sessionData webauthn.SessionData) {
+	parsedResponse, err := protocol.ParseCredentialRequestResponse(r)
+	if err != nil {
+		jsonResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get the user associated with the credential. A discoverable login has
+	// no user in its session; the authenticator names one in the user handle,
+	// which is the WebAuthnID the credential was registered with.
+	userID := sessionData.UserID
+	if len(userID) == 0 {
+		userID = parsedResponse.Response.UserHandle
+		if len(userID) == 0 {
+			jsonResponse(w, "credential returned no user handle", http.StatusBadRequest)
+			return
+		}
+	}
+	user, err := ws.Ctrl.GetUser(r.Context(), models.BytesToID(userID))
+	if errors.Is(err, db.ErrNotFound) && len(sessionData.UserID) == 0 {
+		jsonResponse(w, "no user holds this credential", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	}
+	sessionData.UserID = user.WebAuthnID()
 
 	log.Infof("Finishing authentication with user: %s\n", user.Username)
 
-	// With the session data retrieved, we need to call webauthn.FinishLogin to
-	// verify the signed challenge. This returns the webauthn.Credential that
-	// was used to authenticate.
-	cred, err := ws.webauthn.FinishLogin(user, sessionData, r)
+	// With the session data retrieved, we need to call webauthn.ValidateLogin
+	// to verify the signed challenge against the user's stored credentials.
+	// This returns the webauthn.Credential that was used to authenticate.
+	cred, err := ws.webauthn.ValidateLogin(user, sessionData, parsedResponse)
 	if err != nil {
 		jsonResponse(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -96,32 +217,21 @@ func (ws *Server) MakeAssertion(w http.ResponseWriter, r *http.Request) {
 	// At this point, we've confirmed the correct authenticator has been
 	// provided and it passed the challenge we gave it. We now need to make
 	// sure that the sign counter is higher than what we have stored to help
-	// give assurance that this credential wasn't cloned.
-	if cred.Authenticator.CloneWarning {
+	// give assurance that this credential wasn't cloned, and store the new
+	// value we received.
+	err = ws.Ctrl.CheckSignCount(r.Context(), user, cred)
+	if errors.Is(err, models.ErrCredentialCloned) || errors.Is(err, models.ErrCredentialDisabled) {
 		log.Errorf("credential appears to be cloned: %s", err)
-		jsonResponse(w, ErrCredentialCloned, http.StatusForbidden)
-		return
-	}
-	// We're logged in! All that's left is to update the sign count with the
-	// new value we received. We could join the tables on the CredentialID
-	// field, but for our purposes we'll just get the stored credential and
-	// use that to find the authenticator we need to update.
-	credentialID := base64.URLEncoding.EncodeToString(cred.ID)
-	storedCredential, err := ws.Ctrl.GetCredentialForUser(*user, credentialID)
-	if err != nil {
-		log.Errorf("error getting credentials for user: %s", err)
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		jsonResponse(w, err.Error(), http.StatusForbidden)
 		return
-	}
-	err = ws.Ctrl.UpdateAuthenticatorSignCount(storedCredential.AuthenticatorID, cred.Authenticator.SignCount)
-	if err != nil {
+	} else if err != nil {
 		log.Errorf("error updating sign count: %s", err)
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		jsonResponse(w, err.Error(), storeStatus(err))
 		return
 	}
-	err = ws.store.Set("user_id", user.ID, r, w)
+	err = ws.startSession(w, r, user, base64.URLEncoding.EncodeToString(cred.ID))
 	if err != nil {
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		jsonResponse(w, err.Error(), storeStatus(err))
 		return
 	}
 	jsonResponse(w, user, http.StatusOK)
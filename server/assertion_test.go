@@ -1,9 +1,227 @@
 package server
 
-import "net/http/httptest"
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/duo-labs/webauthn/protocol/webauthncbor"
+	"github.com/duo-labs/webauthn/protocol/webauthncose"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/controller"
+	"github.com/sonr-io/webauthn.io/models"
+)
 
 func (ss *ServerSuite) TestGetAssertion() {
 	req := httptest.NewRequest("POST", "/assertion", nil)
 	response := httptest.NewRecorder()
 	ss.server.GetAssertion(response, req)
 }
+
+// testAuthenticator holds a P-256 credential and signs assertions with it.
+type testAuthenticator struct {
+	id  []byte
+	key *ecdsa.PrivateKey
+}
+
+func (ss *ServerSuite) newAuthenticator(id string) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ss.Require().NoError(err)
+	return &testAuthenticator{id: []byte(id), key: key}
+}
+
+// credential returns the credential to register for the authenticator.
+func (a *testAuthenticator) credential() *webauthn.Credential {
+	x, y := make([]byte, 32), make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	publicKey, _ := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, // P-256
+		XCoord: x,
+		YCoord: y,
+	})
+	return &webauthn.Credential{ID: a.id, PublicKey: publicKey}
+}
+
+// assert returns the request body of an assertion over challenge for rpID
+// and origin, naming userHandle as the credential's user.
+func (a *testAuthenticator) assert(challenge []byte, rpID string, origin string, userHandle []byte) ([]byte, error) {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      "webauthn.get",
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := make([]byte, 37)
+	copy(authData, rpIDHash[:])
+	authData[32] = 0x05 // user present and verified
+	binary.BigEndian.PutUint32(authData[33:], 1)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	return json.Marshal(map[string]interface{}{
+		"id":    enc(a.id),
+		"rawId": enc(a.id),
+		"type":  "public-key",
+		"response": map[string]string{
+			"authenticatorData": enc(authData),
+			"clientDataJSON":    enc(clientData),
+			"signature":         enc(signature),
+			"userHandle":        enc(userHandle),
+		},
+	})
+}
+
+// attest returns the request body of a registration of the credential over
+// challenge for rpID and origin, with no attestation statement.
+func (a *testAuthenticator) attest(challenge []byte, rpID string, origin string) ([]byte, error) {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      "webauthn.create",
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := append(rpIDHash[:], 0x45, 0, 0, 0, 0) // user present and verified, attested data
+	authData = append(authData, make([]byte, 16)...)  // AAGUID
+	authData = append(authData, byte(len(a.id)>>8), byte(len(a.id)))
+	authData = append(append(authData, a.id...), a.credential().PublicKey...)
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	return json.Marshal(map[string]interface{}{
+		"id":    enc(a.id),
+		"rawId": enc(a.id),
+		"type":  "public-key",
+		"response": map[string]string{
+			"attestationObject": enc(attestationObject),
+			"clientDataJSON":    enc(clientData),
+		},
+	})
+}
+
+// conditionalLogin runs an autofill login with a, whose authenticator names
+// userHandle as the credential's user.
+func (ss *ServerSuite) conditionalLogin(a *testAuthenticator, userHandle []byte) *httptest.ResponseRecorder {
+	w := ss.serve(httptest.NewRequest("POST", "/assertion/conditional", nil))
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var begun struct {
+		ID        string `json:"id"`
+		PublicKey struct {
+			Challenge []byte `json:"challenge"`
+		} `json:"publicKey"`
+	}
+	ss.Require().NoError(json.Unmarshal(w.Body.Bytes(), &begun))
+
+	body, err := a.assert(begun.PublicKey.Challenge, ss.config.RelyingParty, ss.config.RPOrigin, userHandle)
+	ss.Require().NoError(err)
+	return ss.serve(httptest.NewRequest("POST", "/assertion/conditional/"+begun.ID, bytes.NewReader(body)))
+}
+
+// sessionCookie returns the login session cookie w set, if any.
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == SessionCookie && c.Value != "" {
+			return c
+		}
+	}
+	return nil
+}
+
+func (ss *ServerSuite) TestDiscoverableLoginResolvesUserHandle() {
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	a := ss.newAuthenticator("alice-1")
+	ss.register(alice, a.credential())
+	bob := &models.User{Username: "bob", DisplayName: "bob"}
+	ss.register(bob, ss.newAuthenticator("bob-1").credential())
+
+	w := ss.conditionalLogin(a, alice.WebAuthnID())
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var user models.User
+	ss.Require().NoError(json.Unmarshal(w.Body.Bytes(), &user))
+	ss.Equal("alice", user.Username)
+	ss.NotNil(sessionCookie(w))
+
+	// The handle picks the user whose credentials are checked, so naming
+	// another user does not log in as them.
+	w = ss.conditionalLogin(a, bob.WebAuthnID())
+	ss.NotEqual(http.StatusOK, w.Code, w.Body.String())
+	ss.Nil(sessionCookie(w))
+}
+
+func (ss *ServerSuite) TestDiscoverableLoginUnknownUserHandle() {
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	a := ss.newAuthenticator("alice-1")
+	ss.register(alice, a.credential())
+
+	unknown := models.User{}
+	unknown.ID = alice.ID + 1000
+	w := ss.conditionalLogin(a, unknown.WebAuthnID())
+	ss.Equal(http.StatusUnauthorized, w.Code, w.Body.String())
+	ss.Nil(sessionCookie(w))
+}
+
+func (ss *ServerSuite) TestDiscoverableLoginEmptyUserHandle() {
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	a := ss.newAuthenticator("alice-1")
+	ss.register(alice, a.credential())
+
+	w := ss.conditionalLogin(a, nil)
+	ss.Equal(http.StatusBadRequest, w.Code, w.Body.String())
+	ss.Nil(sessionCookie(w))
+}
+
+func (ss *ServerSuite) TestConditionalLoginOnAnotherReplica() {
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	a := ss.newAuthenticator("alice-1")
+	ss.register(alice, a.credential())
+
+	w := ss.serve(httptest.NewRequest("POST", "/assertion/conditional", nil))
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var begun struct {
+		ID        string `json:"id"`
+		PublicKey struct {
+			Challenge []byte `json:"challenge"`
+		} `json:"publicKey"`
+	}
+	ss.Require().NoError(json.Unmarshal(w.Body.Bytes(), &begun))
+	body, err := a.assert(begun.PublicKey.Challenge, ss.config.RelyingParty, ss.config.RPOrigin, alice.WebAuthnID())
+	ss.Require().NoError(err)
+
+	// Another server over the same database finishes the login.
+	ctrl, err := controller.New(ss.store, &config.SonrConfig{}, nil)
+	ss.Require().NoError(err)
+	replica, err := NewServer(ctrl, ss.config)
+	ss.Require().NoError(err)
+	w = httptest.NewRecorder()
+	replica.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/assertion/conditional/"+begun.ID, bytes.NewReader(body)))
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	ss.NotNil(sessionCookie(w))
+}
@@ -1,12 +1,9 @@
 package server
 
 import (
-	"encoding/base64"
+	"encoding/json"
 	"errors"
-	"fmt"
-	"math/rand"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/duo-labs/webauthn/protocol"
@@ -14,53 +11,97 @@ import (
 	"github.com/gorilla/mux"
 	log "github.com/sonr-io/webauthn.io/logger"
 	"github.com/sonr-io/webauthn.io/models"
+	"github.com/sonr-io/webauthn.io/pkg/attestation"
 	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
 )
 
+// pendingUserKey is the session key holding a user whose registration has
+// begun but not yet completed.
+const pendingUserKey = "registration-user"
+
+// addCredentialKey is the session key holding the ceremony of a logged in
+// user adding a credential.
+const addCredentialKey = "add-credential"
+
 // RequestNewCredential begins a Credential Registration Request, returning a
 // PublicKeyCredentialCreationOptions object
 func (ws *Server) RequestNewCredential(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
 
-	var takenNames = []string{"api", "tx", "app", "arianagrande", "azamsharp", "barrybonds", "barrysanders", "billgates", "britneyspears", "cdixon", "cristiano", "drake", "elon", "eminem", "flotus", "iamsrk", "imap", "index", "jack", "jbbernstein", "jeffbezos", "jimmyfallon", "joynerlucas", "jtimberlake", "justinbieber", "katyperry", "kimkardashian", "kingjames", "ladygaga", "larrypage", "launchhouse", "logic", "mail", "main", "markzuckerburg", "meekmill", "naval", "neymarjr", "oprah", "patrickbetdavid", "pop", "potus", "prad", "rihanna", "root", "satyanadella", "sc", "selenagomez", "sergeibrin", "shakira", "shl", "smartrick", "srbachchan", "stephencurry", "sundarpichai", "taylorswift", "tombrady", "vitalik", "michael", "prad2", "papa", "ikj", "ian", "shadowysupercoder", "ianperez", "perez", "0x0", "zac", "smartrick", "holwerda", "zholwerda", "NFT", "classof.o7", "goat", "nsfw", "nick", "ntindle", "nicktindle", "cloud", "devops", "engineer", "ntt", "grace", "get", "gtindle", "0xDEADBEEF", "static", "d0x", "null", "exposure", "zach", "joshLong145", "beanPole", "undefined", "Peyton", "gopher", "cosmic", "lauren", "sonr", "prad", "letsgobrandon", "snr", "erin", "jamey", "monica", "Space", "timmy", "creaton", "Warriors", "BestButt", "Mfers", "Beast", "mary", "david", "RX", "NT", "0X", "OK", "NO", "SN", "GB", "GT", "IP", "AH", "PT", "JL", "AF", "0F", "0p", "00", "C0", "80", "snr", "sonr", "xxxtentacion", "yasht", "teksupport", "luffy", "yeah"}
-
-	username := vars["name"]
-	//check length restrictions
-	if len(username) < 2 {
-		jsonResponse(w, errors.New("name too short"), http.StatusInternalServerError)
+	//The trimmer
+	username := models.TrimName(vars["name"])
+	if err := models.ValidateName(username); err != nil {
+		jsonResponse(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	//alphanumeric restrictions
-	re := regexp.MustCompile("^[a-zA-Z0-9_]*$")
-	if !re.MatchString(username) {
-		jsonResponse(w, errors.New("name not alphanumeric"), http.StatusInternalServerError)
+	available, err := ws.Ctrl.NameAvailable(ctx, username)
+	if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	} else if !available {
+		jsonResponse(w, models.ErrUsernameTaken, http.StatusInternalServerError)
 		return
 	}
 
-	//The trimmer
-	if len(username) > 4 && username[len(username)-4:] == ".snr" {
-		username = username[:len(username)-4]
-	}
-	available, err := ws.Ctrl.CheckName(ctx, username)
+	//SQL lite check
+	// user, err := models.GetUserByUsername(username)
+	// if err != nil {
+	// 	user = models.User{
+	// 		DisplayName: strings.Split(username, "@")[0],
+	// 		Username:    username,
+	// 	}
+	// 	err = models.PutUser(&user)
+	// 	if err != nil {
+	// 		jsonResponse(w, "Error creating new user", http.StatusInternalServerError)
+	// 		return
+	// 	}
+	// }
 
-	// if reserved
-	for _, x := range takenNames {
-		if strings.ToLower(x) == strings.ToLower(username) {
-			available = false
-			break
+	//secondary mongo check
+	user, err := ws.Ctrl.FindUserByName(ctx, username)
+
+	// user doesn't exist, create new user. It is only stored once the
+	// registration completes; until then it lives in the session.
+	if errors.Is(err, models.ErrNameNotFound) {
+		var names []string
+		names = append(names, username)
+		user = &models.User{}
+		user.DisplayName = username
+		user.Names = names
+		user.Username = username
+		user.DisplayName = username
+		if user.ID, err = models.NewUserID(); err == nil {
+			err = ws.store.SaveJSON(pendingUserKey, user, r, w)
 		}
 	}
+	if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	}
 
+	credentialOptions, sessionData, err := ws.webauthn.BeginRegistration(user, ws.registrationOptions(r)...)
 	if err != nil {
 		jsonResponse(w, err.Error(), http.StatusInternalServerError)
 		return
-	} else if !available {
-		jsonResponse(w, errors.New("name already taken"), http.StatusInternalServerError)
+	}
+
+	// Save the session data as marshaled JSON
+	err = ws.store.SaveWebauthnSession("registration", sessionData, r, w)
+	if err != nil {
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// Return the PublicKeyCreationOptions back to the browser
+	jsonResponse(w, credentialOptions, http.StatusOK)
+	return
+}
+
+// registrationOptions reads the ceremony settings chosen on the login page.
+// The attestation policy may override the attestation the client asked for.
+func (ws *Server) registrationOptions(r *http.Request) []webauthn.RegistrationOption {
 	// Most times relying parties will choose these.
 	attType := r.FormValue("attType")
 	authType := r.FormValue("authType")
@@ -70,76 +111,63 @@ func (ws *Server) RequestNewCredential(w http.ResponseWriter, r *http.Request) {
 	resKey := r.FormValue("residentKeyRequirement")
 	testExtension := r.FormValue("txAuthExtension")
 
+	// Discoverable credentials are preferred even when not required, so
+	// passkeys can log in without a username.
 	var residentKeyRequirement *bool
+	residentKey := protocol.ResidentKeyRequirementPreferred
 	if strings.EqualFold(resKey, "true") {
 		residentKeyRequirement = protocol.ResidentKeyRequired()
+		residentKey = protocol.ResidentKeyRequirementRequired
 	} else {
 		residentKeyRequirement = protocol.ResidentKeyUnrequired()
 	}
 
 	testEx := protocol.AuthenticationExtensions(map[string]interface{}{"txAuthSimple": testExtension})
 
-	//SQL lite check
-	// user, err := models.GetUserByUsername(username)
-	// if err != nil {
-	// 	user = models.User{
-	// 		DisplayName: strings.Split(username, "@")[0],
-	// 		Username:    username,
-	// 	}
-	// 	err = models.PutUser(&user)
-	// 	if err != nil {
-	// 		jsonResponse(w, "Error creating new user", http.StatusInternalServerError)
-	// 		return
-	// 	}
-	// }
-
-	//secondary mongo check
-	user := ws.Ctrl.FindUserByName(ctx, username)
-
-	// user doesn't exist, create new user
-	if user.DisplayName == "" {
-		available, _ := ws.Ctrl.CheckName(ctx, username)
-		if !available {
-			jsonResponse(w, fmt.Errorf("username is not availabel to use"), http.StatusAlreadyReported)
-			return
-		}
-		var names []string
-		names = append(names, username)
-		did := "did:sonr:temp" + username
-		user.DisplayName = username
-		user.Names = names
-		user.Did = did
-		user.ID = uint(rand.Uint32())
-		user.Username = username
-		user.DisplayName = username
-		ws.Ctrl.NewUser(ctx, *user)
+	conveyance := protocol.ConveyancePreference(attType)
+	if required := ws.Ctrl.AttestationConveyance(); required != "" {
+		conveyance = required
 	}
 
-	credentialOptions, sessionData, err := ws.webauthn.BeginRegistration(user,
+	return []webauthn.RegistrationOption{
 		webauthn.WithAuthenticatorSelection(
 			protocol.AuthenticatorSelection{
 				AuthenticatorAttachment: protocol.AuthenticatorAttachment(authType),
 				RequireResidentKey:      residentKeyRequirement,
+				ResidentKey:             residentKey,
 				UserVerification:        protocol.UserVerificationRequirement(userVer),
 			}),
-		webauthn.WithConveyancePreference(protocol.ConveyancePreference(attType)),
+		webauthn.WithConveyancePreference(conveyance),
 		webauthn.WithExtensions(testEx),
-	)
-	if err != nil {
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
-		return
 	}
+}
 
-	// Save the session data as marshaled JSON
-	err = ws.store.SaveWebauthnSession("registration", sessionData, r, w)
+// finishRegistration verifies the registration response in the request body
+// and applies the attestation policy to its authenticator. It returns the
+// credential and its attestation type.
+func (ws *Server) finishRegistration(user *models.User, sessionData webauthn.SessionData, r *http.Request) (*webauthn.Credential, string, error) {
+	parsedResponse, err := protocol.ParseCredentialCreationResponse(r)
 	if err != nil {
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, "", err
+	}
+	cred, err := ws.webauthn.CreateCredential(user, sessionData, parsedResponse)
+	if err != nil {
+		return nil, "", err
 	}
+	attestationType, err := ws.Ctrl.VerifyAttestation(parsedResponse)
+	if err != nil {
+		log.Warnf("refused registration for %s: %s", user.Username, err)
+		return nil, "", err
+	}
+	return cred, attestationType, nil
+}
 
-	// Return the PublicKeyCreationOptions back to the browser
-	jsonResponse(w, credentialOptions, http.StatusOK)
-	return
+// registrationStatus maps a failed registration to an HTTP status code.
+func registrationStatus(err error) int {
+	if errors.Is(err, attestation.ErrRejected) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
 }
 
 // MakeNewCredential attempts to make a new credential given an authenticator's response
@@ -153,108 +181,146 @@ func (ws *Server) MakeNewCredential(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the user associated with the credential
-	user, err := ws.Ctrl.GetUser(models.BytesToID(sessionData.UserID))
-
+	// Get the user the ceremony is creating, or else the stored user it
+	// belongs to. The pending user comes first: should its ID match a stored
+	// user, creating it fails rather than adding the credential to them.
+	userID := models.BytesToID(sessionData.UserID)
+	user, create := &models.User{}, true
+	if ws.store.GetJSON(pendingUserKey, r, user) != nil || user.ID != userID {
+		user, err = ws.Ctrl.GetUser(ctx, userID)
+		create = false
+	}
 	if err != nil {
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		jsonResponse(w, err.Error(), storeStatus(err))
 		return
 	}
 
-	// Verify that the challenge succeeded
-	cred, err := ws.webauthn.FinishRegistration(user, sessionData, r)
+	// Verify that the challenge succeeded and the authenticator is allowed
+	cred, attestationType, err := ws.finishRegistration(user, sessionData, r)
 	if err != nil {
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		jsonResponse(w, err.Error(), registrationStatus(err))
 		return
 	}
 
-	// If needed, you can perform additional checks here to ensure the
-	// authenticator and generated credential conform to your requirements.
-
 	// Finally, save the credential and authenticator to the
 	// database
-	authenticator, err := ws.Ctrl.CreateAuthenticator(cred.Authenticator)
+	c, err := ws.Ctrl.RegisterCredential(ctx, user, cred, attestationType, create)
 	if err != nil {
-		log.Errorf("error creating authenticator: %v", err)
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		log.Errorf("error registering credential: %v", err)
+		jsonResponse(w, err.Error(), storeStatus(err))
 		return
 	}
 
-	// For our use case, we're encoding the raw credential ID as URL-safe
-	// base64 since we anticipate rendering it in templates. If you choose to
-	// do this, make sure to decode the credential ID before passing it back to
-	// the webauthn library.
-	credentialID := base64.URLEncoding.EncodeToString(cred.ID)
-	c := &models.Credential{
-		Authenticator:   authenticator,
-		AuthenticatorID: authenticator.ID,
-		UserID:          user.ID,
-		PublicKey:       cred.PublicKey,
-		CredentialID:    credentialID,
-	}
-
-	fmt.Println(c.CredentialID)
-	fmt.Println(c.UserID)
-
-	err = ws.Ctrl.CreateCredential(c)
-	if err != nil {
-		jsonResponse(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	//store public key on did
-	did := "did:sonr:" + credentialID
-	regName := &rt.MsgRegisterName{Creator: "", NameToRegister: user.Username}
-	ws.Ctrl.AttachDid(ctx, "did:sonr:temp"+user.DisplayName, did)
-
 	//register name on chain
-
-	fmt.Println(did)
-	fmt.Println(regName)
+	regName := &rt.MsgRegisterName{Creator: "", NameToRegister: user.Username}
+	log.Infof("registered credential %s for %s", c.CredentialID, user.Did)
+	log.Debug(regName)
 
 	//TODO fix async issue on stripe and no need for cron job
 	//ws.Ctrl.RegisterName(ctx, regName, did, c)
 
-	//store cred under user in mgo
-	ws.Ctrl.GiveUserCred(user.Username, c)
+	// A new account gets its recovery codes now, the only time they are
+	// shown. Failing to issue them does not undo the registration; they can
+	// be issued again from the dashboard.
+	var res recoveryCodesResponse
+	if create {
+		if res.RecoveryCodes, err = ws.Ctrl.IssueRecoveryCodes(ctx, user); err != nil {
+			log.Errorf("error issuing recovery codes for %s: %v", user.Username, err)
+		}
+	}
+	jsonResponse(w, res, http.StatusCreated)
+}
 
-	jsonResponse(w, http.StatusText(http.StatusCreated), http.StatusCreated)
+// RequestAdditionalCredential begins registering another credential for the
+// logged in user. The user's existing credentials are excluded, so the same
+// authenticator cannot be registered twice.
+func (ws *Server) RequestAdditionalCredential(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	opts := append(ws.registrationOptions(r), webauthn.WithExclusions(user.CredentialExcludeList()))
+	credentialOptions, sessionData, err := ws.webauthn.BeginRegistration(user, opts...)
+	if err != nil {
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := ws.store.SaveWebauthnSession(addCredentialKey, sessionData, r, w); err != nil {
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, credentialOptions, http.StatusOK)
 }
 
-// GetCredentials gets a user's credentials from the db
-func (ws *Server) GetCredentials(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	//The trimmer
-	username := vars["name"]
-	if username[len(username)-4:] == ".snr" {
-		username = username[:len(username)-4]
+// MakeAdditionalCredential finishes registering another credential for the
+// logged in user, appending it to their credentials.
+func (ws *Server) MakeAdditionalCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	sessionData, err := ws.store.GetWebauthnSession(addCredentialKey, r)
+	if err != nil {
+		jsonResponse(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	u, err := ws.Ctrl.GetUserByUsername(username)
+	// The ceremony must have been started by the user still logged in.
+	if models.BytesToID(sessionData.UserID) != user.ID {
+		jsonResponse(w, "registration was started by another user", http.StatusForbidden)
+		return
+	}
+
+	cred, attestationType, err := ws.finishRegistration(user, sessionData, r)
+	if err != nil {
+		jsonResponse(w, err.Error(), registrationStatus(err))
+		return
+	}
+	c, err := ws.Ctrl.RegisterCredential(ctx, user, cred, attestationType, false)
 	if err != nil {
-		log.Errorf("user not found: %s: %s", username, err)
-		jsonResponse(w, "User not found", http.StatusNotFound)
+		log.Errorf("error adding credential: %v", err)
+		jsonResponse(w, err.Error(), storeStatus(err))
 		return
 	}
-	cs, err := ws.Ctrl.GetCredentialsForUser(u)
+	log.Infof("added credential %s for %s", c.CredentialID, user.Username)
+	jsonResponse(w, c, http.StatusCreated)
+}
+
+// ListCredentials lists the logged in user's credentials.
+func (ws *Server) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	cs, err := ws.Ctrl.ListCredentials(r.Context(), user)
 	if err != nil {
 		log.Error(err)
-		jsonResponse(w, "Credentials not found", http.StatusNotFound)
+		jsonResponse(w, err.Error(), credentialStatus(err))
 		return
 	}
 	jsonResponse(w, cs, http.StatusOK)
 }
 
-// DeleteCredential deletes a credential from the db
-func (ws *Server) DeleteCredential(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	credID := vars["id"]
-	err := ws.Ctrl.DeleteCredentialByID(credID)
-	log.Infof("deleting credential: %s", credID)
+// RenameCredential sets the nickname of one of the logged in user's
+// credentials. The body is a JSON object with a nickname field.
+func (ws *Server) RenameCredential(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	var body struct {
+		Nickname string `json:"nickname"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonResponse(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	c, err := ws.Ctrl.RenameCredential(r.Context(), user, mux.Vars(r)["id"], body.Nickname)
 	if err != nil {
-		log.Errorf("error deleting credential: %s", err)
-		jsonResponse(w, "Credential not Found", http.StatusNotFound)
+		jsonResponse(w, err.Error(), credentialStatus(err))
+		return
+	}
+	jsonResponse(w, c, http.StatusOK)
+}
+
+// RevokeCredential deletes one of the logged in user's credentials.
+func (ws *Server) RevokeCredential(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	credID := mux.Vars(r)["id"]
+	if err := ws.Ctrl.RevokeCredential(r.Context(), user, credID); err != nil {
+		log.Errorf("error revoking credential %s: %s", credID, err)
+		jsonResponse(w, err.Error(), credentialStatus(err))
 		return
 	}
+	log.Infof("revoked credential %s of %s", credID, user.Username)
 	jsonResponse(w, "Success", http.StatusOK)
 }
 
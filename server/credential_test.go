@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+func (ss *ServerSuite) TestRequestAdditionalCredentialExcludesCredentials() {
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	first := ss.register(alice, &webauthn.Credential{ID: []byte("alice-1"), PublicKey: []byte("key-1")})
+	ss.register(alice, &webauthn.Credential{ID: []byte("alice-2"), PublicKey: []byte("key-2")})
+	bob := &models.User{Username: "bob", DisplayName: "bob"}
+	ss.register(bob, &webauthn.Credential{ID: []byte("bob-1"), PublicKey: []byte("key-3")})
+
+	w := ss.serve(httptest.NewRequest("GET", "/credential/add", nil), ss.login(alice, first.CredentialID))
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var options protocol.CredentialCreation
+	ss.Require().NoError(json.Unmarshal(w.Body.Bytes(), &options))
+
+	// Every one of alice's credentials is excluded, and nobody else's.
+	var excluded []string
+	for _, d := range options.Response.CredentialExcludeList {
+		ss.Equal(protocol.PublicKeyCredentialType, d.Type)
+		excluded = append(excluded, string(d.CredentialID))
+	}
+	ss.ElementsMatch([]string{"alice-1", "alice-2"}, excluded)
+	ss.Equal(alice.WebAuthnID(), []byte(options.Response.User.ID))
+}
+
+func (ss *ServerSuite) TestMakeAdditionalCredentialRejectsAnotherUsersCeremony() {
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	a := ss.register(alice, &webauthn.Credential{ID: []byte("alice-1"), PublicKey: []byte("key-1")})
+	bob := &models.User{Username: "bob", DisplayName: "bob"}
+	b := ss.register(bob, &webauthn.Credential{ID: []byte("bob-1"), PublicKey: []byte("key-2")})
+
+	// Alice begins adding a credential; the ceremony is kept in the
+	// browser's session cookie.
+	w := ss.serve(httptest.NewRequest("GET", "/credential/add", nil), ss.login(alice, a.CredentialID))
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	ceremony := w.Result().Cookies()
+	ss.Require().NotEmpty(ceremony)
+
+	// Bob logging in on the same browser cannot finish it.
+	w = ss.serve(httptest.NewRequest("POST", "/credential/add", strings.NewReader("{}")), append(ceremony, ss.login(bob, b.CredentialID))...)
+	ss.Equal(http.StatusForbidden, w.Code, w.Body.String())
+	user, err := ss.server.Ctrl.GetUser(context.Background(), bob.ID)
+	ss.Require().NoError(err)
+	ss.Len(user.WebAuthnCredentials(), 1)
+
+	// Alice herself gets past the check, to the response parsing.
+	w = ss.serve(httptest.NewRequest("POST", "/credential/add", strings.NewReader("{}")), append(ceremony, ss.login(alice, a.CredentialID))...)
+	ss.Equal(http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+// beginRegistration starts signing up name and returns the ceremony's
+// cookies, challenge and user ID.
+func (ss *ServerSuite) beginRegistration(name string) ([]*http.Cookie, []byte, uint) {
+	w := ss.serve(httptest.NewRequest("GET", "/makeCredential/"+name, nil))
+	ss.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var begun struct {
+		PublicKey struct {
+			Challenge []byte `json:"challenge"`
+			User      struct {
+				ID []byte `json:"id"`
+			} `json:"user"`
+		} `json:"publicKey"`
+	}
+	ss.Require().NoError(json.Unmarshal(w.Body.Bytes(), &begun))
+
+	// The session is saved once per value; a browser keeps the last cookie.
+	var cookies []*http.Cookie
+	seen := make(map[string]bool)
+	all := w.Result().Cookies()
+	for i := len(all) - 1; i >= 0; i-- {
+		if !seen[all[i].Name] {
+			seen[all[i].Name] = true
+			cookies = append(cookies, all[i])
+		}
+	}
+	return cookies, begun.PublicKey.Challenge, models.BytesToID(begun.PublicKey.User.ID)
+}
+
+func (ss *ServerSuite) TestMakeNewCredentialCreatesUser() {
+	cookies, challenge, id := ss.beginRegistration("alice")
+	body, err := ss.newAuthenticator("alice-1").attest(challenge, ss.config.RelyingParty, ss.config.RPOrigin)
+	ss.Require().NoError(err)
+
+	w := ss.serve(httptest.NewRequest("POST", "/makeCredential", bytes.NewReader(body)), cookies...)
+	ss.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	user, err := ss.server.Ctrl.GetUser(context.Background(), id)
+	ss.Require().NoError(err)
+	ss.Equal("alice", user.Username)
+	ss.Len(user.WebAuthnCredentials(), 1)
+}
+
+func (ss *ServerSuite) TestMakeNewCredentialKeepsCollidingUser() {
+	cookies, challenge, id := ss.beginRegistration("mallory")
+
+	// A user stored meanwhile under the same ID must not receive the new
+	// sign-up's credential.
+	alice := &models.User{Username: "alice", DisplayName: "alice"}
+	alice.ID = id
+	ss.Require().NoError(ss.store.NewUser(context.Background(), alice))
+	ss.register(alice, &webauthn.Credential{ID: []byte("alice-1"), PublicKey: []byte("key-1")})
+
+	body, err := ss.newAuthenticator("mallory-1").attest(challenge, ss.config.RelyingParty, ss.config.RPOrigin)
+	ss.Require().NoError(err)
+	w := ss.serve(httptest.NewRequest("POST", "/makeCredential", bytes.NewReader(body)), cookies...)
+	ss.Equal(http.StatusConflict, w.Code, w.Body.String())
+	user, err := ss.server.Ctrl.GetUser(context.Background(), id)
+	ss.Require().NoError(err)
+	ss.Equal("alice", user.Username)
+	ss.Len(user.WebAuthnCredentials(), 1)
+}
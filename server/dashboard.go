@@ -10,16 +10,17 @@ import (
 // as well as any other credentials previously registered by the authenticated
 // user.
 func (ws *Server) Index(w http.ResponseWriter, r *http.Request) {
-	user := r.Context().Value("user").(models.User)
-	credentials, err := ws.Ctrl.GetCredentialsForUser(&user)
+	user := r.Context().Value("user").(*models.User)
+	credentials, err := ws.Ctrl.GetCredentialsForUser(r.Context(), user)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		http.Error(w, http.StatusText(storeStatus(err)), storeStatus(err))
+		return
 	}
 	templateData := struct {
 		User        models.User
 		Credentials []models.Credential
 	}{
-		user,
+		*user,
 		credentials,
 	}
 	renderTemplate(w, "index.html", templateData)
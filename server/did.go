@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sonr-io/webauthn.io/pkg/did"
+)
+
+// didResolverPath is the DID resolution endpoint, following the path used by
+// the DIF Universal Resolver.
+const didResolverPath = "/1.0/identifiers/"
+
+// ResolveDid writes the DID document of the DID in the request path.
+func (ws *Server) ResolveDid(w http.ResponseWriter, r *http.Request) {
+	id := didFromPath(r)
+	doc, err := ws.Ctrl.ResolveDid(r.Context(), id)
+	if err != nil {
+		jsonResponse(w, err.Error(), didStatus(err))
+		return
+	}
+	dj, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/did+ld+json")
+	w.Write(dj)
+}
+
+// didFromPath extracts the DID from the request path. Percent-encoded
+// characters are part of a DID's method-specific id, so the escaped form is
+// preferred and the unescaped form is only used when the client escaped the
+// whole DID.
+func didFromPath(r *http.Request) string {
+	raw := strings.TrimPrefix(r.URL.EscapedPath(), didResolverPath)
+	if _, err := did.Parse(raw); err == nil {
+		return raw
+	}
+	if unescaped, err := url.PathUnescape(raw); err == nil {
+		return unescaped
+	}
+	return raw
+}
+
+// didStatus maps DID resolution errors to HTTP status codes.
+func didStatus(err error) int {
+	switch {
+	case errors.Is(err, did.ErrInvalidDid):
+		return http.StatusBadRequest
+	case errors.Is(err, did.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, did.ErrUnsupportedMethod):
+		return http.StatusNotImplemented
+	default:
+		return storeStatus(err)
+	}
+}
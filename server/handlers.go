@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
 	"time"
@@ -19,6 +20,17 @@ import (
 // for timing out read/write operations
 const Timeout = 5 * time.Second
 
+// ConditionalTimeout is how long an autofill login challenge stays valid. The
+// browser holds it for as long as the login page is open, so it is longer
+// than a modal ceremony's. Challenges are kept in the database, so a login
+// begun on one replica can be finished on another.
+const ConditionalTimeout = 10 * time.Minute
+
+// MaxConditionalChallenges bounds the autofill login challenges held at
+// once. Any visitor can open one, so past this many new logins are refused
+// until older challenges expire or are used.
+const MaxConditionalChallenges = 10000
+
 // Option is an option that sets a particular value for the server
 type Option func(*Server)
 
@@ -29,9 +41,18 @@ type Server struct {
 	config   *config.Config
 	webauthn *webauthn.WebAuthn
 	store    *session.Store
+	mounts   []mount
 	Ctrl     *controller.Controller
 }
 
+// mount is a handler served beneath a path prefix, or for the requests a
+// matcher accepts when the prefix is empty
+type mount struct {
+	prefix  string
+	match   func(*http.Request) bool
+	handler http.Handler
+}
+
 // NewServer returns a new instance of a Server configured with the provided
 // configuration
 func NewServer(ctrl *controller.Controller, config *config.Config, opts ...Option) (*Server, error) {
@@ -41,7 +62,10 @@ func NewServer(ctrl *controller.Controller, config *config.Config, opts ...Optio
 		ReadTimeout:  Timeout,
 		WriteTimeout: Timeout,
 	}
-	defaultStore, err := session.NewStore()
+	if len(config.SessionKeys) == 0 {
+		log.Printf("No session keys configured, sessions end when the server restarts")
+	}
+	defaultStore, err := session.NewStore(config.SessionKeys...)
 	if err != nil {
 		return nil, err
 	}
@@ -71,36 +95,122 @@ func WithWebAuthn(w *webauthn.WebAuthn) Option {
 	}
 }
 
+// WithHandler serves h beneath prefix with the prefix stripped from the
+// request path
+func WithHandler(prefix string, h http.Handler) Option {
+	return func(ws *Server) {
+		ws.mounts = append(ws.mounts, mount{prefix: prefix, handler: h})
+	}
+}
+
+// WithMatchedHandler serves h for every request match accepts, ahead of all
+// other routes
+func WithMatchedHandler(match func(*http.Request) bool, h http.Handler) Option {
+	return func(ws *Server) {
+		ws.mounts = append(ws.mounts, mount{match: match, handler: h})
+	}
+}
+
+// WithWriteTimeout overrides Timeout for writing responses. Zero disables the
+// timeout, which long lived streaming responses require.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(ws *Server) {
+		ws.server.WriteTimeout = d
+	}
+}
+
+// WithTLS serves HTTPS using the given configuration
+func WithTLS(c *tls.Config) Option {
+	return func(ws *Server) {
+		ws.server.TLSConfig = c
+	}
+}
+
+// Addr returns the address the server is configured to listen on
+func (ws *Server) Addr() string {
+	return ws.server.Addr
+}
+
+// Handler returns the router serving every HTTP route
+func (ws *Server) Handler() http.Handler {
+	return ws.server.Handler
+}
+
 // Start starts the underlying HTTP server
 func (ws *Server) Start() error {
-	log.Printf("Starting webauthn server at %s", ws.server.Addr)
-	return ws.server.ListenAndServe()
+	l, err := net.Listen("tcp", ws.server.Addr)
+	if err != nil {
+		return err
+	}
+	return ws.Serve(l)
+}
+
+// Serve accepts incoming HTTP connections on the provided listener. It
+// returns http.ErrServerClosed once Shutdown has been called.
+func (ws *Server) Serve(l net.Listener) error {
+	log.Printf("Starting webauthn server at %s", l.Addr())
+	if ws.server.TLSConfig != nil {
+		l = tls.NewListener(l, ws.server.TLSConfig)
+	}
+	return ws.server.Serve(l)
 }
 
-// Shutdown attempts to gracefully shutdown the underlying HTTP server.
-func (ws *Server) Shutdown() error {
-	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
-	defer cancel()
+// Shutdown attempts to gracefully shutdown the underlying HTTP server. If ctx
+// has no deadline, Timeout is used.
+func (ws *Server) Shutdown(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Timeout)
+		defer cancel()
+	}
 	return ws.server.Shutdown(ctx)
 }
 
 func (ws *Server) registerRoutes() {
 	router := mux.NewRouter()
+	for _, m := range ws.mounts {
+		if m.match != nil {
+			match := m.match
+			router.MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+				return match(r)
+			}).Handler(m.handler)
+		}
+	}
 	// Unauthenticated handlers for registering a new credential and logging in.
 	router.HandleFunc("/", ws.Login)
 	router.HandleFunc("/makeCredential/{name}", ws.RequestNewCredential).Methods("GET")
 	router.HandleFunc("/makeCredential", ws.MakeNewCredential).Methods("POST")
 	router.HandleFunc("/assertion/{name}", ws.GetAssertion).Methods("GET")
+	router.HandleFunc("/assertion", ws.GetDiscoverableAssertion).Methods("GET")
 	router.HandleFunc("/assertion", ws.MakeAssertion).Methods("POST")
+	router.HandleFunc("/assertion/conditional", ws.GetConditionalAssertion).Methods("POST")
+	router.HandleFunc("/assertion/conditional/{id}", ws.MakeConditionalAssertion).Methods("POST")
+	router.HandleFunc("/recover", ws.RecoveryPage).Methods("GET")
+	router.HandleFunc("/recovery", ws.StartRecovery).Methods("POST")
 	router.HandleFunc("/user/{name}/exists", ws.UserExists).Methods("GET")
-	router.HandleFunc("/user/{name}/credentials", ws.GetCredentials).Methods("GET")
 
 	//helper handlers
 	router.HandleFunc("/check/name/{name}", ws.CheckName).Methods("GET")
 	router.HandleFunc("/health", ws.HealthHandler).Methods("GET")
+	router.PathPrefix(didResolverPath).HandlerFunc(ws.ResolveDid).Methods("GET")
 
 	// Authenticated handlers for viewing credentials after logging in
 	router.HandleFunc("/dashboard", ws.LoginRequired(ws.Index))
+	router.HandleFunc("/session/token", ws.LoginRequired(ws.SessionToken)).Methods("GET")
+	router.HandleFunc("/credential/add", ws.LoginRequired(ws.RequestAdditionalCredential)).Methods("GET")
+	router.HandleFunc("/credential/add", ws.LoginRequired(ws.MakeAdditionalCredential)).Methods("POST")
+	router.HandleFunc("/credentials", ws.LoginRequired(ws.ListCredentials)).Methods("GET")
+	router.HandleFunc("/credentials/{id}", ws.LoginRequired(ws.RenameCredential)).Methods("PUT")
+	router.HandleFunc("/credentials/{id}", ws.LoginRequired(ws.RevokeCredential)).Methods("DELETE")
+	router.HandleFunc("/recovery/codes", ws.LoginRequired(ws.IssueRecoveryCodes)).Methods("POST")
+	router.HandleFunc("/logout", ws.LoginRequired(ws.Logout)).Methods("POST")
+	router.HandleFunc("/sessions", ws.LoginRequired(ws.SessionsPage)).Methods("GET")
+	router.HandleFunc("/sessions", ws.LoginRequired(ws.EndAllSessions)).Methods("DELETE")
+	router.HandleFunc("/sessions/{id}", ws.LoginRequired(ws.EndSession)).Methods("DELETE")
+
+	// A recovery session can only enroll a new credential
+	router.HandleFunc("/recovery/credential", ws.RecoveryRequired(ws.RequestRecoveryCredential)).Methods("GET")
+	router.HandleFunc("/recovery/credential", ws.RecoveryRequired(ws.MakeRecoveryCredential)).Methods("POST")
 	//router.HandleFunc("/register/name/{name}", ws.RegisterName).Methods("POST")
 
 	//stripe
@@ -112,6 +222,13 @@ func (ws *Server) registerRoutes() {
 	router.HandleFunc("/payment", ws.PaymentPage)
 	router.HandleFunc("/roadmap", ws.RoadMapPage)
 
+	// Mounted handlers, e.g. the REST gateway
+	for _, m := range ws.mounts {
+		if m.prefix != "" {
+			router.PathPrefix(m.prefix).Handler(http.StripPrefix(m.prefix, m.handler))
+		}
+	}
+
 	// Static file serving
 	router.PathPrefix("/").Handler(http.FileServer(http.Dir("./static/")))
 	ws.server.Handler = router
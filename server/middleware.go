@@ -2,34 +2,59 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
-	"github.com/sonr-io/webauthn.io/session"
+	"github.com/sonr-io/webauthn.io/models"
 )
 
-// LoginRequired sets a context variable with the user loaded from the user ID
-// stored in the session cookie
+// LoginRequired sets the context variables "user" and "session" from the
+// login session named by the session cookie. Requests without an active
+// session are redirected to the main login page.
 func (ws *Server) LoginRequired(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		session, _ := ws.store.Get(r, session.WebauthnSession)
-		// Load the user from the database and store it in the request context
-		if id, ok := session.Values["user_id"]; ok {
-			u, err := ws.Ctrl.GetUser(id.(uint))
-			if err != nil {
-				r = r.WithContext(context.WithValue(r.Context(), "user", nil))
-			} else {
-				r = r.WithContext(context.WithValue(r.Context(), "user", u))
-			}
-		} else {
-			r = r.WithContext(context.WithValue(r.Context(), "user", nil))
+		cookie, err := r.Cookie(SessionCookie)
+		if err != nil {
+			http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+			return
 		}
+		s, u, err := ws.Ctrl.ResumeSession(r.Context(), cookie.Value)
+		if errors.Is(err, models.ErrSessionExpired) {
+			ws.clearSessionCookie(w, r)
+			http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+			return
+		} else if err != nil {
+			http.Error(w, http.StatusText(storeStatus(err)), storeStatus(err))
+			return
+		}
+		ctx := context.WithValue(r.Context(), "user", u)
+		ctx = context.WithValue(ctx, "session", s)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		// If we have a valid user, allow access to the handler. Otherwise,
-		// redirect to the main login page.
-		if u := r.Context().Value("user"); u != nil {
-			next.ServeHTTP(w, r)
+// RecoveryRequired sets the context variables "user" and "recovery" from
+// the recovery grant named by the recovery cookie. It is the only way a
+// recovery grant is honored, so it guards nothing but enrolling a new
+// credential.
+func (ws *Server) RecoveryRequired(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(RecoveryCookie)
+		if err != nil {
+			jsonResponse(w, "no open recovery session", http.StatusUnauthorized)
+			return
+		}
+		grant, u, err := ws.Ctrl.ResumeRecovery(r.Context(), cookie.Value)
+		if errors.Is(err, models.ErrRecoveryExpired) {
+			ws.clearRecoveryCookie(w, r)
+			jsonResponse(w, "no open recovery session", http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			jsonResponse(w, err.Error(), storeStatus(err))
 			return
 		}
-		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+		ctx := context.WithValue(r.Context(), "user", u)
+		ctx = context.WithValue(ctx, "recovery", grant)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
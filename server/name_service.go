@@ -2,32 +2,21 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/sonr-io/webauthn.io/models"
 	rt "go.buf.build/grpc/go/sonr-io/sonr/registry"
 )
 
 func (ws *Server) CheckName(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 
-	var takenNames = []string{"api", "tx", "app", "arianagrande", "azamsharp", "barrybonds", "barrysanders", "billgates", "britneyspears", "cdixon", "cristiano", "drake", "elon", "eminem", "flotus", "iamsrk", "imap", "index", "jack", "jbbernstein", "jeffbezos", "jimmyfallon", "joynerlucas", "jtimberlake", "justinbieber", "katyperry", "kimkardashian", "kingjames", "ladygaga", "larrypage", "launchhouse", "logic", "mail", "main", "markzuckerburg", "meekmill", "naval", "neymarjr", "oprah", "patrickbetdavid", "pop", "potus", "prad", "rihanna", "root", "satyanadella", "sc", "selenagomez", "sergeibrin", "shakira", "shl", "smartrick", "srbachchan", "stephencurry", "sundarpichai", "taylorswift", "tombrady", "vitalik", "michael", "prad2", "papa", "ikj", "ian", "shadowysupercoder", "ianperez", "perez", "0x0", "zac", "smartrick", "holwerda", "zholwerda", "NFT", "classof.o7", "goat", "nsfw", "nick", "ntindle", "nicktindle", "cloud", "devops", "engineer", "ntt", "grace", "get", "gtindle", "0xDEADBEEF", "static", "d0x", "null", "exposure", "zach", "joshLong145", "beanPole", "undefined", "Peyton", "gopher", "cosmic", "lauren", "sonr", "prad", "letsgobrandon", "snr", "erin", "jamey", "monica", "Space", "timmy", "creaton", "Warriors", "BestButt", "Mfers", "Beast", "mary", "david", "RX", "NT", "0X", "OK", "NO", "SN", "GB", "GT", "IP", "AH", "PT", "JL", "AF", "0F", "0p", "00", "C0", "80"}
 	vars := mux.Vars(req)
-	name := vars["name"]
-	//The trimmer
-	if name[len(name)-4:] == ".snr" {
-		name = name[:len(name)-4]
-	}
+	name := models.TrimName(vars["name"])
 	var err error
 
-	// if reserved
-	for _, x := range takenNames {
-		if x == name {
-			//TODO return error
-			return
-		}
-	}
-
 	// start := time.Now()
 	// e := log.Info()
 	// defer func(e *zerolog.Event, start time.Time) {
@@ -37,9 +26,9 @@ func (ws *Server) CheckName(w http.ResponseWriter, req *http.Request) {
 	// 	e.Str("handler", "CheckName").AnErr("context", ctx.Err()).Str("name", name).Int64("resp_time", time.Now().Sub(start).Milliseconds()).Send()
 	// }(e, start)
 
-	nameAvailable, err := ws.Ctrl.CheckName(ctx, name)
+	nameAvailable, err := ws.Ctrl.NameAvailable(ctx, name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), storeStatus(err))
 		return
 	}
 
@@ -86,9 +75,12 @@ func (ws *Server) RegisterName(w http.ResponseWriter, req *http.Request) {
 	// }
 
 	//TODO checkname
-	user := ws.Ctrl.FindUserByName(ctx, name)
-	if user.Username == "" {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	user, err := ws.Ctrl.FindUserByName(ctx, name)
+	if errors.Is(err, models.ErrNameNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), storeStatus(err))
 		return
 	}
 
@@ -96,7 +88,8 @@ func (ws *Server) RegisterName(w http.ResponseWriter, req *http.Request) {
 
 	resp, err := ws.Ctrl.RegisterName(ctx, &rt.MsgRegisterName{NameToRegister: name}, did, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), storeStatus(err))
+		return
 	}
 
 	//format response
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	log "github.com/sonr-io/webauthn.io/logger"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// RecoveryCookie is the cookie holding the token of a recovery grant. The
+// grant itself is stored in the database, so it is gone once used.
+const RecoveryCookie = "sonr-recovery"
+
+// recoveryCredentialKey is the session key holding the ceremony of a
+// recovery session enrolling a credential.
+const recoveryCredentialKey = "recovery-credential"
+
+// recoveryCodesResponse carries recovery codes to the browser, the only time
+// they are shown.
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// recoveryStatus maps a failed recovery to an HTTP status code.
+func recoveryStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrInvalidRecoveryCode), errors.Is(err, models.ErrRecoveryExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, models.ErrRecoveryLocked):
+		return http.StatusTooManyRequests
+	default:
+		return storeStatus(err)
+	}
+}
+
+// RecoveryPage renders the page to recover an account with.
+func (ws *Server) RecoveryPage(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, "recover.html", nil)
+}
+
+// StartRecovery consumes the recovery code posted with a username and opens
+// a recovery session, which can only enroll a new credential.
+func (ws *Server) StartRecovery(w http.ResponseWriter, r *http.Request) {
+	username := models.TrimName(r.FormValue("username"))
+	user, err := ws.Ctrl.RecoverAccount(r.Context(), username, r.FormValue("code"))
+	if err != nil {
+		jsonResponse(w, err.Error(), recoveryStatus(err))
+		return
+	}
+	token, grant, err := ws.Ctrl.OpenRecovery(r.Context(), user)
+	if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     RecoveryCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  grant.Expires,
+		Secure:   ws.secureCookies(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	log.Infof("opened recovery session for %s", user.Username)
+	jsonResponse(w, grant, http.StatusOK)
+}
+
+// RequestRecoveryCredential begins enrolling a credential in a recovery
+// session.
+func (ws *Server) RequestRecoveryCredential(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	opts := append(ws.registrationOptions(r), webauthn.WithExclusions(user.CredentialExcludeList()))
+	credentialOptions, sessionData, err := ws.webauthn.BeginRegistration(user, opts...)
+	if err != nil {
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := ws.store.SaveWebauthnSession(recoveryCredentialKey, sessionData, r, w); err != nil {
+		jsonResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, credentialOptions, http.StatusOK)
+}
+
+// MakeRecoveryCredential finishes enrolling a credential in a recovery
+// session and closes the session. The user then logs in with the new
+// credential.
+func (ws *Server) MakeRecoveryCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := ctx.Value("user").(*models.User)
+	grant := ctx.Value("recovery").(*models.RecoveryGrant)
+	sessionData, err := ws.store.GetWebauthnSession(recoveryCredentialKey, r)
+	if err != nil {
+		jsonResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if models.BytesToID(sessionData.UserID) != user.ID {
+		jsonResponse(w, "registration was started by another user", http.StatusForbidden)
+		return
+	}
+
+	cred, attestationType, err := ws.finishRegistration(user, sessionData, r)
+	if err != nil {
+		jsonResponse(w, err.Error(), registrationStatus(err))
+		return
+	}
+	c, err := ws.Ctrl.RecoverCredential(ctx, grant, user, cred, attestationType)
+	if err != nil {
+		log.Errorf("error enrolling recovery credential: %v", err)
+		jsonResponse(w, err.Error(), recoveryStatus(err))
+		return
+	}
+	ws.clearRecoveryCookie(w, r)
+	log.Infof("enrolled credential %s for %s in a recovery session", c.CredentialID, user.Username)
+	jsonResponse(w, c, http.StatusCreated)
+}
+
+// clearRecoveryCookie tells the browser to drop its recovery cookie.
+func (ws *Server) clearRecoveryCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     RecoveryCookie,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   ws.secureCookies(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// IssueRecoveryCodes replaces the logged in user's recovery codes and
+// returns the new ones.
+func (ws *Server) IssueRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	codes, err := ws.Ctrl.IssueRecoveryCodes(r.Context(), user)
+	if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
+	}
+	jsonResponse(w, recoveryCodesResponse{RecoveryCodes: codes}, http.StatusCreated)
+}
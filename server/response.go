@@ -3,11 +3,15 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
 	"net/http"
+
+	db "github.com/sonr-io/webauthn.io/database"
+	"github.com/sonr-io/webauthn.io/models"
 )
 
 // defaultTemplates are included every time a template is rendered.
@@ -25,6 +29,34 @@ func jsonResponse(w http.ResponseWriter, d interface{}, c int) {
 	fmt.Fprintf(w, "%s", dj)
 }
 
+// storeStatus maps database errors to HTTP status codes.
+func storeStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrDuplicate):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// credentialStatus maps credential management errors to HTTP status codes.
+func credentialStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrCredentialNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidNickname):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrLastCredential):
+		return http.StatusConflict
+	default:
+		return storeStatus(err)
+	}
+}
+
 // renderTemplate renders the template to the ResponseWriter
 func renderTemplate(w http.ResponseWriter, f string, data interface{}) {
 	t, err := template.ParseFiles(append(defaultTemplates, fmt.Sprintf("./templates/%s", f))...)
@@ -1,33 +1,69 @@
 package server
 
-// import (
-// 	"testing"
-
-// 	"github.com/sonr-io/webauthn.io/config"
-// 	"github.com/sonr-io/webauthn.io/models"
-// 	"github.com/stretchr/testify/suite"
-// )
-
-// type ServerSuite struct {
-// 	config *config.Config
-// 	server *Server
-
-// 	suite.Suite
-// }
-
-// func (ss *ServerSuite) SetupSuite() {
-// 	ss.config = &config.Config{
-// 		DBName:      "sqlite3",
-// 		DBPath:      ":memory:",
-// 		HostAddress: "localhost",
-// 	}
-// 	err := models.Setup(ss.config)
-// 	ss.Nil(err)
-
-// 	ss.server, err = NewServer(nil, ss.config)
-// 	ss.Nil(err)
-// }
-
-// func TestRunServerSuite(t *testing.T) {
-// 	suite.Run(t, new(ServerSuite))
-// }
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/sonr-io/webauthn.io/config"
+	"github.com/sonr-io/webauthn.io/controller"
+	db "github.com/sonr-io/webauthn.io/database"
+	"github.com/sonr-io/webauthn.io/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServerSuite struct {
+	config *config.Config
+	server *Server
+	store  *db.MemoryStore
+
+	suite.Suite
+}
+
+func (ss *ServerSuite) SetupSuite() {
+	ss.config = &config.Config{
+		HostAddress:  "localhost",
+		RelyingParty: "localhost",
+		RPOrigin:     "http://localhost",
+	}
+}
+
+// SetupTest gives every test a server over an empty memory store.
+func (ss *ServerSuite) SetupTest() {
+	ss.store = db.NewMemoryStore()
+	ctrl, err := controller.New(ss.store, &config.SonrConfig{}, nil)
+	ss.Require().NoError(err)
+	ss.server, err = NewServer(ctrl, ss.config)
+	ss.Require().NoError(err)
+}
+
+// serve sends req through the server's routes with cookies attached.
+func (ss *ServerSuite) serve(req *http.Request, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	ss.server.Handler().ServeHTTP(w, req)
+	return w
+}
+
+// register stores cred for user, creating the user with their first
+// credential.
+func (ss *ServerSuite) register(user *models.User, cred *webauthn.Credential) *models.Credential {
+	c, err := ss.server.Ctrl.RegisterCredential(context.Background(), user, cred, "none", user.ID == 0)
+	ss.Require().NoError(err)
+	return c
+}
+
+// login starts a session for user with credentialID and returns its cookie.
+func (ss *ServerSuite) login(user *models.User, credentialID string) *http.Cookie {
+	token, _, err := ss.server.Ctrl.StartSession(context.Background(), user, credentialID, "test", "127.0.0.1")
+	ss.Require().NoError(err)
+	return &http.Cookie{Name: SessionCookie, Value: token}
+}
+
+func TestRunServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerSuite))
+}
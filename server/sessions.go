@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sonr-io/webauthn.io/logger"
+	"github.com/sonr-io/webauthn.io/models"
+)
+
+// SessionCookie is the cookie holding the token of a login session. The
+// session itself is stored in the database, so it can be listed and signed
+// out from anywhere.
+const SessionCookie = "sonr-session"
+
+// sessionStatus maps session management errors to HTTP status codes.
+func sessionStatus(err error) int {
+	if errors.Is(err, models.ErrSessionNotFound) {
+		return http.StatusNotFound
+	}
+	return storeStatus(err)
+}
+
+// startSession logs user in on the requesting browser, which proved
+// credentialID.
+func (ws *Server) startSession(w http.ResponseWriter, r *http.Request, user *models.User, credentialID string) error {
+	token, s, err := ws.Ctrl.StartSession(r.Context(), user, credentialID, r.UserAgent(), clientIP(r))
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  s.Expires,
+		Secure:   ws.secureCookies(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	log.Infof("started session %d for %s on %s", s.ID, user.Username, s.Device)
+	return nil
+}
+
+// clearSessionCookie tells the browser to drop its session cookie.
+func (ws *Server) clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   ws.secureCookies(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// secureCookies reports whether cookies must only be sent over HTTPS.
+func (ws *Server) secureCookies(r *http.Request) bool {
+	return r.TLS != nil || strings.HasPrefix(ws.config.RPOrigin, "https://")
+}
+
+// clientIP is the address the request came from, without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// SessionsPage lists the logged in user's active sessions.
+func (ws *Server) SessionsPage(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	current := r.Context().Value("session").(*models.Session)
+	sessions, err := ws.Ctrl.ListSessions(r.Context(), user)
+	if err != nil {
+		http.Error(w, http.StatusText(storeStatus(err)), storeStatus(err))
+		return
+	}
+	templateData := struct {
+		User     models.User
+		Sessions []models.Session
+		Current  uint
+	}{
+		*user,
+		sessions,
+		current.ID,
+	}
+	renderTemplate(w, "sessions.html", templateData)
+}
+
+// Logout signs out the requesting browser's session.
+func (ws *Server) Logout(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	current := r.Context().Value("session").(*models.Session)
+	if err := ws.Ctrl.EndSession(r.Context(), user, current.ID); err != nil && !errors.Is(err, models.ErrSessionNotFound) {
+		jsonResponse(w, err.Error(), sessionStatus(err))
+		return
+	}
+	ws.clearSessionCookie(w, r)
+	jsonResponse(w, "Signed out", http.StatusOK)
+}
+
+// EndSession signs out one of the logged in user's sessions.
+func (ws *Server) EndSession(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	current := r.Context().Value("session").(*models.Session)
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		jsonResponse(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	if err := ws.Ctrl.EndSession(r.Context(), user, uint(id)); err != nil {
+		jsonResponse(w, err.Error(), sessionStatus(err))
+		return
+	}
+	if uint(id) == current.ID {
+		ws.clearSessionCookie(w, r)
+	}
+	log.Infof("signed out session %d of %s", id, user.Username)
+	jsonResponse(w, "Signed out", http.StatusOK)
+}
+
+// EndAllSessions signs the logged in user out everywhere, including the
+// requesting browser.
+func (ws *Server) EndAllSessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	if err := ws.Ctrl.EndAllSessions(r.Context(), user); err != nil {
+		jsonResponse(w, err.Error(), sessionStatus(err))
+		return
+	}
+	ws.clearSessionCookie(w, r)
+	log.Infof("signed out every session of %s", user.Username)
+	jsonResponse(w, "Signed out everywhere", http.StatusOK)
+}
@@ -32,12 +32,17 @@ func (ws *Server) CreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pi, err := ws.Ctrl.StripeIntent(req.Items[0], name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("pi.New: %v", err)
+		return
+	}
 	log.Printf("pi.New: %v", pi.ClientSecret)
 
 	// fmt.Println(pi.Status)
 	// fmt.Println(stripe.PaymentIntentStatusSucceeded)
 
-	ws.Ctrl.AttachIntent(pi.ID, name)
+	err = ws.Ctrl.AttachIntent(r.Context(), pi.ID, name)
 
 	//TODO this is bad
 	// go func(item models.SnrItem, name string) {
@@ -53,8 +58,8 @@ func (ws *Server) CreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
 	// }(req.Items[0], name)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		log.Printf("pi.New: %v", err)
+		http.Error(w, err.Error(), storeStatus(err))
+		log.Printf("AttachIntent: %v", err)
 		return
 	}
 
@@ -95,7 +100,12 @@ func (ws *Server) StripeWebhook(w http.ResponseWriter, req *http.Request) {
 		}
 		fmt.Println("PaymentIntent was successful!")
 
-		ws.Ctrl.UpdatePayment(paymentIntent.ID)
+		// A failed write is reported so that Stripe retries the event.
+		if err := ws.Ctrl.UpdatePayment(req.Context(), paymentIntent.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording payment: %v\n", err)
+			w.WriteHeader(storeStatus(err))
+			return
+		}
 
 	case "payment_method.attached":
 		var paymentMethod stripe.PaymentMethod
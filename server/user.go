@@ -1,10 +1,12 @@
 package server
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/jinzhu/gorm"
+	db "github.com/sonr-io/webauthn.io/database"
 	log "github.com/sonr-io/webauthn.io/logger"
 	"github.com/sonr-io/webauthn.io/models"
 )
@@ -21,11 +23,14 @@ func (ws *Server) CreateUser(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, "No email specified", http.StatusBadRequest)
 		return
 	}
-	user, err := ws.Ctrl.GetUserByUsername(email)
-	if err != gorm.ErrRecordNotFound {
+	user, err := ws.Ctrl.GetUserByUsername(r.Context(), email)
+	if err == nil {
 		log.Errorf("user already exists: %s", email)
 		jsonResponse(w, user, http.StatusOK)
 		return
+	} else if !errors.Is(err, db.ErrNotFound) {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
 	}
 	u := models.User{
 		Username:    email,
@@ -33,9 +38,9 @@ func (ws *Server) CreateUser(w http.ResponseWriter, r *http.Request) {
 		Paid:        false,
 		Icon:        models.PlaceholderUserIcon,
 	}
-	err = ws.Ctrl.PutUser(&u)
+	err = ws.Ctrl.PutUser(r.Context(), &u)
 	if err != nil {
-		jsonResponse(w, "Error Creating User", http.StatusInternalServerError)
+		jsonResponse(w, "Error Creating User", storeStatus(err))
 		return
 	}
 	jsonResponse(w, u, http.StatusCreated)
@@ -48,15 +53,37 @@ func (ws *Server) UserExists(w http.ResponseWriter, r *http.Request) {
 	}
 	vars := mux.Vars(r)
 	//The trimmer
-	username := vars["name"]
-	if username[len(username)-4:] == ".snr" {
-		username = username[:len(username)-4]
-	}
-	_, err := ws.Ctrl.GetUserByUsername(username)
-	if err != nil {
+	username := models.TrimName(vars["name"])
+	_, err := ws.Ctrl.GetUserByUsername(r.Context(), username)
+	if errors.Is(err, db.ErrNotFound) {
 		log.Errorf("user not found: %s: %s", username, err)
 		jsonResponse(w, existsResponse{Exists: false}, http.StatusNotFound)
 		return
+	} else if err != nil {
+		jsonResponse(w, err.Error(), storeStatus(err))
+		return
 	}
 	jsonResponse(w, existsResponse{Exists: true}, http.StatusOK)
 }
+
+// SessionToken issues a short lived token the logged in user's browser code
+// presents as "authorization: Bearer" when calling the Highway RPCs.
+func (ws *Server) SessionToken(w http.ResponseWriter, r *http.Request) {
+	type tokenResponse struct {
+		Token   string    `json:"token"`
+		Expires time.Time `json:"expires"`
+	}
+	u, ok := r.Context().Value("user").(*models.User)
+	if !ok || u.Did == "" {
+		jsonResponse(w, "No DID is attached to this account", http.StatusForbidden)
+		return
+	}
+	s := r.Context().Value("session").(*models.Session)
+	token, expires, err := ws.Ctrl.SessionToken(u.Did, s)
+	if err != nil {
+		log.Errorf("error issuing session token: %s", err)
+		jsonResponse(w, "Error issuing session token", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, tokenResponse{Token: token, Expires: expires}, http.StatusOK)
+}
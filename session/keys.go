@@ -0,0 +1,176 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Key lengths of a generated KeyPair: an HMAC-SHA256 hash key and an AES-256
+// encryption key.
+const (
+	HashKeyLength       = 64
+	EncryptionKeyLength = 32
+)
+
+// ErrNoKeys is returned for a key file that holds no key pairs.
+var ErrNoKeys = errors.New("no session keys")
+
+// KeyPair authenticates and encrypts session cookies.
+type KeyPair struct {
+	Hash       []byte
+	Encryption []byte
+}
+
+// GenerateKeyPair returns a new random KeyPair.
+func GenerateKeyPair() (KeyPair, error) {
+	hash, err := GenerateSecureKey(HashKeyLength)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	encryption, err := GenerateSecureKey(EncryptionKeyLength)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return KeyPair{Hash: hash, Encryption: encryption}, nil
+}
+
+// String encodes the pair as its base64 hash and encryption keys separated
+// by a colon, the form read by ParseKeyPair.
+func (k KeyPair) String() string {
+	return base64.StdEncoding.EncodeToString(k.Hash) + ":" + base64.StdEncoding.EncodeToString(k.Encryption)
+}
+
+// ParseKeyPair decodes a pair encoded by KeyPair.String. The encryption key
+// must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func ParseKeyPair(s string) (KeyPair, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return KeyPair{}, fmt.Errorf("session key must be <hash>:<encryption>")
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("session hash key: %w", err)
+	}
+	encryption, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("session encryption key: %w", err)
+	}
+	if len(hash) < 32 {
+		return KeyPair{}, fmt.Errorf("session hash key is %d bytes, need at least 32", len(hash))
+	}
+	switch len(encryption) {
+	case 16, 24, 32:
+	default:
+		return KeyPair{}, fmt.Errorf("session encryption key is %d bytes, need 16, 24 or 32", len(encryption))
+	}
+	return KeyPair{Hash: hash, Encryption: encryption}, nil
+}
+
+// KeyPairs are the active session keys, newest first. Cookies are written
+// with the first pair and read with any of them, so a rotated out pair keeps
+// working until it is dropped.
+type KeyPairs []KeyPair
+
+// ParseKeyPairs decodes a list of pairs encoded by KeyPair.String.
+func ParseKeyPairs(list []string) (KeyPairs, error) {
+	var keys KeyPairs
+	for _, s := range list {
+		k, err := ParseKeyPair(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// Flatten returns the keys in the order NewStore takes them.
+func (keys KeyPairs) Flatten() [][]byte {
+	flat := make([][]byte, 0, 2*len(keys))
+	for _, k := range keys {
+		flat = append(flat, k.Hash, k.Encryption)
+	}
+	return flat
+}
+
+// Rotate returns keys with a new pair in front, keeping at most keep pairs
+// so the oldest are retired.
+func (keys KeyPairs) Rotate(keep int) (KeyPairs, error) {
+	k, err := GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	rotated := append(KeyPairs{k}, keys...)
+	if keep > 0 && len(rotated) > keep {
+		rotated = rotated[:keep]
+	}
+	return rotated, nil
+}
+
+// ReadKeyFile reads the pairs of a key file, one per line newest first.
+// Blank lines and lines starting with # are skipped.
+func ReadKeyFile(path string) (KeyPairs, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, line)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrNoKeys)
+	}
+	keys, err := ParseKeyPairs(list)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// WriteKeyFile replaces the key file at path with keys, readable only by its
+// owner. The file is written beside path and renamed over it, so a server
+// starting meanwhile never reads half of it.
+func WriteKeyFile(path string, keys KeyPairs) error {
+	if len(keys) == 0 {
+		return ErrNoKeys
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Session keys, newest first. Cookies are written with the first pair.\n")
+	for _, k := range keys {
+		buf.WriteString(k.String())
+		buf.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// LoadKeys returns the session keys configured in keys, or when that is
+// empty read from keyFile. Without either it returns no keys.
+func LoadKeys(keys []string, keyFile string) (KeyPairs, error) {
+	if len(keys) > 0 {
+		return ParseKeyPairs(keys)
+	}
+	if keyFile == "" {
+		return nil, nil
+	}
+	return ReadKeyFile(keyFile)
+}
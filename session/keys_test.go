@@ -0,0 +1,116 @@
+package session
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "session-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys")
+
+	first, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteKeyFile(path, KeyPairs{first}); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadKeys(nil, path)
+	if err != nil || len(keys) != 1 || keys[0].String() != first.String() {
+		t.Fatalf("LoadKeys = %v, %v", keys, err)
+	}
+
+	// A cookie written before a rotation is still read after it.
+	store, err := NewStore(keys.Flatten()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	if err := store.SaveJSON("grant", "before", r, w); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+
+	rotated, err := keys.Rotate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 2 || rotated[1].String() != first.String() {
+		t.Fatalf("rotated = %v", rotated)
+	}
+	if err := WriteKeyFile(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	keys, err = ReadKeyFile(path)
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("ReadKeyFile = %v, %v", keys, err)
+	}
+	readCookie := func(keys KeyPairs) (string, error) {
+		store, err := NewStore(keys.Flatten()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		var value string
+		err = store.GetJSON("grant", r, &value)
+		return value, err
+	}
+	if value, err := readCookie(keys); err != nil || value != "before" {
+		t.Fatalf("after rotation: %q, %v", value, err)
+	}
+
+	// Rotating again retires the first pair, and its cookies with it.
+	keys, err = keys.Rotate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCookie(keys); err == nil {
+		t.Fatal("cookie of a retired key was read")
+	}
+}
+
+func TestParseKeyPair(t *testing.T) {
+	k, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseKeyPairs([]string{k.String()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"",
+		"bm90OmJhc2U2NA",
+		"c2hvcnQ=:" + k.String()[len(k.String())-44:],
+		k.String()[:88] + ":c2hvcnQ=",
+	} {
+		if _, err := ParseKeyPair(s); err == nil {
+			t.Errorf("ParseKeyPair(%q) accepted", s)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "session-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(path, []byte("# nothing yet\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadKeyFile(path); !errors.Is(err, ErrNoKeys) {
+		t.Fatalf("empty key file: %v", err)
+	}
+}
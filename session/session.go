@@ -46,7 +46,9 @@ type Store struct {
 	*sessions.CookieStore
 }
 
-// NewStore returns a new session store.
+// NewStore returns a new session store. keyPairs alternate hash and
+// encryption keys, as returned by KeyPairs.Flatten. Without any a random key
+// is generated, so cookies do not outlive the process.
 func NewStore(keyPairs ...[]byte) (*Store, error) {
 	// Generate a default encryption key if one isn't provided
 	if len(keyPairs) == 0 {
@@ -103,3 +105,35 @@ func (store *Store) Set(key string, value interface{}, r *http.Request, w http.R
 	session.Save(r, w)
 	return nil
 }
+
+// Delete removes the value stored under key from the session.
+func (store *Store) Delete(key string, r *http.Request, w http.ResponseWriter) error {
+	session, err := store.Get(r, WebauthnSession)
+	if err != nil {
+		return err
+	}
+	delete(session.Values, key)
+	return session.Save(r, w)
+}
+
+// SaveJSON marshals value and saves it to the session under key.
+func (store *Store) SaveJSON(key string, value interface{}, r *http.Request, w http.ResponseWriter) error {
+	marshaledData, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return store.Set(key, marshaledData, r, w)
+}
+
+// GetJSON unmarshals the value saved with SaveJSON under key into value.
+func (store *Store) GetJSON(key string, r *http.Request, value interface{}) error {
+	session, err := store.Get(r, WebauthnSession)
+	if err != nil {
+		return err
+	}
+	data, ok := session.Values[key].([]byte)
+	if !ok {
+		return ErrMarshal
+	}
+	return json.Unmarshal(data, value)
+}
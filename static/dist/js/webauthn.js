@@ -89,6 +89,7 @@ function makeCredential() {
         return;
     }
     setUser();
+    abortConditionalAssertion();
     var credential = null;
     var attestation_type = $('#select-attestation').find(':selected').val();
     var authenticator_attachment = $('#select-authenticator').find(':selected').val();
@@ -117,7 +118,9 @@ function makeCredential() {
                 publicKey: makeCredentialOptions.publicKey
             }).then(function(newCredential) {
                 state.createResponse = newCredential;
-                registerNewCredential(newCredential);
+                return registerNewCredential(newCredential);
+            }).then(function(response) {
+                showRecoveryCodes(response.recovery_codes);
                 window.location = "/payment";
             })
         });
@@ -130,7 +133,7 @@ function registerNewCredential(newCredential) {
     let clientDataJSON = new Uint8Array(newCredential.response.clientDataJSON);
     let rawId = new Uint8Array(newCredential.rawId);
 
-    $.ajax({
+    return $.ajax({
         url: '/makeCredential',
         type: 'POST',
         data: JSON.stringify({
@@ -150,6 +153,195 @@ function registerNewCredential(newCredential) {
     });
 }
 
+// Register another authenticator for the logged in user
+function addCredential() {
+    hideErrorAlert();
+    enrollCredential('/credential/add')
+        .then(function() {
+            window.location.reload();
+        })
+        .catch(function(err) {
+            // An authenticator that already holds one of the user's
+            // credentials is rejected with InvalidStateError.
+            showErrorAlert(err.message || err.responseJSON || "Unable to add the passkey");
+        });
+}
+
+// Run a registration ceremony against url, which serves the options on GET
+// and takes the new credential on POST.
+function enrollCredential(url) {
+    return $.get(url, {}, null, 'json')
+        .then(function(makeCredentialOptions) {
+            makeCredentialOptions.publicKey.challenge = bufferDecode(makeCredentialOptions.publicKey.challenge);
+            makeCredentialOptions.publicKey.user.id = bufferDecode(makeCredentialOptions.publicKey.user.id);
+            if (makeCredentialOptions.publicKey.excludeCredentials) {
+                for (var i = 0; i < makeCredentialOptions.publicKey.excludeCredentials.length; i++) {
+                    makeCredentialOptions.publicKey.excludeCredentials[i].id = bufferDecode(makeCredentialOptions.publicKey.excludeCredentials[i].id);
+                }
+            }
+            return navigator.credentials.create({
+                publicKey: makeCredentialOptions.publicKey
+            });
+        })
+        .then(function(newCredential) {
+            let attestationObject = new Uint8Array(newCredential.response.attestationObject);
+            let clientDataJSON = new Uint8Array(newCredential.response.clientDataJSON);
+            let rawId = new Uint8Array(newCredential.rawId);
+            return $.ajax({
+                url: url,
+                type: 'POST',
+                data: JSON.stringify({
+                    id: newCredential.id,
+                    rawId: bufferEncode(rawId),
+                    type: newCredential.type,
+                    response: {
+                        attestationObject: bufferEncode(attestationObject),
+                        clientDataJSON: bufferEncode(clientDataJSON),
+                    },
+                }),
+                contentType: "application/json; charset=utf-8",
+                dataType: "json",
+            });
+        });
+}
+
+// Recovery codes are only ever sent once, so make the user acknowledge them.
+function showRecoveryCodes(codes) {
+    if (!codes || codes.length === 0) {
+        return;
+    }
+    window.alert("Save these recovery codes somewhere safe. Each one can be used once " +
+        "to add a passkey if you lose yours, and they will not be shown again.\n\n" + codes.join("\n"));
+}
+
+function issueRecoveryCodes() {
+    hideErrorAlert();
+    if (!window.confirm("Replace your recovery codes? The current ones will stop working.")) {
+        return;
+    }
+    $.ajax({
+            url: '/recovery/codes',
+            type: 'POST',
+            dataType: "json",
+        })
+        .then(function(response) {
+            showRecoveryCodes(response.recovery_codes);
+            window.location.reload();
+        })
+        .catch(function(err) {
+            showErrorAlert(err.responseJSON || "Unable to issue recovery codes");
+        });
+}
+
+// Use a recovery code to open a recovery session, then enroll a new passkey
+// in it.
+function recoverAccount() {
+    hideErrorAlert();
+    $.post('/recovery', {
+            username: $("#input-recovery-name").val(),
+            code: $("#input-recovery-code").val(),
+        }, null, 'json')
+        .then(function() {
+            return enrollCredential('/recovery/credential');
+        })
+        .then(function() {
+            window.alert("Your new passkey is ready. Sign in with it to continue.");
+            window.location = "/";
+        })
+        .catch(function(err) {
+            showErrorAlert(err.message || err.responseJSON || "Unable to recover the account");
+        });
+}
+
+function renameCredential(credentialId) {
+    hideErrorAlert();
+    let nickname = window.prompt("Nickname for this passkey");
+    if (nickname === null) {
+        return;
+    }
+    $.ajax({
+            url: '/credentials/' + encodeURIComponent(credentialId),
+            type: 'PUT',
+            data: JSON.stringify({ nickname: nickname }),
+            contentType: "application/json; charset=utf-8",
+            dataType: "json",
+        })
+        .then(function() {
+            window.location.reload();
+        })
+        .catch(function(err) {
+            showErrorAlert(err.responseJSON || "Unable to rename the passkey");
+        });
+}
+
+function revokeCredential(credentialId) {
+    hideErrorAlert();
+    if (!window.confirm("Revoke this passkey? It will no longer be able to log in.")) {
+        return;
+    }
+    $.ajax({
+            url: '/credentials/' + encodeURIComponent(credentialId),
+            type: 'DELETE',
+            dataType: "json",
+        })
+        .then(function() {
+            window.location.reload();
+        })
+        .catch(function(err) {
+            // The last passkey is kept unless the account can be recovered.
+            showErrorAlert(err.responseJSON || "Unable to revoke the passkey");
+        });
+}
+
+function signOut() {
+    $.ajax({
+            url: '/logout',
+            type: 'POST',
+            dataType: "json",
+        })
+        .always(function() {
+            window.location = "/";
+        });
+}
+
+// End one session. Ending this browser's own session signs it out.
+function endSession(id, current) {
+    hideErrorAlert();
+    $.ajax({
+            url: '/sessions/' + id,
+            type: 'DELETE',
+            dataType: "json",
+        })
+        .then(function() {
+            if (current) {
+                window.location = "/";
+            } else {
+                window.location.reload();
+            }
+        })
+        .catch(function(err) {
+            showErrorAlert(err.responseJSON || "Unable to sign out the session");
+        });
+}
+
+function signOutEverywhere() {
+    hideErrorAlert();
+    if (!window.confirm("Sign out of every browser, including this one?")) {
+        return;
+    }
+    $.ajax({
+            url: '/sessions',
+            type: 'DELETE',
+            dataType: "json",
+        })
+        .then(function() {
+            window.location = "/";
+        })
+        .catch(function(err) {
+            showErrorAlert(err.responseJSON || "Unable to sign out everywhere");
+        });
+}
+
 function addUserErrorMsg(msg) {
     if (msg === "username") {
         msg = 'Please correct your SNR name.';
@@ -166,6 +358,7 @@ function getAssertion() {
         return;
     }
     setUser();
+    abortConditionalAssertion();
     $.get('/user/' + state.user.name + '/exists', {}, null, 'json').done(function(response) {
             console.log(response);
         }).then(function() {
@@ -205,7 +398,70 @@ function getAssertion() {
         });
 }
 
-function verifyAssertion(assertedCredential) {
+// conditionalAbort cancels the pending autofill request, which would
+// otherwise block any other WebAuthn ceremony on the page.
+var conditionalAbort = null;
+
+function abortConditionalAssertion() {
+    if (conditionalAbort) {
+        conditionalAbort.abort();
+        conditionalAbort = null;
+    }
+}
+
+// startConditionalAssertion offers the user's passkeys in the browser's
+// autofill for inputs marked autocomplete="username webauthn". The challenge
+// is kept on the server under the returned ID while the page is open.
+function startConditionalAssertion() {
+    if (!window.PublicKeyCredential || !PublicKeyCredential.isConditionalMediationAvailable) {
+        return;
+    }
+    PublicKeyCredential.isConditionalMediationAvailable().then(function(available) {
+        if (!available) {
+            return;
+        }
+        $.post('/assertion/conditional', {}, null, 'json')
+            .done(function(makeAssertionOptions) {
+                makeAssertionOptions.publicKey.challenge = bufferDecode(makeAssertionOptions.publicKey.challenge);
+                conditionalAbort = new AbortController();
+                navigator.credentials.get({
+                        mediation: 'conditional',
+                        publicKey: makeAssertionOptions.publicKey,
+                        signal: conditionalAbort.signal
+                    })
+                    .then(function(credential) {
+                        conditionalAbort = null;
+                        verifyAssertion(credential, '/assertion/conditional/' + makeAssertionOptions.id);
+                    }).catch(function(err) {
+                        // Aborted for another ceremony, or the challenge expired.
+                        console.log(err.name);
+                    });
+            });
+    });
+}
+
+// getDiscoverableAssertion logs in without a name: the authenticator offers
+// the passkeys it holds for this site and the server finds the user from the
+// one picked.
+function getDiscoverableAssertion() {
+    hideErrorAlert();
+    abortConditionalAssertion();
+    $.get('/assertion', {}, null, 'json')
+        .done(function(makeAssertionOptions) {
+            makeAssertionOptions.publicKey.challenge = bufferDecode(makeAssertionOptions.publicKey.challenge);
+            navigator.credentials.get({
+                    publicKey: makeAssertionOptions.publicKey
+                })
+                .then(function(credential) {
+                    verifyAssertion(credential);
+                }).catch(function(err) {
+                    console.log(err.name);
+                    showErrorAlert(err.message);
+                });
+        });
+}
+
+function verifyAssertion(assertedCredential, url) {
     // Move data into Arrays incase it is super long
     console.log('calling verify')
     let authData = new Uint8Array(assertedCredential.response.authenticatorData);
@@ -214,7 +470,7 @@ function verifyAssertion(assertedCredential) {
     let sig = new Uint8Array(assertedCredential.response.signature);
     let userHandle = new Uint8Array(assertedCredential.response.userHandle);
     $.ajax({
-        url: '/assertion',
+        url: url || '/assertion',
         type: 'POST',
         data: JSON.stringify({
             id: assertedCredential.id,